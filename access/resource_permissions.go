@@ -344,15 +344,15 @@ func ResourcePermissions() *schema.Resource {
 			return nil
 		}
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		me, err := identity.NewUsersAPI(ctx, m).Me()
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		entity, err := objectACL.ToPermissionsEntity(ctx, d, me.UserName)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		if len(entity.AccessControlList) == 0 {
 			// empty "modifiable" access control list is the same as resource absence
@@ -361,7 +361,7 @@ func ResourcePermissions() *schema.Resource {
 		}
 		err = common.StructToData(entity, s, d)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return nil
 	}
@@ -396,20 +396,20 @@ func ResourcePermissions() *schema.Resource {
 			var entity PermissionsEntity
 			err := common.DataToStructPointer(d, s, &entity)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			for _, mapping := range permissionsResourceIDFields(ctx) {
 				if v, ok := d.GetOk(mapping.field); ok {
 					id, err := mapping.idRetriever(m.(*common.DatabricksClient), v.(string))
 					if err != nil {
-						return diag.FromErr(err)
+						return common.DiagFromErr(err)
 					}
 					objectID := fmt.Sprintf("/%s/%s", mapping.resourceType, id)
 					err = NewPermissionsAPI(ctx, m).Update(objectID, AccessControlChangeList{
 						AccessControlList: entity.AccessControlList,
 					})
 					if err != nil {
-						return diag.FromErr(err)
+						return common.DiagFromErr(err)
 					}
 					d.SetId(objectID)
 					return readContext(ctx, d, m)
@@ -421,13 +421,13 @@ func ResourcePermissions() *schema.Resource {
 			var entity PermissionsEntity
 			err := common.DataToStructPointer(d, s, &entity)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			err = NewPermissionsAPI(ctx, m).Update(d.Id(), AccessControlChangeList{
 				AccessControlList: entity.AccessControlList,
 			})
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			return readContext(ctx, d, m)
 		},
@@ -438,7 +438,7 @@ func ResourcePermissions() *schema.Resource {
 				return nil
 			}
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			return nil
 		},
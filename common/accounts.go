@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// configureAccountsClient prepares client for AccountHost with the same credentials, as
+// direct params are replaced with encoded token during authentication of the workspace
+func (c *DatabricksClient) configureAccountsClient() error {
+	if c.AccountHost == "" || c.accounts != nil {
+		return nil
+	}
+	accounts := &DatabricksClient{
+		Host:                 c.AccountHost,
+		Username:             c.Username,
+		Password:             c.Password,
+		AccountID:            c.AccountID,
+		ClientID:             c.ClientID,
+		ClientSecret:         c.ClientSecret,
+		GoogleServiceAccount: c.GoogleServiceAccount,
+		GoogleCredentials:    c.GoogleCredentials,
+		DebugTruncateBytes:   c.DebugTruncateBytes,
+		DebugHeaders:         c.DebugHeaders,
+		UserAgentExtra:       c.UserAgentExtra,
+		InitContext:          c.InitContext,
+		Provider:             c.Provider,
+		// share rate limits, retries and proxy settings with workspace requests
+		httpClient: c.httpClient,
+	}
+	accounts.fixHost()
+	u, err := url.Parse(accounts.Host)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid account host: %s", c.AccountHost)
+	}
+	accounts.AzureAuth.databricksClient = accounts
+	c.accounts = accounts
+	return nil
+}
+
+// isAccountsRequest tells if request has to be sent to AccountHost instead of Host
+func (c *DatabricksClient) isAccountsRequest(r *http.Request) bool {
+	return c.accounts != nil && r.URL.Host == "" &&
+		strings.HasPrefix(r.URL.Path, "/accounts/")
+}
+
+// authorizeAccountsRequest authenticates with accounts credentials on the first call
+func (c *DatabricksClient) authorizeAccountsRequest(r *http.Request) error {
+	host := c.accounts.Host
+	err := c.accounts.Authenticate()
+	if err != nil {
+		return fmt.Errorf("cannot authenticate on %s: %w", host, err)
+	}
+	if c.accounts.Host != host {
+		// ~/.databrickscfg profiles are for workspaces only
+		return fmt.Errorf("cannot authenticate on %s: no account credentials configured", host)
+	}
+	return c.accounts.authVisitor(r)
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hostServer(t *testing.T, name string, calls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		user, password, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "a", user)
+		assert.Equal(t, "b", password)
+		*calls = append(*calls, name+" "+req.URL.Path)
+		_, err := rw.Write([]byte(`{}`))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestAccountHost_RoutesAccountsRequests(t *testing.T) {
+	defer CleanupEnvironment()()
+	calls := []string{}
+	workspace := hostServer(t, "workspace", &calls)
+	defer workspace.Close()
+	accounts := hostServer(t, "accounts", &calls)
+	defer accounts.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:        workspace.URL,
+		AccountHost: accounts.URL,
+		Username:    "a",
+		Password:    "b",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	err = client.Get(ctx, "/accounts/abc/workspaces", nil, nil)
+	require.NoError(t, err)
+	err = client.Get(ctx, "/clusters/list", nil, nil)
+	require.NoError(t, err)
+	err = client.Post(ctx, "/accounts/abc/networks", map[string]string{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"accounts /api/2.0/accounts/abc/workspaces",
+		"workspace /api/2.0/clusters/list",
+		"accounts /api/2.0/accounts/abc/networks",
+	}, calls)
+}
+
+func TestAccountHost_NoAccountCredentials(t *testing.T) {
+	defer CleanupEnvironment()()
+	os.Setenv("PATH", "testdata:/bin")
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Fail(t, "Received unexpected call: "+req.RequestURI)
+	}))
+	defer server.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:        server.URL,
+		AccountHost: "accounts.cloud.databricks.com",
+		Token:       "..",
+	})
+	require.NoError(t, err)
+	err = client.Get(context.Background(), "/accounts/abc/workspaces", nil, nil)
+	AssertErrorStartsWith(t, err, "cannot authenticate on https://accounts.cloud.databricks.com: "+
+		"authentication is not configured for provider")
+}
+
+func TestAccountHost_Invalid(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:        "https://abc.cloud.databricks.com",
+		AccountHost: "https://",
+		Token:       "..",
+	})
+	assert.EqualError(t, err, "invalid account host: https://")
+}
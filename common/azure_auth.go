@@ -2,10 +2,12 @@ package common
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,7 +16,6 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
 )
 
 // List of management information
@@ -36,6 +37,15 @@ type AzureAuth struct {
 	TenantID     string
 	Environment  string
 
+	// UseMSI authenticates with managed identity of Azure VM or AKS pod.
+	// Identity is user-assigned, when MSIClientID is set, and system-assigned otherwise.
+	UseMSI      bool
+	MSIClientID string
+
+	// TokenRefreshMinutes is the time before expiry of AAD token, when it's proactively
+	// refreshed, so that long applies don't fail with expired tokens. Default is 6.
+	TokenRefreshMinutes int
+
 	// temporary workaround for SP-based auth
 	PATTokenDurationSeconds string
 	UsePATForCLI            bool
@@ -47,6 +57,7 @@ type AzureAuth struct {
 	databricksClient *DatabricksClient
 
 	azureManagementEndpoint string
+	azureMSIEndpoint        string
 	authorizer              autorest.Authorizer
 	temporaryPat            *tokenResponse
 }
@@ -69,8 +80,61 @@ type tokenInfo struct {
 	Comment      string `json:"comment,omitempty"`
 }
 
+// tokenExpirySkew is the time before expiry, when session PAT is renewed. It applies only
+// to PATs, that are created for Azure service principals and Azure CLI users. AAD tokens
+// are renewed by refreshable authorizers according to TokenRefreshMinutes and OAuth
+// tokens by their token sources.
+const tokenExpirySkew = time.Minute
+
+// expiresSoon is true when token expires within tokenExpirySkew. Tokens
+// without expiry time never expire.
+func (tr *tokenResponse) expiresSoon() bool {
+	if tr.TokenInfo == nil || tr.TokenInfo.ExpiryTime <= 0 {
+		return false
+	}
+	expiry := time.Unix(0, tr.TokenInfo.ExpiryTime*int64(time.Millisecond))
+	return time.Now().Add(tokenExpirySkew).After(expiry)
+}
+
+// azureWorkspaceDomains of Azure Databricks in public and sovereign clouds
+var azureWorkspaceDomains = []struct {
+	domain      string
+	environment string
+}{
+	{".azuredatabricks.net", "public"},
+	{".databricks.azure.us", "usgovernment"},
+	{".databricks.azure.cn", "china"},
+}
+
+// azureEnvironmentOfHost returns Azure environment name of the workspace host or empty string
+func azureEnvironmentOfHost(host string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Hostname()
+	} else {
+		// host without scheme, like adb-123.4.azuredatabricks.net:443/
+		host = strings.SplitN(strings.SplitN(host, "/", 2)[0], ":", 2)[0]
+	}
+	host = strings.ToLower(host)
+	for _, wd := range azureWorkspaceDomains {
+		if strings.HasSuffix(host, wd.domain) {
+			return wd.environment
+		}
+	}
+	return ""
+}
+
 var authorizerMutex sync.Mutex
 
+// defaultTokenRefreshMinutes is a minute more than AAD token refresh window of adal
+const defaultTokenRefreshMinutes = 6
+
+func (aa *AzureAuth) tokenRefreshMinutes() int {
+	if aa.TokenRefreshMinutes <= 0 {
+		return defaultTokenRefreshMinutes
+	}
+	return aa.TokenRefreshMinutes
+}
+
 func (aa *AzureAuth) getAzureEnvironment() (azure.Environment, error) {
 	// Used for unit testing purposes
 	if aa.azureManagementEndpoint != "" {
@@ -79,11 +143,16 @@ func (aa *AzureAuth) getAzureEnvironment() (azure.Environment, error) {
 		}, nil
 	}
 
-	if aa.Environment == "" {
+	environment := aa.Environment
+	if environment == "" && aa.databricksClient != nil {
+		// sovereign clouds are recognized by workspace host, like adb-123.4.databricks.azure.us
+		environment = azureEnvironmentOfHost(aa.databricksClient.Host)
+	}
+	if environment == "" || environment == "public" {
 		return azure.PublicCloud, nil
 	}
 
-	envName := fmt.Sprintf("AZURE%sCLOUD", strings.ToUpper(aa.Environment))
+	envName := fmt.Sprintf("AZURE%sCLOUD", strings.ToUpper(environment))
 	return azure.EnvironmentFromName(envName)
 }
 
@@ -214,14 +283,16 @@ func (aa *AzureAuth) acquirePAT(
 	ctx context.Context,
 	factory func(resource string) (autorest.Authorizer, error),
 	visitors ...func(r *http.Request, ma autorest.Authorizer) error) (*tokenResponse, error) {
-	if aa.temporaryPat != nil {
-		// todo: add IsExpired
+	if aa.temporaryPat != nil && !aa.temporaryPat.expiresSoon() {
 		return aa.temporaryPat, nil
 	}
 	authorizerMutex.Lock()
 	defer authorizerMutex.Unlock()
 	if aa.temporaryPat != nil {
-		return aa.temporaryPat, nil
+		if !aa.temporaryPat.expiresSoon() {
+			return aa.temporaryPat, nil
+		}
+		log.Printf("[INFO] Session token is about to expire, creating new one")
 	}
 	management, err := factory(aa.AzureEnvironment.ServiceManagementEndpoint)
 	if err != nil {
@@ -335,38 +406,75 @@ func (aa *AzureAuth) createPAT(ctx context.Context,
 	return
 }
 
+// aadTokenKey identifies tokens of the same service principal for the same resource
+type aadTokenKey struct {
+	activeDirectoryEndpoint string
+	tenantID                string
+	clientID                string
+	// hash of the secret, so that wrong secrets are never served from cache
+	secretHash     string
+	resource       string
+	refreshMinutes int
+}
+
+// aadAuthorizers are shared by all provider aliases within the process, so that
+// aliases with the same service principal don't exchange client secret for tokens
+// on their own. Cached authorizers refresh tokens before they expire.
+var aadAuthorizers = struct {
+	sync.Mutex
+	m map[aadTokenKey]autorest.Authorizer
+}{m: map[aadTokenKey]autorest.Authorizer{}}
+
 func (aa *AzureAuth) getClientSecretAuthorizer(resource string) (autorest.Authorizer, error) {
 	if aa.authorizer != nil {
 		// todo: probably should be two different ones...
 		return aa.authorizer, nil
 	}
+	key := aadTokenKey{
+		activeDirectoryEndpoint: aa.AzureEnvironment.ActiveDirectoryEndpoint,
+		tenantID:                aa.TenantID,
+		clientID:                aa.ClientID,
+		secretHash:              fmt.Sprintf("%x", sha256.Sum256([]byte(aa.ClientSecret))),
+		resource:                resource,
+		refreshMinutes:          aa.tokenRefreshMinutes(),
+	}
+	aadAuthorizers.Lock()
+	defer aadAuthorizers.Unlock()
+	if authorizer, ok := aadAuthorizers.m[key]; ok {
+		log.Printf("[DEBUG] Reusing AAD token of %s for %s", aa.ClientID, resource)
+		return authorizer, nil
+	}
+	authorizer, err := aa.newClientSecretAuthorizer(resource)
+	if err != nil {
+		return nil, err
+	}
+	aadAuthorizers.m[key] = authorizer
+	return authorizer, nil
+}
+
+func (aa *AzureAuth) newClientSecretAuthorizer(resource string) (autorest.Authorizer, error) {
+	// management tokens are requested with api-version=1.0, like azure/auth package does
+	var apiVersion *string
 	if resource != AzureDatabricksResourceID {
-		es := auth.EnvironmentSettings{
-			Values: map[string]string{
-				auth.ClientID:     aa.ClientID,
-				auth.ClientSecret: aa.ClientSecret,
-				auth.TenantID:     aa.TenantID,
-				auth.Resource:     resource,
-			},
-			Environment: *aa.AzureEnvironment,
-		}
-		return es.GetAuthorizer()
+		v1 := "1.0"
+		apiVersion = &v1
 	}
-	platformTokenOAuthCfg, err := adal.NewOAuthConfigWithAPIVersion(
+	oauthConfig, err := adal.NewOAuthConfigWithAPIVersion(
 		aa.AzureEnvironment.ActiveDirectoryEndpoint,
 		aa.TenantID,
-		nil)
+		apiVersion)
 	if err != nil {
 		return nil, maybeExtendAuthzError(err)
 	}
 	spt, err := adal.NewServicePrincipalToken(
-		*platformTokenOAuthCfg,
+		*oauthConfig,
 		aa.ClientID,
 		aa.ClientSecret,
-		AzureDatabricksResourceID)
+		resource)
 	if err != nil {
 		return nil, maybeExtendAuthzError(err)
 	}
+	spt.SetRefreshWithin(time.Duration(aa.tokenRefreshMinutes()) * time.Minute)
 	return autorest.NewBearerAuthorizer(spt), nil
 }
 
@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/adal"
@@ -98,6 +100,97 @@ func TestGetClientSecretAuthorizer(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetClientSecretAuthorizer_SharedAcrossAliases(t *testing.T) {
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/tenant/oauth2/token", req.URL.Path)
+		assert.NoError(t, req.ParseForm())
+		assert.Equal(t, "shared-client", req.PostForm.Get("client_id"))
+		exchanges++
+		rw.Header().Set("Content-Type", "application/json")
+		_, err := rw.Write([]byte(fmt.Sprintf(`{"access_token": "aad-%d", "token_type": "Bearer",
+			"expires_in": "3600", "expires_on": "%d", "resource": "%s"}`,
+			exchanges, time.Now().Add(time.Hour).Unix(), AzureDatabricksResourceID)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	authorize := func(secret string) (string, error) {
+		aa := AzureAuth{
+			TenantID:     "tenant",
+			ClientID:     "shared-client",
+			ClientSecret: secret,
+			AzureEnvironment: &azure.Environment{
+				ActiveDirectoryEndpoint: server.URL + "/",
+			},
+		}
+		authorizer, err := aa.getClientSecretAuthorizer(AzureDatabricksResourceID)
+		if err != nil {
+			return "", err
+		}
+		req, err := http.NewRequest("GET", "http://localhost/", nil)
+		if err != nil {
+			return "", err
+		}
+		req, err = autorest.Prepare(req, authorizer.WithAuthorization())
+		if err != nil {
+			return "", err
+		}
+		return req.Header.Get("Authorization"), nil
+	}
+	for i := 0; i < 3; i++ {
+		header, err := authorize("secret")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer aad-1", header)
+	}
+	assert.Equal(t, 1, exchanges)
+
+	// other secrets of the same client never reuse the token
+	header, err := authorize("other-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer aad-2", header)
+	assert.Equal(t, 2, exchanges)
+}
+
+func TestGetClientSecretAuthorizer_RefreshesBeforeExpiry(t *testing.T) {
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		exchanges++
+		rw.Header().Set("Content-Type", "application/json")
+		// every token expires in 10 minutes
+		_, err := rw.Write([]byte(fmt.Sprintf(`{"access_token": "aad-%d", "token_type": "Bearer",
+			"expires_in": "600", "expires_on": "%d", "resource": "%s"}`,
+			exchanges, time.Now().Add(10*time.Minute).Unix(), AzureDatabricksResourceID)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	authorizeTwice := func(refreshMinutes int) []string {
+		aa := AzureAuth{
+			TenantID:            "tenant",
+			ClientID:            "refreshing-client",
+			ClientSecret:        "secret",
+			TokenRefreshMinutes: refreshMinutes,
+			AzureEnvironment: &azure.Environment{
+				ActiveDirectoryEndpoint: server.URL + "/",
+			},
+		}
+		authorizer, err := aa.getClientSecretAuthorizer(AzureDatabricksResourceID)
+		require.NoError(t, err)
+		headers := []string{}
+		for i := 0; i < 2; i++ {
+			req, err := http.NewRequest("GET", "http://localhost/", nil)
+			require.NoError(t, err)
+			req, err = autorest.Prepare(req, authorizer.WithAuthorization())
+			require.NoError(t, err)
+			headers = append(headers, req.Header.Get("Authorization"))
+		}
+		return headers
+	}
+	assert.Equal(t, []string{"Bearer aad-1", "Bearer aad-1"}, authorizeTwice(0))
+	assert.Equal(t, []string{"Bearer aad-2", "Bearer aad-3"}, authorizeTwice(15))
+}
+
 func TestEnsureWorkspaceURL_CornerCases(t *testing.T) {
 	aa := AzureAuth{}
 	env, err := aa.getAzureEnvironment()
@@ -279,6 +372,79 @@ func TestAzureAuth_configureWithClientSecretPAT(t *testing.T) {
 	assert.Len(t, zi.Zones, 3)
 }
 
+func TestAzureAuth_ExpiredPATIsRenewed(t *testing.T) {
+	aa := AzureAuth{
+		ResourceID:   "/subscriptions/a/resourceGroups/b/providers/Microsoft.Databricks/workspaces/c",
+		ClientID:     "a",
+		ClientSecret: "b",
+		TenantID:     "c",
+		UsePATForSPN: true,
+	}
+	aa.authorizer = autorest.NewBearerAuthorizer(&adal.Token{
+		AccessToken: "TestToken",
+		Resource:    "https://azure.microsoft.com/",
+		Type:        "Bearer",
+	})
+	// first token expires right away and the second one in an hour
+	expiries := []time.Time{time.Now(), time.Now().Add(time.Hour)}
+	created := 0
+	var serverURL string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			var response interface{}
+			switch req.RequestURI {
+			case "/subscriptions/a/resourceGroups/b/providers/Microsoft.Databricks/workspaces/c?api-version=2018-04-01":
+				response = map[string]interface{}{
+					"properties": map[string]string{
+						"workspaceUrl": strings.ReplaceAll(serverURL, "https://", ""),
+					},
+				}
+			case "/api/2.0/token/create":
+				response = tokenResponse{
+					TokenValue: fmt.Sprintf("dapi%d", created),
+					TokenInfo: &tokenInfo{
+						ExpiryTime: expiries[created].UnixNano() / int64(time.Millisecond),
+					},
+				}
+				created++
+			case "/api/2.0/clusters/list-zones":
+				response = map[string]string{
+					"default_zone": req.Header.Get("Authorization"),
+				}
+			default:
+				assert.Fail(t, fmt.Sprintf("Received unexpected call: %s %s",
+					req.Method, req.RequestURI))
+			}
+			err := json.NewEncoder(rw).Encode(response)
+			assert.NoError(t, err)
+		}))
+	server.StartTLS()
+	serverURL = server.URL
+	defer server.Close()
+	aa.azureManagementEndpoint = fmt.Sprintf("%s/", server.URL)
+
+	auth, err := aa.configureWithClientSecret()
+	require.NoError(t, err)
+	client := DatabricksClient{InsecureSkipVerify: true}
+	client.authVisitor = auth
+	err = client.Configure()
+	require.NoError(t, err)
+	aa.databricksClient = &client
+	client.AzureAuth = aa
+
+	authorizations := []string{}
+	for i := 0; i < 3; i++ {
+		var zi struct {
+			DefaultZone string `json:"default_zone"`
+		}
+		err = client.Get(context.Background(), "/clusters/list-zones", nil, &zi)
+		require.NoError(t, err)
+		authorizations = append(authorizations, zi.DefaultZone)
+	}
+	assert.Equal(t, []string{"Bearer dapi0", "Bearer dapi1", "Bearer dapi1"}, authorizations)
+	assert.Equal(t, 2, created)
+}
+
 func TestAzureAuth_configureWithClientSecretAAD(t *testing.T) {
 	aa := AzureAuth{}
 	aa.ResourceID = "/subscriptions/a/resourceGroups/b/providers/Microsoft.Databricks/workspaces/c"
@@ -392,6 +558,44 @@ func TestAzureEnvironment(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestAzureEnvironmentOfHost(t *testing.T) {
+	for host, environment := range map[string]string{
+		"https://adb-123.4.azuredatabricks.net/":      "public",
+		"adb-123.4.azuredatabricks.net":               "public",
+		"adb-123.4.azuredatabricks.net:443/":          "public",
+		"https://adb-123.4.databricks.azure.us":       "usgovernment",
+		"https://ADB-123.4.DATABRICKS.AZURE.CN/":      "china",
+		"https://abc.cloud.databricks.com":            "",
+		"https://azuredatabricks.net.example.com":     "",
+		"https://abc.gcp.databricks.com/?x=.azure.us": "",
+	} {
+		assert.Equal(t, environment, azureEnvironmentOfHost(host), host)
+	}
+}
+
+func TestAzureEnvironment_FromHost(t *testing.T) {
+	aa := AzureAuth{
+		databricksClient: &DatabricksClient{
+			Host: "https://adb-123.4.databricks.azure.us/",
+		},
+	}
+	env, err := aa.getAzureEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, azure.USGovernmentCloud, env)
+	assert.True(t, aa.databricksClient.IsAzure())
+
+	aa.databricksClient.Host = "https://adb-123.4.databricks.azure.cn/"
+	env, err = aa.getAzureEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, azure.ChinaCloud, env)
+
+	// explicit environment wins
+	aa.Environment = "public"
+	env, err = aa.getAzureEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, azure.PublicCloud, env)
+}
+
 func TestMaybeExtendError(t *testing.T) {
 	err := fmt.Errorf("Some test")
 	err2 := maybeExtendAuthzError(err)
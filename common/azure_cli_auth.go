@@ -86,7 +86,7 @@ func (aa *AzureAuth) cliAuthorizer(resource string) (autorest.Authorizer, error)
 	rct := refreshableCliToken{
 		lock:           &sync.RWMutex{},
 		resource:       resource,
-		refreshMinutes: 6,
+		refreshMinutes: aa.tokenRefreshMinutes(),
 	}
 	err := rct.refreshInternal(resource)
 	if err != nil {
@@ -102,7 +102,7 @@ func (aa *AzureAuth) configureWithAzureCLI() (func(r *http.Request) error, error
 	if !aa.databricksClient.IsAzure() {
 		return nil, nil
 	}
-	if aa.IsClientSecretSet() {
+	if aa.IsClientSecretSet() || aa.UseMSI {
 		return nil, nil
 	}
 	azureEnvironment, err := aa.getAzureEnvironment()
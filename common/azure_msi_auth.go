@@ -0,0 +1,141 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+// Azure Instance Metadata Service endpoint for managed identity tokens
+const azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+type refreshableMSIToken struct {
+	endpoint       string
+	resource       string
+	clientID       string
+	token          *adal.Token
+	lock           *sync.RWMutex
+	refreshMinutes int
+}
+
+// OAuthToken implements adal.OAuthTokenProvider
+func (rmt *refreshableMSIToken) OAuthToken() string {
+	rmt.lock.RLock()
+	defer rmt.lock.RUnlock()
+	if rmt.token == nil {
+		return ""
+	}
+	return rmt.token.OAuthToken()
+}
+
+// isFresh must be called with the lock held
+func (rmt *refreshableMSIToken) isFresh() bool {
+	refreshInterval := time.Duration(rmt.refreshMinutes) * time.Minute
+	return rmt.token != nil && !rmt.token.WillExpireIn(refreshInterval)
+}
+
+// EnsureFreshWithContext implements adal.RefresherWithContext
+func (rmt *refreshableMSIToken) EnsureFreshWithContext(ctx context.Context) error {
+	rmt.lock.RLock()
+	fresh := rmt.isFresh()
+	rmt.lock.RUnlock()
+	if fresh {
+		return nil
+	}
+	rmt.lock.Lock()
+	defer rmt.lock.Unlock()
+	if rmt.isFresh() {
+		return nil
+	}
+	return rmt.refreshInternal(ctx)
+}
+
+// RefreshWithContext implements adal.RefresherWithContext
+func (rmt *refreshableMSIToken) RefreshWithContext(ctx context.Context) error {
+	rmt.lock.Lock()
+	defer rmt.lock.Unlock()
+	return rmt.refreshInternal(ctx)
+}
+
+// RefreshExchangeWithContext implements adal.RefresherWithContext
+func (rmt *refreshableMSIToken) RefreshExchangeWithContext(ctx context.Context, resource string) error {
+	rmt.lock.Lock()
+	defer rmt.lock.Unlock()
+	return rmt.refreshInternal(ctx)
+}
+
+func (rmt *refreshableMSIToken) refreshInternal(ctx context.Context) error {
+	query := url.Values{}
+	query.Set("api-version", "2018-02-01")
+	query.Set("resource", rmt.resource)
+	if rmt.clientID != "" {
+		query.Set("client_id", rmt.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s?%s", rmt.endpoint, query.Encode()), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata", "true")
+	// metadata service is link-local and never goes through proxy
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot get managed identity token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get managed identity token: %s", resp.Status)
+	}
+	var token adal.Token
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return fmt.Errorf("cannot parse managed identity token: %w", err)
+	}
+	log.Printf("[INFO] Refreshed OAuth token for %s from managed identity, which expires on %s",
+		rmt.resource, token.Expires())
+	rmt.token = &token
+	return nil
+}
+
+func (aa *AzureAuth) msiAuthorizer(resource string) (autorest.Authorizer, error) {
+	endpoint := aa.azureMSIEndpoint
+	if endpoint == "" {
+		endpoint = azureIMDSEndpoint
+	}
+	rmt := refreshableMSIToken{
+		endpoint:       endpoint,
+		resource:       resource,
+		clientID:       aa.MSIClientID,
+		lock:           &sync.RWMutex{},
+		refreshMinutes: aa.tokenRefreshMinutes(),
+	}
+	err := rmt.refreshInternal(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return autorest.NewBearerAuthorizer(&rmt), nil
+}
+
+func (aa *AzureAuth) configureWithAzureManagedIdentity() (func(r *http.Request) error, error) {
+	if aa.databricksClient != nil && !aa.databricksClient.IsAzure() {
+		return nil, nil
+	}
+	if !aa.UseMSI {
+		return nil, nil
+	}
+	azureEnvironment, err := aa.getAzureEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	aa.AzureEnvironment = &azureEnvironment
+	log.Printf("[INFO] Using Azure Managed Identity authentication")
+	return aa.simpleAADRequestVisitor(context.TODO(), aa.msiAuthorizer, aa.addSpManagementTokenVisitor)
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureAuth_configureWithAzureManagedIdentity(t *testing.T) {
+	for clientID, expectedToken := range map[string]string{
+		"":    "system-assigned",
+		"abc": "user-assigned",
+	} {
+		t.Run(expectedToken, func(t *testing.T) {
+			defer CleanupEnvironment()()
+			msi := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "true", req.Header.Get("Metadata"))
+				assert.Equal(t, clientID, req.URL.Query().Get("client_id"))
+				token := expectedToken
+				if req.URL.Query().Get("resource") != AzureDatabricksResourceID {
+					token = "management"
+				}
+				err := json.NewEncoder(rw).Encode(map[string]string{
+					"access_token": token,
+					"expires_in":   "3600",
+					"expires_on":   fmt.Sprint(time.Now().Add(time.Hour).Unix()),
+					"token_type":   "Bearer",
+					"resource":     req.URL.Query().Get("resource"),
+				})
+				assert.NoError(t, err)
+			}))
+			defer msi.Close()
+			workspace := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/api/2.0/clusters/list-zones", req.RequestURI)
+				assert.Equal(t, "Bearer "+expectedToken, req.Header.Get("Authorization"))
+				assert.Equal(t, "management", req.Header.Get("X-Databricks-Azure-SP-Management-Token"))
+				_, err := rw.Write([]byte(`{"zones": ["a"]}`))
+				assert.NoError(t, err)
+			}))
+			defer workspace.Close()
+
+			client := &DatabricksClient{
+				Host: workspace.URL,
+				AzureAuth: AzureAuth{
+					ResourceID:       "/subscriptions/a/resourceGroups/b/providers/Microsoft.Databricks/workspaces/c",
+					UseMSI:           true,
+					MSIClientID:      clientID,
+					azureMSIEndpoint: msi.URL,
+				},
+			}
+			_, err := configureAndAuthenticate(client)
+			require.NoError(t, err)
+			var zi struct {
+				Zones []string `json:"zones"`
+			}
+			err = client.Get(context.Background(), "/clusters/list-zones", nil, &zi)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a"}, zi.Zones)
+		})
+	}
+}
+
+func TestAzureAuth_configureWithAzureManagedIdentity_NotUsed(t *testing.T) {
+	aa := AzureAuth{}
+	auth, err := aa.configureWithAzureManagedIdentity()
+	assert.Nil(t, auth)
+	assert.NoError(t, err)
+}
+
+func TestAzureAuth_configureWithAzureManagedIdentity_Unavailable(t *testing.T) {
+	msi := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(400)
+	}))
+	defer msi.Close()
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host: "https://adb-123.4.azuredatabricks.net",
+		AzureAuth: AzureAuth{
+			UseMSI:           true,
+			azureMSIEndpoint: msi.URL,
+		},
+	})
+	AssertErrorStartsWith(t, err, "cannot get managed identity token: 400 Bad Request")
+}
+
+func TestRefreshableMSIToken_Concurrent(t *testing.T) {
+	var refreshes int32
+	msi := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+		err := json.NewEncoder(rw).Encode(map[string]string{
+			"access_token": "x",
+			"expires_in":   "3600",
+			"expires_on":   fmt.Sprint(time.Now().Add(time.Hour).Unix()),
+			"token_type":   "Bearer",
+		})
+		assert.NoError(t, err)
+	}))
+	defer msi.Close()
+	rmt := &refreshableMSIToken{
+		endpoint:       msi.URL,
+		resource:       AzureDatabricksResourceID,
+		lock:           &sync.RWMutex{},
+		refreshMinutes: 5,
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, rmt.EnsureFreshWithContext(context.Background()))
+			assert.Equal(t, "x", rmt.OAuthToken())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCircuitCooldownSeconds is the time requests fail fast after the circuit is open
+const DefaultCircuitCooldownSeconds = 60
+
+// circuitBreaker counts consecutive server errors of all requests to the workspace
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	lastErr   APIError
+}
+
+func (c *DatabricksClient) circuitBreakerCooldown() time.Duration {
+	if c.CircuitCooldownSeconds <= 0 {
+		return DefaultCircuitCooldownSeconds * time.Second
+	}
+	return time.Duration(c.CircuitCooldownSeconds) * time.Second
+}
+
+func (c *DatabricksClient) circuitOpenError(cb *circuitBreaker) APIError {
+	return APIError{
+		ErrorCode: "CIRCUIT_OPEN",
+		Message: fmt.Sprintf("%s has returned %d consecutive server errors, failing fast for %s. "+
+			"The workspace might be upgrading, please retry later. Last error: %s",
+			c.Host, cb.failures, time.Until(cb.openUntil).Round(time.Second), cb.lastErr.Error()),
+		StatusCode: cb.lastErr.StatusCode,
+		Resource:   cb.lastErr.Resource,
+	}
+}
+
+// circuitOpen fails requests without sending them, while the circuit is open
+func (c *DatabricksClient) circuitOpen() error {
+	if c.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	if time.Now().Before(c.breaker.openUntil) {
+		return c.circuitOpenError(&c.breaker)
+	}
+	return nil
+}
+
+// recordServerError opens the circuit after CircuitBreakerThreshold consecutive server errors
+// and returns an error, that stops retries of the current request
+func (c *DatabricksClient) recordServerError(apiError APIError) error {
+	if c.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	c.breaker.failures++
+	c.breaker.lastErr = apiError
+	if c.breaker.failures < c.CircuitBreakerThreshold {
+		return nil
+	}
+	if time.Now().After(c.breaker.openUntil) {
+		// the first one or every failure after the cooldown opens the circuit again
+		c.breaker.openUntil = time.Now().Add(c.circuitBreakerCooldown())
+		log.Printf("[WARN] Circuit is open for %s after %d consecutive server errors",
+			c.Host, c.breaker.failures)
+	}
+	return c.circuitOpenError(&c.breaker)
+}
+
+// recordHealthy closes the circuit on any response, that is not a server error
+func (c *DatabricksClient) recordHealthy() {
+	if c.CircuitBreakerThreshold <= 0 {
+		return
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	if c.breaker.failures >= c.CircuitBreakerThreshold {
+		log.Printf("[INFO] Circuit is closed for %s", c.Host)
+	}
+	c.breaker.failures = 0
+	c.breaker.openUntil = time.Time{}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	healthy := false
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		hits++
+		if healthy {
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+			return
+		}
+		rw.WriteHeader(503)
+		_, err := rw.Write([]byte(`{"error_code": "TEMPORARILY_UNAVAILABLE", "message": "ClusterNotReadyException"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client := &DatabricksClient{
+		Host:                    server.URL,
+		Token:                   "..",
+		CircuitBreakerThreshold: 3,
+	}
+	require.NoError(t, client.Configure())
+	client.httpClient.RetryWaitMin = 10 * time.Millisecond
+	client.httpClient.RetryWaitMax = 10 * time.Millisecond
+	ctx := context.Background()
+
+	err := client.Get(ctx, "/clusters/get", nil, nil)
+	AssertErrorStartsWith(t, err, server.URL+" has returned 3 consecutive server errors, failing fast for 1m0s")
+	assert.Contains(t, err.Error(), "Last error: ClusterNotReadyException")
+	assert.Equal(t, 3, hits)
+
+	// open circuit doesn't send requests at all
+	err = client.Get(ctx, "/clusters/list", nil, nil)
+	ae, ok := err.(APIError)
+	require.True(t, ok)
+	assert.Equal(t, "CIRCUIT_OPEN", ae.ErrorCode)
+	assert.Equal(t, 503, ae.StatusCode)
+	assert.Equal(t, 3, hits)
+
+	// the next server error after the cooldown opens the circuit again
+	client.breaker.openUntil = time.Now().Add(-time.Second)
+	err = client.Get(ctx, "/clusters/list", nil, nil)
+	AssertErrorStartsWith(t, err, server.URL+" has returned 4 consecutive server errors")
+	assert.Equal(t, 4, hits)
+
+	// the first success closes it
+	client.breaker.openUntil = time.Now().Add(-time.Second)
+	healthy = true
+	err = client.Get(ctx, "/clusters/list", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, client.breaker.failures)
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	client := &DatabricksClient{}
+	assert.NoError(t, client.recordServerError(APIError{StatusCode: 503}))
+	assert.NoError(t, client.circuitOpen())
+	assert.Equal(t, 0, client.breaker.failures)
+}
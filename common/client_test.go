@@ -1,11 +1,21 @@
 package common
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func AssertErrorStartsWith(t *testing.T, err error, message string) bool {
@@ -125,6 +135,48 @@ func TestDatabricksClientConfigure_InvalidProfileGivesError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDatabricksClientConfigure_MissingProfileListsAvailable(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		ConfigFile: "testdata/.databrickscfg",
+		Profile:    "typo",
+	})
+	assert.EqualError(t, err, "testdata/.databrickscfg has no typo profile configured. "+
+		"Available profiles: DEFAULT, nohost, notoken, pat")
+}
+
+func TestDatabricksClientConfigure_ProfileFromEnv(t *testing.T) {
+	defer CleanupEnvironment()()
+	t.Setenv("DATABRICKS_CONFIG_FILE", "testdata/.databrickscfg")
+	t.Setenv("DATABRICKS_CONFIG_PROFILE", "pat")
+	dc, err := configureAndAuthenticate(&DatabricksClient{})
+	require.NoError(t, err)
+	assert.Equal(t, "pat", dc.Profile)
+	assert.Equal(t, "https://pat.cloud.databricks.com/", dc.Host)
+	assert.Equal(t, "dapi-pat", dc.Token)
+}
+
+func TestDatabricksClientConfigure_ExplicitProfileWinsOverEnv(t *testing.T) {
+	defer CleanupEnvironment()()
+	t.Setenv("DATABRICKS_CONFIG_FILE", "testdata/.invalid file")
+	t.Setenv("DATABRICKS_CONFIG_PROFILE", "pat")
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		ConfigFile: "testdata/.databrickscfg",
+		Profile:    "DEFAULT",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "PT0+IC9kZXYvdXJhbmRvbSA8PT0KYFZ", dc.Token)
+}
+
+func TestDatabricksClientConfigure_DefaultProfileWithoutEnv(t *testing.T) {
+	defer CleanupEnvironment()()
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		ConfigFile: "testdata/.databrickscfg",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "DEFAULT", dc.Profile)
+	assert.Equal(t, "PT0+IC9kZXYvdXJhbmRvbSA8PT0KYFZ", dc.Token)
+}
+
 func TestDatabricksClientConfigure_MissingFile(t *testing.T) {
 	_, err := configureAndAuthenticate(&DatabricksClient{
 		Token:      "connfigured",
@@ -156,3 +208,346 @@ func TestDatabricksClient_FormatURL(t *testing.T) {
 	client := DatabricksClient{Host: "https://some.host"}
 	assert.Equal(t, "https://some.host/#job/123", client.FormatURL("#job/123"))
 }
+
+func TestDatabricksClientConfigure_ProxyURL(t *testing.T) {
+	proxied := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = append(proxied, req.URL.String())
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")),
+			req.Header.Get("Proxy-Authorization"))
+		_, err := rw.Write([]byte(`{"a": "b"}`))
+		assert.NoError(t, err)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     "http://workspace.invalid",
+		Token:    "...",
+		ProxyURL: proxyURL.String(),
+	})
+	require.NoError(t, err)
+	var resp map[string]string
+	err = dc.Get(context.Background(), "/clusters/list", nil, &resp)
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp["a"])
+	assert.Equal(t, []string{"http://workspace.invalid/api/2.0/clusters/list"}, proxied)
+}
+
+func TestDatabricksClientConfigure_InvalidProxyURL(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://localhost:443",
+		Token:    "...",
+		ProxyURL: "://proxy",
+	})
+	AssertErrorStartsWith(t, err, "invalid proxy url: parse \"://proxy\": missing protocol scheme")
+
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://localhost:443",
+		Token:    "...",
+		ProxyURL: "proxy.corp:3128",
+	})
+	AssertErrorStartsWith(t, err, "invalid proxy url: proxy.corp:3128 must have scheme and host")
+}
+
+// socksServer accepts SOCKS5 connections with username and password and tunnels them to target
+func socksServer(t *testing.T, target string, requested chan<- string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	readBytes := func(r io.Reader, n int) []byte {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		assert.NoError(t, err)
+		return buf
+	}
+	readString := func(r io.Reader) string {
+		return string(readBytes(r, int(readBytes(r, 1)[0])))
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// version and supported methods, where 2 is username and password
+				assert.Equal(t, byte(5), readBytes(conn, 1)[0])
+				assert.Contains(t, readString(conn), "\x02")
+				_, _ = conn.Write([]byte{5, 2})
+				assert.Equal(t, byte(1), readBytes(conn, 1)[0])
+				assert.Equal(t, "user", readString(conn))
+				assert.Equal(t, "pass", readString(conn))
+				_, _ = conn.Write([]byte{1, 0})
+				// connect command with domain name address
+				assert.Equal(t, []byte{5, 1, 0, 3}, readBytes(conn, 4))
+				requested <- readString(conn)
+				readBytes(conn, 2)
+				upstream, err := net.Dial("tcp", target)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer upstream.Close()
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go func() {
+					_, _ = io.Copy(upstream, conn)
+				}()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestDatabricksClientConfigure_SOCKSProxy(t *testing.T) {
+	workspace := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "workspace.invalid", req.Host)
+		_, err := rw.Write([]byte(`{"a": "b"}`))
+		assert.NoError(t, err)
+	}))
+	defer workspace.Close()
+	requested := make(chan string, 1)
+	socks := socksServer(t, workspace.Listener.Addr().String(), requested)
+	defer socks.Close()
+
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:       "http://workspace.invalid",
+		Token:      "...",
+		SOCKSProxy: fmt.Sprintf("socks5://user:pass@%s", socks.Addr()),
+	})
+	require.NoError(t, err)
+	var resp map[string]string
+	err = dc.Get(context.Background(), "/clusters/list", nil, &resp)
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp["a"])
+	assert.Equal(t, "workspace.invalid", <-requested)
+}
+
+func TestDatabricksClientConfigure_InvalidSOCKSProxy(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:       "https://localhost:443",
+		Token:      "...",
+		SOCKSProxy: "http://bastion:1080",
+	})
+	AssertErrorStartsWith(t, err, "invalid proxy url: http://bastion:1080 must have socks5 scheme")
+
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:       "https://localhost:443",
+		Token:      "...",
+		SOCKSProxy: "socks5://bastion:1080",
+		HTTPSProxy: "http://proxy.corp:3128",
+	})
+	AssertErrorStartsWith(t, err, "socks proxy cannot be combined with http proxies")
+}
+
+func TestDatabricksClientConfigure_SchemeProxies(t *testing.T) {
+	proxied := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = append(proxied, req.URL.String())
+		_, err := rw.Write([]byte(`{}`))
+		assert.NoError(t, err)
+	}))
+	defer proxy.Close()
+
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:       "http://workspace.invalid",
+		Token:      "...",
+		HTTPProxy:  proxy.URL,
+		HTTPSProxy: "http://never.invalid:3128",
+		NoProxy:    "localhost",
+	})
+	require.NoError(t, err)
+	err = dc.Get(context.Background(), "/clusters/list", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://workspace.invalid/api/2.0/clusters/list"}, proxied)
+}
+
+func TestDatabricksClientProxyFunc(t *testing.T) {
+	envProxy, err := url.Parse("http://env:3128")
+	require.NoError(t, err)
+	dc := &DatabricksClient{
+		HTTPSProxy: "http://corp:3128",
+		NoProxy:    "internal.corp, .azuredatabricks.net,local:8443",
+	}
+	proxy, err := dc.proxyFunc(http.ProxyURL(envProxy))
+	require.NoError(t, err)
+	for target, expected := range map[string]string{
+		"https://abc.cloud.databricks.com":        "http://corp:3128",
+		"http://abc.cloud.databricks.com":         "http://env:3128",
+		"https://internal.corp/api":               "",
+		"https://api.internal.corp/api":           "",
+		"https://notinternal.corp/api":            "http://corp:3128",
+		"https://adb-123.4.azuredatabricks.net":   "",
+		"https://azuredatabricks.net":             "",
+		"https://local:8443/api":                  "",
+		"https://local:443/api":                   "http://corp:3128",
+		"https://ABC.AZUREDATABRICKS.NET/api/2.0": "",
+	} {
+		proxyURL, err := proxy(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		assert.Equal(t, expected, actual, target)
+	}
+
+	_, err = (&DatabricksClient{HTTPProxy: "proxy.corp:3128"}).proxyFunc(nil)
+	AssertErrorStartsWith(t, err, "invalid proxy url: proxy.corp:3128 must have scheme and host")
+
+	proxy, err = (&DatabricksClient{NoProxy: "*"}).proxyFunc(http.ProxyURL(envProxy))
+	require.NoError(t, err)
+	proxyURL, err := proxy(httptest.NewRequest("GET", "https://abc", nil))
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+}
+
+func TestDatabricksClientProxyFunc_ProxyURLWithNoProxy(t *testing.T) {
+	proxy, err := (&DatabricksClient{
+		ProxyURL: "http://corp:3128",
+		NoProxy:  "internal.corp",
+	}).proxyFunc(nil)
+	require.NoError(t, err)
+	for target, expected := range map[string]string{
+		"https://abc.cloud.databricks.com": "http://corp:3128",
+		"http://abc.cloud.databricks.com":  "http://corp:3128",
+		"https://internal.corp/api":        "",
+	} {
+		proxyURL, err := proxy(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		assert.Equal(t, expected, actual, target)
+	}
+
+	_, err = (&DatabricksClient{
+		ProxyURL:   "http://corp:3128",
+		HTTPSProxy: "http://other:3128",
+	}).proxyFunc(nil)
+	assert.EqualError(t, err, "proxy url cannot be combined with http or https proxy")
+}
+
+func TestDatabricksClientConfigure_RetryDefaults(t *testing.T) {
+	dc := &DatabricksClient{}
+	err := dc.Configure()
+	require.NoError(t, err)
+	assert.Equal(t, RetryStrategyLinear, dc.RetryStrategy)
+	assert.Equal(t, 10*time.Second, dc.httpClient.RetryWaitMin)
+	assert.Equal(t, 10*time.Second, dc.httpClient.RetryWaitMax)
+	assert.Equal(t, 30, dc.httpClient.RetryMax)
+	// linear backoff grows with every attempt
+	assert.Equal(t, 10*time.Second, dc.httpClient.Backoff(
+		dc.httpClient.RetryWaitMin, dc.httpClient.RetryWaitMax, 0, nil))
+	assert.Equal(t, 20*time.Second, dc.httpClient.Backoff(
+		dc.httpClient.RetryWaitMin, dc.httpClient.RetryWaitMax, 1, nil))
+}
+
+func TestDatabricksClientConfigure_RetryExponential(t *testing.T) {
+	dc := &DatabricksClient{
+		RetryStrategy:       RetryStrategyExponential,
+		RetryWaitMinSeconds: 1,
+		RetryWaitMaxSeconds: 30,
+		RetryMaxAttempts:    5,
+	}
+	err := dc.Configure()
+	require.NoError(t, err)
+	assert.Equal(t, 4, dc.httpClient.RetryMax)
+	min, max := dc.httpClient.RetryWaitMin, dc.httpClient.RetryWaitMax
+	assert.Equal(t, 1*time.Second, dc.httpClient.Backoff(min, max, 0, nil))
+	// second half of exponential wait is randomized
+	for i := 0; i < 10; i++ {
+		wait := dc.httpClient.Backoff(min, max, 2, nil)
+		assert.GreaterOrEqual(t, int64(wait), int64(2*time.Second))
+		assert.LessOrEqual(t, int64(wait), int64(4*time.Second))
+		wait = dc.httpClient.Backoff(min, max, 10, nil)
+		assert.GreaterOrEqual(t, int64(wait), int64(15*time.Second))
+		assert.LessOrEqual(t, int64(wait), int64(30*time.Second))
+	}
+}
+
+func TestDatabricksClientConfigure_RetryLinearJitter(t *testing.T) {
+	dc := &DatabricksClient{
+		RetryStrategy:       RetryStrategyLinear,
+		RetryWaitMinSeconds: 1,
+		RetryWaitMaxSeconds: 2,
+	}
+	err := dc.Configure()
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		wait := dc.httpClient.Backoff(dc.httpClient.RetryWaitMin, dc.httpClient.RetryWaitMax, 0, nil)
+		assert.GreaterOrEqual(t, int64(wait), int64(time.Second))
+		assert.LessOrEqual(t, int64(wait), int64(2*time.Second))
+	}
+}
+
+func TestDatabricksClientConfigure_RetryInvalid(t *testing.T) {
+	err := (&DatabricksClient{
+		RetryWaitMinSeconds: 20,
+		RetryWaitMaxSeconds: 5,
+	}).Configure()
+	AssertErrorStartsWith(t, err, "retry wait min (20s) cannot be greater than retry wait max (5s)")
+
+	err = (&DatabricksClient{
+		RetryMaxAttempts: -1,
+	}).Configure()
+	AssertErrorStartsWith(t, err, "retry wait and attempts cannot be negative")
+
+	err = (&DatabricksClient{
+		RetryStrategy: "fibonacci",
+	}).Configure()
+	AssertErrorStartsWith(t, err, "unknown retry strategy: fibonacci")
+}
+
+func TestDatabricksClientConfigure_RetryAfter(t *testing.T) {
+	dc := &DatabricksClient{}
+	err := dc.Configure()
+	require.NoError(t, err)
+	throttled := func(status int, retryAfter string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Retry-After": []string{retryAfter}},
+			Request:    httptest.NewRequest("GET", "/api/2.0/clusters/list", nil),
+		}
+	}
+	min, max := dc.httpClient.RetryWaitMin, dc.httpClient.RetryWaitMax
+	assert.Equal(t, 3*time.Second, dc.httpClient.Backoff(min, max, 5, throttled(429, "3")))
+	assert.Equal(t, 60*time.Second, dc.httpClient.Backoff(min, max, 5, throttled(429, "invalid")))
+	// only throttled responses are honored
+	assert.Equal(t, 60*time.Second, dc.httpClient.Backoff(min, max, 5, throttled(503, "3")))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC)
+	for header, expected := range map[string]time.Duration{
+		"0":                             0,
+		"120":                           2 * time.Minute,
+		"Thu, 01 Jul 2021 10:00:30 GMT": 30 * time.Second,
+		"Thu, 01 Jul 2021 09:00:00 GMT": 0,
+	} {
+		wait, ok := retryAfter(header, now)
+		assert.True(t, ok, header)
+		assert.Equal(t, expected, wait, header)
+	}
+	for _, header := range []string{"", "-1", "1.5", "tomorrow"} {
+		_, ok := retryAfter(header, now)
+		assert.False(t, ok, header)
+	}
+}
+
+func TestEffectiveAuthType(t *testing.T) {
+	for _, c := range []struct {
+		client   *DatabricksClient
+		authType string
+	}{
+		{&DatabricksClient{Host: "https://x.invalid", Token: ".."}, "pat"},
+		{&DatabricksClient{Host: "https://x.invalid", Username: "a", Password: "b"}, "basic"},
+	} {
+		assert.Equal(t, "", c.client.EffectiveAuthType())
+		require.NoError(t, c.client.Authenticate())
+		assert.Equal(t, c.authType, c.client.EffectiveAuthType())
+	}
+}
@@ -0,0 +1,144 @@
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	filename := filepath.Join(t.TempDir(), name)
+	err := ioutil.WriteFile(filename, pem.EncodeToMemory(&pem.Block{
+		Type:  blockType,
+		Bytes: der,
+	}), 0600)
+	require.NoError(t, err)
+	return filename
+}
+
+// selfSignedClientCert returns paths to PEM certificate and key, together with parsed certificate
+func selfSignedClientCert(t *testing.T) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePEM(t, "client.crt", "CERTIFICATE", der),
+		writePEM(t, "client.key", "EC PRIVATE KEY", keyDer), cert
+}
+
+func tlsServer(t *testing.T, clientCAs *x509.CertPool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write([]byte(`{}`))
+		assert.NoError(t, err)
+	}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+	}
+	server.StartTLS()
+	return server
+}
+
+func TestDatabricksClient_TLSCAFile(t *testing.T) {
+	server := tlsServer(t, nil)
+	defer server.Close()
+
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:  server.URL,
+		Token: "...",
+	})
+	require.NoError(t, err)
+	err = dc.Get(context.Background(), "/clusters/list", nil, nil)
+	assert.Error(t, err, "server certificate must not be trusted by default")
+
+	dc, err = configureAndAuthenticate(&DatabricksClient{
+		Host:      server.URL,
+		Token:     "...",
+		TLSCAFile: writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw),
+	})
+	require.NoError(t, err)
+	err = dc.Get(context.Background(), "/clusters/list", nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestDatabricksClient_TLSClientCertificate(t *testing.T) {
+	certFile, keyFile, cert := selfSignedClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server := tlsServer(t, clientCAs)
+	defer server.Close()
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:      server.URL,
+		Token:     "...",
+		TLSCAFile: caFile,
+	})
+	require.NoError(t, err)
+	err = dc.Get(context.Background(), "/clusters/list", nil, nil)
+	assert.Error(t, err, "server must require client certificate")
+
+	dc, err = configureAndAuthenticate(&DatabricksClient{
+		Host:        server.URL,
+		Token:       "...",
+		TLSCAFile:   caFile,
+		TLSCertFile: certFile,
+		TLSKeyFile:  keyFile,
+	})
+	require.NoError(t, err)
+	err = dc.Get(context.Background(), "/clusters/list", nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestDatabricksClient_TLSConfigErrors(t *testing.T) {
+	certFile, keyFile, _ := selfSignedClientCert(t)
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, ioutil.WriteFile(notPEM, []byte("abc"), 0600))
+
+	for _, tc := range []struct {
+		dc  *DatabricksClient
+		err string
+	}{
+		{&DatabricksClient{TLSCAFile: "testdata/missing.pem"}, "cannot read tls ca file"},
+		{&DatabricksClient{TLSCAFile: notPEM}, "tls ca file " + notPEM + " has no PEM certificates"},
+		{&DatabricksClient{TLSCertFile: certFile}, "tls cert file and tls key file must be set together"},
+		{&DatabricksClient{TLSKeyFile: keyFile}, "tls cert file and tls key file must be set together"},
+		{&DatabricksClient{TLSCertFile: keyFile, TLSKeyFile: certFile}, "cannot load tls client certificate"},
+	} {
+		_, err := tc.dc.tlsConfig()
+		if assert.Error(t, err) {
+			AssertErrorStartsWith(t, err, tc.err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -21,25 +22,40 @@ func AddContextToAllResources(p *schema.Provider, prefix string) {
 }
 
 func addContextToResource(name string, r *schema.Resource) {
+	timeouts := r.Timeouts != nil
 	if r.CreateContext != nil {
-		r.CreateContext = addContextToStage(name, r.CreateContext)
+		r.CreateContext = addContextToStage(name, timeouts, r.CreateContext)
 	}
 	if r.ReadContext != nil {
-		r.ReadContext = addContextToStage(name, r.ReadContext)
+		r.ReadContext = addContextToStage(name, timeouts, r.ReadContext)
 	}
 	if r.UpdateContext != nil {
-		r.UpdateContext = addContextToStage(name, r.UpdateContext)
+		r.UpdateContext = addContextToStage(name, timeouts, r.UpdateContext)
 	}
 	if r.DeleteContext != nil {
-		r.DeleteContext = addContextToStage(name, r.DeleteContext)
+		r.DeleteContext = addContextToStage(name, timeouts, r.DeleteContext)
 	}
 }
 
-func addContextToStage(name string,
+func addContextToStage(name string, timeouts bool,
 	f func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics) func(
 	ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		ctx = context.WithValue(ctx, ResourceName, name)
+		if timeouts {
+			ctx = context.WithValue(ctx, declaredTimeouts, true)
+		}
 		return f(ctx, d, m)
 	}
 }
+
+// OperationTimeout returns the time left until the deadline of `timeouts {}` block for
+// resources, that declare it. Terraform sets default 20 minute deadline on context of
+// every other resource, which is ignored in favor of fallback.
+func OperationTimeout(ctx context.Context, fallback time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok || ctx.Value(declaredTimeouts) == nil {
+		return fallback
+	}
+	return time.Until(deadline)
+}
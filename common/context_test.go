@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,3 +33,45 @@ func TestAddContextToAllResources(t *testing.T) {
 	AddContextToAllResources(p, "foo")
 	p.ResourcesMap["foo_bar"].CreateContext(context.Background(), nil, nil)
 }
+
+func TestOperationTimeout(t *testing.T) {
+	assert.Equal(t, time.Minute, OperationTimeout(context.Background(), time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+	assert.Equal(t, time.Minute, OperationTimeout(ctx, time.Minute),
+		"deadline of resource without timeouts is ignored")
+
+	timeout := OperationTimeout(context.WithValue(ctx, declaredTimeouts, true), time.Minute)
+	assert.True(t, timeout > time.Hour && timeout <= 2*time.Hour, timeout)
+}
+
+func TestAddContextToAllResources_DeclaredTimeouts(t *testing.T) {
+	var timeout time.Duration
+	check := func(ctx context.Context, rd *schema.ResourceData, i interface{}) diag.Diagnostics {
+		timeout = OperationTimeout(ctx, time.Minute)
+		return nil
+	}
+	p := &schema.Provider{
+		ResourcesMap: map[string]*schema.Resource{
+			"foo_bar": {
+				CreateContext: check,
+			},
+			"foo_baz": {
+				CreateContext: check,
+				Timeouts: &schema.ResourceTimeout{
+					Create: schema.DefaultTimeout(2 * time.Hour),
+				},
+			},
+		},
+	}
+	AddContextToAllResources(p, "foo")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	p.ResourcesMap["foo_bar"].CreateContext(ctx, nil, nil)
+	assert.Equal(t, time.Minute, timeout)
+
+	p.ResourcesMap["foo_baz"].CreateContext(ctx, nil, nil)
+	assert.True(t, timeout > time.Hour, timeout)
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// tokenFromCredentialHelper runs helper command, like `security find-generic-password -s databricks -w`,
+// with DATABRICKS_HOST in its environment and uses its output as the token
+func tokenFromCredentialHelper(helper, host string) (string, error) {
+	args := strings.Fields(helper)
+	if len(args) == 0 {
+		return "", fmt.Errorf("credential helper is empty")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "DATABRICKS_HOST="+host)
+	out, err := cmd.Output()
+	if ee, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("credential helper %s failed: %s", args[0],
+			strings.TrimSpace(string(ee.Stderr)))
+	}
+	if err != nil {
+		return "", fmt.Errorf("credential helper %s failed: %w", args[0], err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" || strings.ContainsAny(token, "\r\n") {
+		// multi-line output is most likely an error message or a help screen
+		return "", fmt.Errorf("credential helper %s must print a single token", args[0])
+	}
+	return token, nil
+}
+
+// configureWithCredentialHelper gets personal access token from OS keychain or
+// any other external program, so that it's not kept in plaintext files
+func (c *DatabricksClient) configureWithCredentialHelper() (func(r *http.Request) error, error) {
+	if c.CredentialHelper == "" {
+		return nil, nil
+	}
+	if c.Host == "" {
+		return nil, fmt.Errorf("host is empty, but is required by credential_helper")
+	}
+	c.fixHost()
+	token, err := tokenFromCredentialHelper(c.CredentialHelper, c.Host)
+	if err != nil {
+		return nil, err
+	}
+	// keep the token like ~/.databrickscfg does, so that its expiry could be checked
+	c.Token = token
+	log.Printf("[INFO] Using token from credential helper for %s", c.Host)
+	return c.authorizer("Bearer", c.Token), nil
+}
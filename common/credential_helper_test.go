@@ -0,0 +1,77 @@
+package common
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialHelper(t *testing.T) {
+	defer CleanupEnvironment()()
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		Host:             "abc.cloud.databricks.com",
+		CredentialHelper: "testdata/credential-helper --service databricks",
+	})
+	require.NoError(t, err)
+	r := httptest.NewRequest("GET", "/", nil)
+	require.NoError(t, dc.authVisitor(r))
+	assert.Equal(t, "Bearer dapi-abc.cloud.databricks.com", r.Header.Get("Authorization"))
+}
+
+func TestCredentialHelper_Errors(t *testing.T) {
+	defer CleanupEnvironment()()
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		CredentialHelper: "testdata/credential-helper",
+	})
+	assert.EqualError(t, err, "host is empty, but is required by credential_helper")
+
+	os.Setenv("FAIL", "yes")
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:             "https://abc.cloud.databricks.com",
+		CredentialHelper: "testdata/credential-helper",
+	})
+	assert.EqualError(t, err, "credential helper testdata/credential-helper failed: "+
+		"The specified item could not be found in the keychain.")
+
+	os.Setenv("FAIL", "multiline")
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:             "https://abc.cloud.databricks.com",
+		CredentialHelper: "testdata/credential-helper",
+	})
+	assert.EqualError(t, err, "credential helper testdata/credential-helper must print a single token")
+
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:             "https://abc.cloud.databricks.com",
+		CredentialHelper: "testdata/nope",
+	})
+	AssertErrorStartsWith(t, err, "credential helper testdata/nope failed: ")
+}
+
+func TestCredentialHelper_ConfigProfile(t *testing.T) {
+	defer CleanupEnvironment()()
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	err := ioutil.WriteFile(configFile, []byte(`[keychain]
+host = https://abc.cloud.databricks.com
+credential_helper = testdata/credential-helper
+`), 0600)
+	require.NoError(t, err)
+	dc, err := configureAndAuthenticate(&DatabricksClient{
+		ConfigFile: configFile,
+		Profile:    "keychain",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "dapi-abc.cloud.databricks.com", dc.Token)
+
+	os.Setenv("FAIL", "yes")
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		ConfigFile: configFile,
+		Profile:    "keychain",
+	})
+	assert.EqualError(t, err, "config file "+configFile+" has invalid credential helper in keychain profile: "+
+		"credential helper testdata/credential-helper failed: The specified item could not be found in the keychain.")
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugLogEntry is a single line of DebugLogFile
+type debugLogEntry struct {
+	Time       string            `json:"time"`
+	Type       string            `json:"type"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     int               `json:"status,omitempty"`
+	DurationMs int64             `json:"duration_ms,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       interface{}       `json:"body,omitempty"`
+	BodyBytes  int               `json:"body_bytes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// debugLogWriter appends redacted JSON lines, so that logs are safe to be shared
+type debugLogWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (c *DatabricksClient) configureDebugLog() error {
+	if c.DebugLogFile == "" || c.debugLog != nil {
+		return nil
+	}
+	f, err := os.OpenFile(c.DebugLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open debug log file: %w", err)
+	}
+	c.debugLog = &debugLogWriter{w: f}
+	return nil
+}
+
+func (l *debugLogWriter) write(entry debugLogEntry) {
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("[WARN] Cannot marshal debug log entry: %v", err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(line, '\n'))
+	if err != nil {
+		log.Printf("[WARN] Cannot write debug log: %v", err)
+	}
+}
+
+// redactedURL hides values of sensitive query parameters
+func redactedURL(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return requestURL
+	}
+	query := u.Query()
+	for k := range query {
+		if sensitiveFields[strings.ToLower(k)] {
+			query.Set(k, "**REDACTED**")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func flatHeaders(header http.Header) map[string]string {
+	flat := map[string]string{}
+	for k, v := range redactHeaders(header) {
+		flat[k] = strings.Join(v, ", ")
+	}
+	return flat
+}
+
+// setBody adds redacted JSON body to the entry. Other bodies are never logged,
+// because they cannot be redacted field by field.
+func (c *DatabricksClient) setBody(entry *debugLogEntry, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	var document map[string]interface{}
+	if json.Unmarshal(body, &document) != nil {
+		entry.BodyBytes = len(body)
+		return
+	}
+	entry.Body = c.recursiveMask(document)
+}
+
+func (c *DatabricksClient) logRequest(r *http.Request, body []byte) {
+	if c.debugLog == nil {
+		return
+	}
+	entry := debugLogEntry{
+		Type:    "request",
+		Method:  r.Method,
+		URL:     redactedURL(r.URL.String()),
+		Headers: flatHeaders(r.Header),
+	}
+	c.setBody(&entry, body)
+	c.debugLog.write(entry)
+}
+
+// logResponse records either response or error of a call started at start.
+// Body is nil for raw responses, that are read by the caller.
+func (c *DatabricksClient) logResponse(method, requestURL string, start time.Time,
+	resp *http.Response, body []byte, err error) {
+	if c.debugLog == nil {
+		return
+	}
+	entry := debugLogEntry{
+		Type:       "response",
+		Method:     method,
+		URL:        redactedURL(requestURL),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		entry.Headers = flatHeaders(resp.Header)
+		if resp.Request != nil {
+			entry.URL = redactedURL(resp.Request.URL.String())
+		}
+	}
+	if ae, ok := err.(APIError); ok {
+		entry.Status = ae.StatusCode
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	c.setBody(&entry, body)
+	c.debugLog.write(entry)
+}
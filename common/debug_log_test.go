@@ -0,0 +1,143 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readDebugLog(t *testing.T, filename string) (string, []debugLogEntry) {
+	raw, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	entries := []debugLogEntry{}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		var entry debugLogEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return string(raw), entries
+}
+
+func TestDebugLogFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("X-Request-Id", "req-1")
+			rw.Header().Set("X-Upstream-Token", "response-header-secret")
+			if req.URL.Path == "/api/2.0/failing" {
+				rw.WriteHeader(400)
+				_, err := rw.Write([]byte(`{"error_code": "INVALID_REQUEST", "message": "bad"}`))
+				assert.NoError(t, err)
+				return
+			}
+			_, err := rw.Write([]byte(`{"token_value": "response-secret", "name": "abc"}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	logFile := filepath.Join(t.TempDir(), "debug.log")
+	ws := DatabricksClient{
+		Host:         server.URL,
+		Token:        "request-token",
+		DebugLogFile: logFile,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = ws.Post(ctx, "/token/create", map[string]interface{}{
+		"comment": "abc",
+		"nested": map[string]string{
+			"password": "request-secret",
+		},
+	}, nil)
+	require.NoError(t, err)
+	err = ws.Get(ctx, "/imaginary/endpoint", map[string]string{
+		"token": "query-secret",
+		"page":  "2",
+	}, nil)
+	require.NoError(t, err)
+	err = ws.Get(ctx, "/failing", nil, nil)
+	require.Error(t, err)
+
+	raw, entries := readDebugLog(t, logFile)
+	for _, secret := range []string{
+		"request-token",
+		"request-secret",
+		"response-secret",
+		"response-header-secret",
+		"query-secret",
+	} {
+		assert.NotContains(t, raw, secret)
+	}
+	require.Len(t, entries, 6)
+
+	assert.Equal(t, "request", entries[0].Type)
+	assert.Equal(t, "POST", entries[0].Method)
+	assert.Equal(t, server.URL+"/api/2.0/token/create", entries[0].URL)
+	assert.Equal(t, "**REDACTED**", entries[0].Headers["Authorization"])
+	assert.Equal(t, map[string]interface{}{
+		"comment": "abc",
+		"nested": map[string]interface{}{
+			"password": "**REDACTED**",
+		},
+	}, entries[0].Body)
+
+	assert.Equal(t, "response", entries[1].Type)
+	assert.Equal(t, 200, entries[1].Status)
+	assert.Equal(t, "req-1", entries[1].Headers["X-Request-Id"])
+	assert.Equal(t, "**REDACTED**", entries[1].Headers["X-Upstream-Token"])
+	assert.Equal(t, map[string]interface{}{
+		"name":        "abc",
+		"token_value": "**REDACTED**",
+	}, entries[1].Body)
+
+	assert.Equal(t, server.URL+"/api/2.0/imaginary/endpoint?page=2&token=%2A%2AREDACTED%2A%2A", entries[2].URL)
+
+	assert.Equal(t, "response", entries[5].Type)
+	assert.Equal(t, 400, entries[5].Status)
+	assert.Equal(t, "bad", entries[5].Error)
+}
+
+func TestDebugLogFile_NonJSONBody(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "debug.log")
+	ws := DatabricksClient{
+		DebugLogFile: logFile,
+	}
+	err := ws.configureDebugLog()
+	require.NoError(t, err)
+
+	entry := debugLogEntry{}
+	ws.setBody(&entry, []byte("grant_type=client_credentials&client_secret=abc"))
+	assert.Nil(t, entry.Body)
+	assert.Equal(t, 47, entry.BodyBytes)
+}
+
+func TestDebugLogFile_CannotOpen(t *testing.T) {
+	ws := DatabricksClient{
+		DebugLogFile: filepath.Join(t.TempDir(), "missing", "debug.log"),
+	}
+	err := ws.Configure()
+	AssertErrorStartsWith(t, err, "cannot open debug log file")
+}
+
+func TestRedactHeadersWithUnknownCredentials(t *testing.T) {
+	redacted := redactHeaders(http.Header{
+		"Authorization":    []string{"Bearer abc"},
+		"X-Api-Token":      []string{"abc"},
+		"X-Client-Secret":  []string{"abc"},
+		"X-Correlation-Id": []string{"abc"},
+	})
+	assert.Equal(t, http.Header{
+		"Authorization":    []string{"**REDACTED**"},
+		"X-Api-Token":      []string{"**REDACTED**"},
+		"X-Client-Secret":  []string{"**REDACTED**"},
+		"X-Correlation-Id": []string{"abc"},
+	}, redacted)
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -16,37 +17,76 @@ var (
 	commonClient *DatabricksClient
 )
 
-// NewClientFromEnvironment makes very good client for testing purposes
+// NewClientFromEnvironment makes very good client for testing purposes. It reads
+// the same environment variables, as the provider does for its arguments.
 func NewClientFromEnvironment() *DatabricksClient {
-	debugBytes, err := strconv.Atoi(os.Getenv("DATABRICKS_DEBUG_TRUNCATE_BYTES"))
+	rateLimits, err := ParseRateLimits(os.Getenv("DATABRICKS_RATE_LIMITS"))
 	if err != nil {
-		debugBytes = DefaultTruncateBytes
-	}
-	debugHeaders, err := strconv.ParseBool(os.Getenv("DATABRICKS_DEBUG_HEADERS"))
-	if err != nil {
-		debugHeaders = false
+		panic(err)
 	}
 	client := DatabricksClient{
-		Host:                 os.Getenv("DATABRICKS_HOST"),
-		Token:                os.Getenv("DATABRICKS_TOKEN"),
-		Username:             os.Getenv("DATABRICKS_USERNAME"),
-		Password:             os.Getenv("DATABRICKS_PASSWORD"),
-		ConfigFile:           os.Getenv("DATABRICKS_CONFIG_FILE"),
-		Profile:              os.Getenv("DATABRICKS_CONFIG_PROFILE"),
-		GoogleServiceAccount: os.Getenv("DATABRICKS_GOOGLE_SERVICE_ACCOUNT"),
+		Host:                         os.Getenv("DATABRICKS_HOST"),
+		Token:                        os.Getenv("DATABRICKS_TOKEN"),
+		Username:                     os.Getenv("DATABRICKS_USERNAME"),
+		Password:                     os.Getenv("DATABRICKS_PASSWORD"),
+		AccountID:                    os.Getenv("DATABRICKS_ACCOUNT_ID"),
+		AccountHost:                  os.Getenv("DATABRICKS_ACCOUNT_HOST"),
+		ClientID:                     os.Getenv("DATABRICKS_CLIENT_ID"),
+		ClientSecret:                 os.Getenv("DATABRICKS_CLIENT_SECRET"),
+		OIDCAudience:                 os.Getenv("DATABRICKS_OIDC_AUDIENCE"),
+		AuthType:                     os.Getenv("DATABRICKS_AUTH_TYPE"),
+		CredentialHelper:             os.Getenv("DATABRICKS_CREDENTIAL_HELPER"),
+		ConfigFile:                   os.Getenv("DATABRICKS_CONFIG_FILE"),
+		Profile:                      os.Getenv("DATABRICKS_CONFIG_PROFILE"),
+		GoogleServiceAccount:         os.Getenv("DATABRICKS_GOOGLE_SERVICE_ACCOUNT"),
+		GoogleCredentials:            os.Getenv("DATABRICKS_GOOGLE_CREDENTIALS"),
+		ImpersonateServiceAccount:    os.Getenv("DATABRICKS_GOOGLE_IMPERSONATE_SERVICE_ACCOUNT"),
+		GoogleImpersonationDelegates: ParseList(os.Getenv("DATABRICKS_GOOGLE_IMPERSONATION_DELEGATES")),
 		AzureAuth: AzureAuth{
-			ResourceID:     os.Getenv("DATABRICKS_AZURE_WORKSPACE_RESOURCE_ID"),
-			WorkspaceName:  os.Getenv("DATABRICKS_AZURE_WORKSPACE_NAME"),
-			ResourceGroup:  os.Getenv("DATABRICKS_AZURE_RESOURCE_GROUP"),
-			SubscriptionID: os.Getenv("ARM_SUBSCRIPTION_ID"),
-			ClientID:       os.Getenv("ARM_CLIENT_ID"),
-			ClientSecret:   os.Getenv("ARM_CLIENT_SECRET"),
-			TenantID:       os.Getenv("ARM_TENANT_ID"),
-			Environment:    os.Getenv("ARM_ENVIRONMENT"),
+			ResourceID: envFirst("DATABRICKS_AZURE_WORKSPACE_RESOURCE_ID",
+				"AZURE_DATABRICKS_WORKSPACE_RESOURCE_ID"),
+			WorkspaceName:           os.Getenv("DATABRICKS_AZURE_WORKSPACE_NAME"),
+			ResourceGroup:           os.Getenv("DATABRICKS_AZURE_RESOURCE_GROUP"),
+			SubscriptionID:          envFirst("DATABRICKS_AZURE_SUBSCRIPTION_ID", "ARM_SUBSCRIPTION_ID"),
+			ClientID:                envFirst("DATABRICKS_AZURE_CLIENT_ID", "ARM_CLIENT_ID"),
+			ClientSecret:            envFirst("DATABRICKS_AZURE_CLIENT_SECRET", "ARM_CLIENT_SECRET"),
+			TenantID:                envFirst("DATABRICKS_AZURE_TENANT_ID", "ARM_TENANT_ID"),
+			Environment:             os.Getenv("ARM_ENVIRONMENT"),
+			UseMSI:                  envBool("ARM_USE_MSI"),
+			MSIClientID:             os.Getenv("DATABRICKS_AZURE_MSI_CLIENT_ID"),
+			TokenRefreshMinutes:     envInt("DATABRICKS_AZURE_TOKEN_REFRESH_MINUTES", 0),
+			PATTokenDurationSeconds: os.Getenv("DATABRICKS_AZURE_PAT_TOKEN_DURATION_SECONDS"),
+			UsePATForCLI:            envBool("DATABRICKS_AZURE_USE_PAT_FOR_CLI"),
+			UsePATForSPN:            envBool("DATABRICKS_AZURE_USE_PAT_FOR_SPN"),
 		},
-		RateLimitPerSecond: 10,
-		DebugTruncateBytes: debugBytes,
-		DebugHeaders:       debugHeaders,
+		ProxyURL:                  os.Getenv("DATABRICKS_PROXY_URL"),
+		HTTPProxy:                 os.Getenv("DATABRICKS_HTTP_PROXY"),
+		HTTPSProxy:                os.Getenv("DATABRICKS_HTTPS_PROXY"),
+		NoProxy:                   os.Getenv("DATABRICKS_NO_PROXY"),
+		SOCKSProxy:                os.Getenv("DATABRICKS_SOCKS_PROXY"),
+		TLSCAFile:                 os.Getenv("DATABRICKS_TLS_CA_FILE"),
+		TLSCertFile:               os.Getenv("DATABRICKS_TLS_CERT_FILE"),
+		TLSKeyFile:                os.Getenv("DATABRICKS_TLS_KEY_FILE"),
+		InsecureSkipVerify:        envBool("DATABRICKS_SKIP_VERIFY"),
+		DevelopmentMode:           envBool("DATABRICKS_DEV"),
+		HTTPTimeoutSeconds:        envInt("DATABRICKS_HTTP_TIMEOUT_SECONDS", 0),
+		RateLimitPerSecond:        envInt("DATABRICKS_RATE_LIMIT", 10),
+		MaxConcurrentRequests:     envInt("DATABRICKS_MAX_CONCURRENT_REQUESTS", 0),
+		RateLimits:                rateLimits,
+		GzipRequests:              envBool("DATABRICKS_GZIP_REQUESTS"),
+		RetryStrategy:             os.Getenv("DATABRICKS_RETRY_STRATEGY"),
+		RetryWaitMinSeconds:       envInt("DATABRICKS_RETRY_WAIT_MIN_SECONDS", 0),
+		RetryWaitMaxSeconds:       envInt("DATABRICKS_RETRY_WAIT_MAX_SECONDS", 0),
+		RetryMaxAttempts:          envInt("DATABRICKS_RETRY_MAX_ATTEMPTS", 0),
+		FailedCallCooldownSeconds: envInt("DATABRICKS_FAILED_CALL_COOLDOWN_SECONDS", 0),
+		CircuitBreakerThreshold:   envInt("DATABRICKS_CIRCUIT_BREAKER_THRESHOLD", 0),
+		CircuitCooldownSeconds:    envInt("DATABRICKS_CIRCUIT_COOLDOWN_SECONDS", 0),
+		ExpiringPATsWarningDays:   envInt("DATABRICKS_EXPIRING_PATS_WARNING_DAYS", 0),
+		DebugTruncateBytes:        envInt("DATABRICKS_DEBUG_TRUNCATE_BYTES", DefaultTruncateBytes),
+		DebugHeaders:              envBool("DATABRICKS_DEBUG_HEADERS"),
+		DebugLogFile:              os.Getenv("DATABRICKS_DEBUG_LOG_FILE"),
+		CacheResponses:            envBool("DATABRICKS_CACHE_RESPONSES"),
+		UserAgentExtra:            os.Getenv("DATABRICKS_USER_AGENT_EXTRA"),
 	}
 	err = client.Configure()
 	if err != nil {
@@ -55,6 +95,63 @@ func NewClientFromEnvironment() *DatabricksClient {
 	return &client
 }
 
+// envFirst returns the first non-empty environment variable
+func envFirst(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func envInt(name string, defaultValue int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+func envBool(name string) bool {
+	v, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return false
+	}
+	return v
+}
+
+// ParseRateLimits parses comma-separated family=limit pairs, like "scim=5,dbfs=30"
+func ParseRateLimits(raw string) (map[string]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	rateLimits := map[string]int{}
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rate limit: %s", pair)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %s", pair)
+		}
+		rateLimits[strings.TrimSpace(kv[0])] = limit
+	}
+	return rateLimits, nil
+}
+
+// ParseList parses comma-separated values, like "a@x.iam.gserviceaccount.com,b@x.iam.gserviceaccount.com"
+func ParseList(raw string) (values []string) {
+	for _, v := range strings.Split(raw, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return
+}
+
 // ResetCommonEnvironmentClient resets test dummy
 func ResetCommonEnvironmentClient() {
 	commonClient = nil
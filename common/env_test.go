@@ -26,3 +26,62 @@ func TestCommonEnvironmentClient(t *testing.T) {
 	}
 	assert.Equal(t, "databricks-tf-provider/"+version+" (+cluster) terraform/0.12", c.userAgent(ctx))
 }
+
+func TestUserAgentExtra(t *testing.T) {
+	c := &DatabricksClient{
+		UserAgentExtra: "partner/acme ",
+	}
+	assert.Equal(t, "databricks-tf-provider/"+version+" (+unknown) terraform/unknown partner/acme",
+		c.userAgent(context.Background()))
+
+	c.UserAgentExtra = "partner/acme\r\nX-Injected: true"
+	err := c.Configure()
+	assert.EqualError(t, err, "user agent extra must be a single line")
+}
+
+func TestNewClientFromEnvironment(t *testing.T) {
+	defer CleanupEnvironment()()
+	os.Setenv("DATABRICKS_HOST", "https://x")
+	os.Setenv("DATABRICKS_TOKEN", ".")
+	os.Setenv("DATABRICKS_RATE_LIMITS", "scim=5, dbfs=30")
+	os.Setenv("DATABRICKS_HTTP_TIMEOUT_SECONDS", "120")
+	os.Setenv("DATABRICKS_SKIP_VERIFY", "true")
+	os.Setenv("DATABRICKS_CIRCUIT_BREAKER_THRESHOLD", "5")
+	os.Setenv("DATABRICKS_AZURE_CLIENT_ID", "a")
+	os.Setenv("ARM_CLIENT_ID", "b")
+	os.Setenv("ARM_TENANT_ID", "c")
+	os.Setenv("DATABRICKS_AZURE_USE_PAT_FOR_CLI", "yes")
+	c := NewClientFromEnvironment()
+	assert.Equal(t, map[string]int{"scim": 5, "dbfs": 30}, c.RateLimits)
+	assert.Equal(t, 120, c.HTTPTimeoutSeconds)
+	assert.True(t, c.InsecureSkipVerify)
+	assert.Equal(t, 5, c.CircuitBreakerThreshold)
+	assert.Equal(t, 10, c.RateLimitPerSecond)
+	assert.Equal(t, DefaultTruncateBytes, c.DebugTruncateBytes)
+	// provider-specific variables take precedence over ARM ones
+	assert.Equal(t, "a", c.AzureAuth.ClientID)
+	assert.Equal(t, "c", c.AzureAuth.TenantID)
+	// invalid booleans are false
+	assert.False(t, c.AzureAuth.UsePATForCLI)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	rateLimits, err := ParseRateLimits("")
+	assert.NoError(t, err)
+	assert.Nil(t, rateLimits)
+
+	rateLimits, err = ParseRateLimits("jobs=1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"jobs": 1}, rateLimits)
+
+	_, err = ParseRateLimits("jobs")
+	assert.EqualError(t, err, "invalid rate limit: jobs")
+
+	_, err = ParseRateLimits("jobs=1,scim=many")
+	assert.EqualError(t, err, "invalid rate limit: scim=many")
+}
+
+func TestParseList(t *testing.T) {
+	assert.Nil(t, ParseList(""))
+	assert.Equal(t, []string{"a", "b"}, ParseList(" a, ,b "))
+}
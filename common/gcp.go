@@ -1,45 +1,141 @@
 package common
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
 )
 
-func (c *DatabricksClient) getGoogleOIDCSource() (oauth2.TokenSource, error) {
+var googleScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/compute",
+}
+
+// impersonatedAccount is the service account from service_account_impersonation_url
+var impersonatedAccount = regexp.MustCompile(`/serviceAccounts/([^/:]+):generateAccessToken$`)
+
+type googleCredentialsFile struct {
+	Type                           string `json:"type"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url,omitempty"`
+}
+
+// loadGoogleCredentials uses GoogleCredentials, that are either a path to or contents
+// of service account key or workload identity federation config, instead of
+// ambient credentials. Workload identity federation allows CI systems, like GitHub
+// Actions, to authenticate without long-lived keys.
+func (c *DatabricksClient) loadGoogleCredentials() error {
+	if c.GoogleCredentials == "" || c.googleCredentialsLoaded {
+		return nil
+	}
+	credentials := []byte(c.GoogleCredentials)
+	if !strings.HasPrefix(strings.TrimSpace(c.GoogleCredentials), "{") {
+		var err error
+		credentials, err = ioutil.ReadFile(c.GoogleCredentials)
+		if err != nil {
+			return fmt.Errorf("cannot read google credentials: %w", err)
+		}
+	}
+	var file googleCredentialsFile
+	err := json.Unmarshal(credentials, &file)
+	if err != nil {
+		return fmt.Errorf("cannot parse google credentials: %w", err)
+	}
+	switch file.Type {
+	case "service_account", "authorized_user":
+	case "external_account":
+		log.Printf("[INFO] Using Google workload identity federation")
+		m := impersonatedAccount.FindStringSubmatch(file.ServiceAccountImpersonationURL)
+		if c.GoogleServiceAccount == "" && len(m) == 2 {
+			// federated identity already impersonates the service account
+			c.GoogleServiceAccount = m[1]
+		}
+	default:
+		return fmt.Errorf("unsupported google credentials type: %s", file.Type)
+	}
+	c.googleAuthOptions = append(append([]option.ClientOption{}, c.googleAuthOptions...),
+		option.WithCredentialsJSON(credentials))
+	c.googleCredentialsLoaded = true
+	return nil
+}
+
+// googleClientOptions authenticates Google clients as ImpersonateServiceAccount
+// on top of ambient credentials, if it's configured
+func (c *DatabricksClient) googleClientOptions() ([]option.ClientOption, error) {
+	if c.ImpersonateServiceAccount == "" {
+		return c.googleAuthOptions, nil
+	}
+	ts, err := impersonate.CredentialsTokenSource(c.InitContext, impersonate.CredentialsConfig{
+		TargetPrincipal: c.ImpersonateServiceAccount,
+		Scopes:          googleScopes,
+	}, c.googleAuthOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot impersonate %s: %w", c.ImpersonateServiceAccount, err)
+	}
+	// fail early, if ambient credentials cannot mint tokens for impersonated account
+	_, err = ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("cannot impersonate %s. Make sure that ambient credentials "+
+			"have Service Account Token Creator role on it: %w", c.ImpersonateServiceAccount, err)
+	}
+	log.Printf("[INFO] Impersonating %s with ambient Google credentials", c.ImpersonateServiceAccount)
+	options := append([]option.ClientOption{}, c.googleAuthOptions...)
+	return append(options, option.WithTokenSource(ts)), nil
+}
+
+func (c *DatabricksClient) getGoogleOIDCSource(options []option.ClientOption) (oauth2.TokenSource, error) {
 	// source for generateIdToken
 	ts, err := impersonate.IDTokenSource(c.InitContext, impersonate.IDTokenConfig{
 		Audience:        c.Host,
 		TargetPrincipal: c.GoogleServiceAccount,
 		IncludeEmail:    true,
-	}, c.googleAuthOptions...)
+		Delegates:       c.GoogleImpersonationDelegates,
+	}, options...)
 	if err != nil {
 		err = fmt.Errorf("could not obtain OIDC token. %w Running 'gcloud auth application-default login' may help", err)
 		return nil, err
 	}
+	if len(c.GoogleImpersonationDelegates) > 0 {
+		log.Printf("[INFO] Impersonating %s through %s", c.GoogleServiceAccount,
+			strings.Join(c.GoogleImpersonationDelegates, " -> "))
+	}
 	// TODO: verify that refreshers work...
 	ts = oauth2.ReuseTokenSource(nil, ts)
 	return ts, nil
 }
 
 func (c *DatabricksClient) configureWithGoogleForAccountsAPI() (func(r *http.Request) error, error) {
-	if c.GoogleServiceAccount == "" || !c.IsGcp() || !c.isAccountsClient() {
+	if !c.IsGcp() || !c.isAccountsClient() {
 		return nil, nil
 	}
-	oidcSource, err := c.getGoogleOIDCSource()
+	// credentials are loaded only for GCP hosts, as they may name the service account
+	if err := c.loadGoogleCredentials(); err != nil {
+		return nil, err
+	}
+	if c.GoogleServiceAccount == "" {
+		return nil, nil
+	}
+	options, err := c.googleClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	oidcSource, err := c.getGoogleOIDCSource(options)
 	if err != nil {
 		return nil, err
 	}
 	// source for generateAccessToken
 	platformSource, err := impersonate.CredentialsTokenSource(c.InitContext, impersonate.CredentialsConfig{
 		TargetPrincipal: c.GoogleServiceAccount,
-		Scopes: []string{
-			"https://www.googleapis.com/auth/cloud-platform",
-			"https://www.googleapis.com/auth/compute",
-		},
-	}, c.googleAuthOptions...)
+		Scopes:          googleScopes,
+		Delegates:       c.GoogleImpersonationDelegates,
+	}, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,10 +160,20 @@ func newOidcAuthorizerForAccountsAPI(oidcSource oauth2.TokenSource,
 }
 
 func (c *DatabricksClient) configureWithGoogleForWorkspace() (func(r *http.Request) error, error) {
-	if c.GoogleServiceAccount == "" || !c.IsGcp() || c.isAccountsClient() {
+	if !c.IsGcp() || c.isAccountsClient() {
+		return nil, nil
+	}
+	if err := c.loadGoogleCredentials(); err != nil {
+		return nil, err
+	}
+	if c.GoogleServiceAccount == "" {
 		return nil, nil
 	}
-	oidcSource, err := c.getGoogleOIDCSource()
+	options, err := c.googleClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	oidcSource, err := c.getGoogleOIDCSource(options)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,10 @@
 package common
 
 import (
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,7 +24,7 @@ func TestGoogleOIDC(t *testing.T) {
 	}
 	client.configureHTTPCLient()
 
-	_, err := client.getGoogleOIDCSource()
+	_, err := client.getGoogleOIDCSource(client.googleAuthOptions)
 	require.NoError(t, err)
 }
 
@@ -89,3 +92,182 @@ func TestNewOidcAuthorizerForWorkspace(t *testing.T) {
 	assert.Equal(t, "Bearer abc", request.Header.Get("Authorization"))
 	assert.Equal(t, "", request.Header.Get("X-Databricks-GCP-SA-Access-Token"))
 }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func fakeIAMCredentials(status int, body string) option.ClientOption {
+	return option.WithHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		}),
+	})
+}
+
+func TestGoogleClientOptions(t *testing.T) {
+	defer CleanupEnvironment()()
+	client := &DatabricksClient{
+		Host:                 "https://123.4.gcp.databricks.com/",
+		GoogleServiceAccount: "a",
+		googleAuthOptions: []option.ClientOption{
+			fakeIAMCredentials(200, `{"accessToken": "xyz", "expireTime": "2100-01-01T00:00:00Z"}`),
+		},
+	}
+	client.configureHTTPCLient()
+
+	options, err := client.googleClientOptions()
+	require.NoError(t, err)
+	assert.Equal(t, client.googleAuthOptions, options)
+
+	client.ImpersonateServiceAccount = "b"
+	options, err = client.googleClientOptions()
+	require.NoError(t, err)
+	assert.Len(t, options, 2)
+	assert.Equal(t, client.googleAuthOptions[0], options[0])
+	assert.Len(t, client.googleAuthOptions, 1, "original options must not be modified")
+}
+
+func TestGoogleClientOptions_NoTokenCreator(t *testing.T) {
+	defer CleanupEnvironment()()
+	client := &DatabricksClient{
+		Host:                      "https://123.4.gcp.databricks.com/",
+		GoogleServiceAccount:      "a",
+		ImpersonateServiceAccount: "b",
+		googleAuthOptions: []option.ClientOption{
+			fakeIAMCredentials(403, `{"error": {"message": "Permission 'iam.serviceAccounts.getAccessToken' denied"}}`),
+		},
+	}
+	client.configureHTTPCLient()
+
+	_, err := client.configureWithGoogleForWorkspace()
+	AssertErrorStartsWith(t, err, "cannot impersonate b. Make sure that ambient credentials "+
+		"have Service Account Token Creator role on it")
+	assert.Contains(t, err.Error(), "iam.serviceAccounts.getAccessToken")
+}
+
+func TestGoogleOIDC_Delegates(t *testing.T) {
+	defer CleanupEnvironment()()
+	var requests []string
+	client := &DatabricksClient{
+		Host:                         "https://123.4.gcp.databricks.com/",
+		GoogleServiceAccount:         "c@x.iam.gserviceaccount.com",
+		GoogleImpersonationDelegates: []string{"a@x.iam.gserviceaccount.com", "b@x.iam.gserviceaccount.com"},
+		googleAuthOptions: []option.ClientOption{
+			option.WithHTTPClient(&http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					body, err := ioutil.ReadAll(r.Body)
+					require.NoError(t, err)
+					requests = append(requests, r.URL.Path+" "+string(body))
+					return &http.Response{
+						StatusCode: 200,
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(strings.NewReader(`{"token": "xyz"}`)),
+						Request:    r,
+					}, nil
+				}),
+			}),
+		},
+	}
+	client.configureHTTPCLient()
+
+	ts, err := client.getGoogleOIDCSource(client.googleAuthOptions)
+	require.NoError(t, err)
+	token, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", token.AccessToken)
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0], "/serviceAccounts/c@x.iam.gserviceaccount.com:generateIdToken")
+	assert.Contains(t, requests[0], `"delegates":["projects/-/serviceAccounts/a@x.iam.gserviceaccount.com",`+
+		`"projects/-/serviceAccounts/b@x.iam.gserviceaccount.com"]`)
+}
+
+func TestLoadGoogleCredentials_ExternalAccount(t *testing.T) {
+	client := &DatabricksClient{
+		Host: "https://123.4.gcp.databricks.com/",
+		GoogleCredentials: `{
+			"type": "external_account",
+			"audience": "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/p/providers/github",
+			"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+			"token_url": "https://sts.googleapis.com/v1/token",
+			"service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/ci@p.iam.gserviceaccount.com:generateAccessToken",
+			"credential_source": {"file": "/tmp/token"}
+		}`,
+	}
+	err := client.loadGoogleCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "ci@p.iam.gserviceaccount.com", client.GoogleServiceAccount)
+	assert.Len(t, client.googleAuthOptions, 1)
+
+	// credentials are loaded only once
+	err = client.loadGoogleCredentials()
+	require.NoError(t, err)
+	assert.Len(t, client.googleAuthOptions, 1)
+}
+
+func TestLoadGoogleCredentials_ExplicitServiceAccountWins(t *testing.T) {
+	client := &DatabricksClient{
+		GoogleServiceAccount: "explicit@p.iam.gserviceaccount.com",
+		GoogleCredentials: `{"type": "external_account", "service_account_impersonation_url": ` +
+			`"https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/ci@p.iam.gserviceaccount.com:generateAccessToken"}`,
+	}
+	err := client.loadGoogleCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, "explicit@p.iam.gserviceaccount.com", client.GoogleServiceAccount)
+}
+
+func TestLoadGoogleCredentials_File(t *testing.T) {
+	client := &DatabricksClient{
+		GoogleCredentials: "testdata/gcp/service-account.json",
+	}
+	err := client.loadGoogleCredentials()
+	require.NoError(t, err)
+	assert.Len(t, client.googleAuthOptions, 1)
+	assert.Equal(t, "", client.GoogleServiceAccount)
+}
+
+func TestLoadGoogleCredentials_Errors(t *testing.T) {
+	for credentials, expected := range map[string]string{
+		"testdata/gcp/missing.json": "cannot read google credentials",
+		"{":                         "cannot parse google credentials",
+		`{"type": "whatever"}`:      "unsupported google credentials type: whatever",
+	} {
+		err := (&DatabricksClient{GoogleCredentials: credentials}).loadGoogleCredentials()
+		AssertErrorStartsWith(t, err, expected)
+	}
+}
+
+func TestConfigureWithGoogleForWorkspace_InvalidCredentials(t *testing.T) {
+	client := &DatabricksClient{
+		Host:              "https://123.4.gcp.databricks.com/",
+		GoogleCredentials: `{"type": "whatever"}`,
+	}
+	_, err := client.configureWithGoogleForWorkspace()
+	AssertErrorStartsWith(t, err, "unsupported google credentials type: whatever")
+}
+
+func TestConfigureWithGoogle_IgnoresCredentialsOutsideOfGcp(t *testing.T) {
+	for _, host := range []string{
+		"https://abc.cloud.databricks.com",
+		"https://adb-123.4.azuredatabricks.net",
+	} {
+		client := &DatabricksClient{
+			Host:              host,
+			AccountID:         "abc",
+			GoogleCredentials: "testdata/gcp/missing.json",
+		}
+		authorizer, err := client.configureWithGoogleForWorkspace()
+		assert.NoError(t, err)
+		assert.Nil(t, authorizer)
+		authorizer, err = client.configureWithGoogleForAccountsAPI()
+		assert.NoError(t, err)
+		assert.Nil(t, authorizer)
+	}
+}
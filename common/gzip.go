@@ -0,0 +1,40 @@
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// gzipMinBytes is the size of request body, after which compression pays off
+const gzipMinBytes = 64 * 1024
+
+// gzipRequestBody replaces large request body with its gzip-compressed version, that every
+// retry of the request sends again. Response bodies are decompressed by http.Transport,
+// which asks for gzip on its own.
+func gzipRequestBody(request *http.Request, body []byte) error {
+	if len(body) < gzipMinBytes || request.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	compressed := buf.Bytes()
+	log.Printf("[DEBUG] Compressed %d bytes of %s %s to %d", len(body),
+		request.Method, request.URL.Path, len(compressed))
+	request.Header.Set("Content-Encoding", "gzip")
+	request.Body = ioutil.NopCloser(bytes.NewReader(compressed))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	request.ContentLength = int64(len(compressed))
+	return nil
+}
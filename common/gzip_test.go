@@ -0,0 +1,59 @@
+package common
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipRequests(t *testing.T) {
+	large := strings.Repeat("a", gzipMinBytes)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		if req.URL.Path == "/api/2.0/workspace/import" {
+			assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+			assert.Less(t, len(body), gzipMinBytes)
+			reader, err := gzip.NewReader(strings.NewReader(string(body)))
+			assert.NoError(t, err)
+			body, err = ioutil.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), large)
+		} else {
+			assert.Equal(t, "", req.Header.Get("Content-Encoding"))
+		}
+		rw.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(rw)
+		_, err = writer.Write([]byte(`{"status": "ok"}`))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+	}))
+	defer server.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:         server.URL,
+		Token:        "..",
+		GzipRequests: true,
+	})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/workspace/import", "/workspace/mkdirs"} {
+		content := "small"
+		if path == "/workspace/import" {
+			content = large
+		}
+		var resp map[string]string
+		err = client.Post(context.Background(), path, map[string]string{
+			"content": content,
+		}, &resp)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp["status"])
+	}
+}
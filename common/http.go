@@ -6,13 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/go-querystring/query"
 	"github.com/hashicorp/go-retryablehttp"
@@ -49,6 +52,8 @@ type APIError struct {
 	Message    string
 	Resource   string
 	StatusCode int
+	// RequestID is X-Request-Id of the failed response, that Databricks support asks for
+	RequestID string
 }
 
 // Error returns error message string instead of
@@ -60,13 +65,18 @@ func (apiError APIError) Error() string {
 	return apiError.Message
 }
 
-// IsMissing tells if error is about missing resource
+// HasErrorCode tells if err, or any error it wraps, is APIError with the given
+// error_code, like RESOURCE_DOES_NOT_EXIST or INVALID_STATE
+func HasErrorCode(err error, code string) bool {
+	var apiError APIError
+	return errors.As(err, &apiError) && apiError.ErrorCode == code
+}
+
+// IsMissing tells if error, or any error it wraps, is about missing resource
 func IsMissing(err error) bool {
-	if err == nil {
-		return false
-	}
-	e, ok := err.(APIError)
-	return ok && e.IsMissing()
+	var apiError APIError
+	return errors.As(err, &apiError) &&
+		(apiError.IsMissing() || HasErrorCode(err, "RESOURCE_DOES_NOT_EXIST"))
 }
 
 // IsMissing tells if it is missing resource
@@ -74,6 +84,19 @@ func (apiError APIError) IsMissing() bool {
 	return apiError.StatusCode == http.StatusNotFound
 }
 
+// IsAlreadyExists tells if error, or any error it wraps, is about conflict with existing resource
+func IsAlreadyExists(err error) bool {
+	var apiError APIError
+	return errors.As(err, &apiError) &&
+		(apiError.StatusCode == http.StatusConflict || HasErrorCode(err, "RESOURCE_ALREADY_EXISTS"))
+}
+
+// IsVersionConflict tells if the resource was modified since the version given in If-Match
+func IsVersionConflict(err error) bool {
+	var apiError APIError
+	return errors.As(err, &apiError) && apiError.StatusCode == http.StatusPreconditionFailed
+}
+
 // IsTooManyRequests shows rate exceeded limits
 func (apiError APIError) IsTooManyRequests() bool {
 	return apiError.StatusCode == http.StatusTooManyRequests
@@ -146,7 +169,8 @@ func (c *DatabricksClient) commonErrorClarity(resp *http.Response) *APIError {
 		return nil
 	}
 	isAccountsAPI := strings.HasPrefix(resp.Request.URL.Path, "/api/2.0/accounts")
-	isAccountsClient := c.isAccountsClient()
+	// accounts requests of workspace client are routed to AccountHost
+	isAccountsClient := c.isAccountsClient() || (c.accounts != nil && isAccountsAPI)
 	isTesting := strings.HasPrefix(resp.Request.URL.Host, "127.0.0.1")
 	if !isTesting && isAccountsClient && !isAccountsAPI {
 		return &APIError{
@@ -189,6 +213,7 @@ func (c *DatabricksClient) parseError(resp *http.Response) APIError {
 	log.Printf("[DEBUG] %s %v", resp.Status, c.redactedDump(body))
 	mwsError := c.commonErrorClarity(resp)
 	if mwsError != nil {
+		mwsError.RequestID = resp.Header.Get("X-Request-Id")
 		return *mwsError
 	}
 	// try to read in nicely formatted API error response
@@ -215,11 +240,102 @@ func (c *DatabricksClient) parseError(resp *http.Response) APIError {
 		ErrorCode:  errorBody.ErrorCode,
 		StatusCode: resp.StatusCode,
 		Resource:   resp.Request.URL.Path,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
+
+// lastAttempt is the outcome of the previous attempt of a retried request
+type lastAttempt struct {
+	status    int
+	requestID string
+	at        time.Time
+
+	// counters of all attempts of the request, that are reported in metrics
+	attempts     int
+	throttled    int
+	serverErrors int
+}
+
+// retryLogFields are structured fields of a retried request for log aggregation
+type retryLogFields struct {
+	Resource  string
+	Method    string
+	Path      string
+	Attempt   int
+	Status    int
+	Wait      time.Duration
+	RequestID string
+}
+
+func (f retryLogFields) String() string {
+	fields := []string{
+		fmt.Sprintf("resource=%s", f.Resource),
+		fmt.Sprintf("method=%s", f.Method),
+		fmt.Sprintf("path=%s", f.Path),
+		fmt.Sprintf("attempt=%d", f.Attempt),
+	}
+	if f.Status > 0 {
+		fields = append(fields, fmt.Sprintf("status=%d", f.Status))
+	}
+	fields = append(fields, fmt.Sprintf("wait=%s", f.Wait.Round(time.Millisecond)))
+	if f.RequestID != "" {
+		fields = append(fields, fmt.Sprintf("request_id=%s", f.RequestID))
+	}
+	return strings.Join(fields, " ")
+}
+
+// recordAttempt remembers outcome of the current attempt, so that the retry is logged with it
+func recordAttempt(ctx context.Context, resp *http.Response) {
+	last, ok := ctx.Value(retryState).(*lastAttempt)
+	if !ok {
+		return
+	}
+	last.at = time.Now()
+	last.status = 0
+	last.requestID = ""
+	last.attempts++
+	if resp != nil {
+		last.status = resp.StatusCode
+		last.requestID = resp.Header.Get("X-Request-Id")
+		if resp.StatusCode == 429 {
+			last.throttled++
+		}
+		if resp.StatusCode >= 500 {
+			last.serverErrors++
+		}
 	}
 }
 
+// logRetry is invoked by retryablehttp before every attempt
+func logRetry(_ retryablehttp.Logger, r *http.Request, retryNumber int) {
+	if retryNumber == 0 {
+		return
+	}
+	fields := retryLogFields{
+		Resource: ResourceName.GetOrUnknown(r.Context()),
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Attempt:  retryNumber + 1,
+	}
+	if last, ok := r.Context().Value(retryState).(*lastAttempt); ok {
+		fields.Status = last.status
+		fields.RequestID = last.requestID
+		fields.Wait = time.Since(last.at)
+	}
+	log.Printf("[DEBUG] Retrying request: %s", fields)
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
 // checkHTTPRetry inspects HTTP errors from the Databricks API for known transient errors on Workspace creation
 func (c *DatabricksClient) checkHTTPRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	recordAttempt(ctx, resp)
+	if isContextError(err) {
+		// cancelled or timed out requests are not retried and keep the original error
+		return false, err
+	}
 	if ue, ok := err.(*url.Error); ok {
 		apiError := APIError{ErrorCode: "IO_ERROR", Message: ue.Error()}
 		return apiError.IsRetriable(), apiError
@@ -236,6 +352,14 @@ func (c *DatabricksClient) checkHTTPRetry(ctx context.Context, resp *http.Respon
 			StatusCode: 429,
 		}
 	}
+	if resp.StatusCode >= 500 {
+		apiError := c.parseError(resp)
+		if err := c.recordServerError(apiError); err != nil {
+			return false, err
+		}
+		return apiError.IsRetriable(), apiError
+	}
+	c.recordHealthy()
 	if resp.StatusCode >= 400 {
 		apiError := c.parseError(resp)
 		return apiError.IsRetriable(), apiError
@@ -245,7 +369,7 @@ func (c *DatabricksClient) checkHTTPRetry(ctx context.Context, resp *http.Respon
 
 // Get on path
 func (c *DatabricksClient) Get(ctx context.Context, path string, request interface{}, response interface{}) error {
-	body, err := c.authenticatedQuery(ctx, http.MethodGet, path, request, c.api2)
+	body, err := c.cachedGet(ctx, path, request)
 	if err != nil {
 		return err
 	}
@@ -303,10 +427,18 @@ func (c *DatabricksClient) api2(r *http.Request) error {
 	if r.URL == nil {
 		return fmt.Errorf("no URL found in request")
 	}
+	host := c.Host
+	if c.isAccountsRequest(r) {
+		host = c.accounts.Host
+	}
 	r.URL.Path = fmt.Sprintf("/api/2.0%s", r.URL.Path)
 	r.Header.Set("Content-Type", "application/json")
+	if r.URL.Host != "" {
+		// request is explicitly targeted to another host
+		return nil
+	}
 
-	url, err := url.Parse(c.Host)
+	url, err := url.Parse(host)
 	if err != nil {
 		return err
 	}
@@ -320,10 +452,18 @@ func (c *DatabricksClient) api12(r *http.Request) error {
 	if r.URL == nil {
 		return fmt.Errorf("no URL found in request")
 	}
+	host := c.Host
+	if c.isAccountsRequest(r) {
+		host = c.accounts.Host
+	}
 	r.URL.Path = fmt.Sprintf("/api/1.2%s", r.URL.Path)
 	r.Header.Set("Content-Type", "application/json")
+	if r.URL.Host != "" {
+		// request is explicitly targeted to another host
+		return nil
+	}
 
-	url, err := url.Parse(c.Host)
+	url, err := url.Parse(host)
 	if err != nil {
 		return err
 	}
@@ -335,10 +475,29 @@ func (c *DatabricksClient) api12(r *http.Request) error {
 
 // Scim sets SCIM headers
 func (c *DatabricksClient) Scim(ctx context.Context, method, path string, request interface{}, response interface{}) error {
-	body, err := c.authenticatedQuery(ctx, method, path, request, c.api2, func(r *http.Request) error {
-		r.Header.Set("Content-Type", "application/scim+json")
+	return c.scim(ctx, method, path, request, response)
+}
+
+// ScimIfMatch sets SCIM headers and If-Match with the version of the resource, so that the
+// request fails with HTTP 412 when the resource was changed by someone else. Empty version
+// makes it the same as Scim.
+func (c *DatabricksClient) ScimIfMatch(ctx context.Context, method, path, version string,
+	request interface{}, response interface{}) error {
+	return c.scim(ctx, method, path, request, response, func(r *http.Request) error {
+		if version != "" {
+			r.Header.Set("If-Match", version)
+		}
 		return nil
 	})
+}
+
+func (c *DatabricksClient) scim(ctx context.Context, method, path string, request interface{},
+	response interface{}, visitors ...func(*http.Request) error) error {
+	visitors = append([]func(*http.Request) error{c.api2, func(r *http.Request) error {
+		r.Header.Set("Content-Type", "application/scim+json")
+		return nil
+	}}, visitors...)
+	body, err := c.authenticatedQuery(ctx, method, path, request, visitors...)
 	if err != nil {
 		return err
 	}
@@ -360,35 +519,144 @@ func (c *DatabricksClient) authenticatedQuery(ctx context.Context, method, reque
 	if err != nil {
 		return
 	}
-	visitors = append([]func(*http.Request) error{c.authVisitor}, visitors...)
+	visitors = append([]func(*http.Request) error{c.authorizeByHost}, visitors...)
 	return c.genericQuery(ctx, method, requestURL, data, visitors...)
 }
 
+// authorizeByHost applies authorizer from HostAuth for the request explicitly
+// targeted to another host and falls back to the default one. Default authorizer
+// runs before the host is set, because it might resolve the workspace host lazily.
+func (c *DatabricksClient) authorizeByHost(r *http.Request) error {
+	if c.isAccountsRequest(r) {
+		return c.authorizeAccountsRequest(r)
+	}
+	if r.URL.Host == "" {
+		return c.authVisitor(r)
+	}
+	for host, authorizer := range c.HostAuth {
+		if strings.Contains(host, "://") {
+			u, err := url.Parse(host)
+			if err != nil {
+				return fmt.Errorf("invalid host auth key %s: %w", host, err)
+			}
+			host = u.Host
+		}
+		if strings.EqualFold(host, r.URL.Host) {
+			return authorizer(r)
+		}
+	}
+	return c.authVisitor(r)
+}
+
+// Do performs authenticated, rate-limited and retried request on path and returns
+// raw HTTP response for callers, that need response headers. It's the responsibility
+// of the caller to read and close the response body.
+func (c *DatabricksClient) Do(ctx context.Context, method, path string, request interface{}) (*http.Response, error) {
+	err := c.Authenticate()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := c.rawQuery(ctx, method, path, request, c.authorizeByHost, c.api2)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s <- %s %s", resp.Status, method, path)
+	c.logResponse(method, path, start, resp, nil, nil)
+	return resp, nil
+}
+
+// sensitiveFields are never logged
+var sensitiveFields = map[string]bool{
+	"string_value":  true,
+	"token_value":   true,
+	"content":       true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"password":      true,
+	"client_secret": true,
+	"secret":        true,
+}
+
+// sensitiveHeaders are never logged, even with DebugHeaders
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Databricks-Azure-SP-Management-Token",
+	"X-Databricks-GCP-SA-Access-Token",
+}
+
+func (c *DatabricksClient) debugTruncateBytes() int {
+	if c.DebugTruncateBytes == 0 {
+		return DefaultTruncateBytes
+	}
+	return c.DebugTruncateBytes
+}
+
 func (c *DatabricksClient) recursiveMask(requestMap map[string]interface{}) interface{} {
 	for k, v := range requestMap {
-		if k == "string_value" {
-			requestMap[k] = "**REDACTED**"
-			continue
-		}
-		if k == "token_value" {
+		if sensitiveFields[k] {
 			requestMap[k] = "**REDACTED**"
 			continue
 		}
-		if k == "content" {
-			requestMap[k] = "**REDACTED**"
-			continue
+		requestMap[k] = c.maskValue(v)
+	}
+	return requestMap
+}
+
+func (c *DatabricksClient) maskValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return c.recursiveMask(x)
+	case []interface{}:
+		for i, item := range x {
+			x[i] = c.maskValue(item)
 		}
-		if m, ok := v.(map[string]interface{}); ok {
-			requestMap[k] = c.recursiveMask(m)
-			continue
+		return x
+	case string:
+		return onlyNBytes(x, c.debugTruncateBytes())
+	}
+	return v
+}
+
+// redactHeaders returns a copy of headers without credentials, including
+// the ones not known upfront, like X-Api-Token of proxies
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "**REDACTED**")
 		}
-		// todo: dapi...
-		// TODO: just redact any dapiXXX & "secret": "...."...
-		if s, ok := v.(string); ok {
-			requestMap[k] = onlyNBytes(s, c.DebugTruncateBytes)
+	}
+	for name := range redacted {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+			redacted.Set(name, "**REDACTED**")
 		}
 	}
-	return requestMap
+	return redacted
+}
+
+// redactedHeaders formats request headers for debug logs, if DebugHeaders is enabled
+func (c *DatabricksClient) redactedHeaders(header http.Header) string {
+	if !c.DebugHeaders {
+		return ""
+	}
+	redacted := redactHeaders(header)
+	keys := []string{}
+	for k := range redacted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	headers := ""
+	for _, k := range keys {
+		headers += fmt.Sprintf("\n * %s: %s", k, onlyNBytes(strings.Join(redacted[k], ""), c.debugTruncateBytes()))
+	}
+	if len(headers) > 0 {
+		headers += "\n"
+	}
+	return headers
 }
 
 func (c *DatabricksClient) redactedDump(body []byte) (res string) {
@@ -407,8 +675,8 @@ func (c *DatabricksClient) redactedDump(body []byte) (res string) {
 		return
 	}
 	maxBytes := 1024
-	if c.DebugTruncateBytes > maxBytes {
-		maxBytes = c.DebugTruncateBytes
+	if c.debugTruncateBytes() > maxBytes {
+		maxBytes = c.debugTruncateBytes()
 	}
 	return onlyNBytes(string(rePacked), maxBytes)
 }
@@ -422,23 +690,55 @@ func (c *DatabricksClient) userAgent(ctx context.Context) string {
 	if c.Provider != nil {
 		terraformVersion = c.Provider.TerraformVersion
 	}
-	return fmt.Sprintf("databricks-tf-provider/%s (+%s) terraform/%s",
+	userAgent := fmt.Sprintf("databricks-tf-provider/%s (+%s) terraform/%s",
 		Version(), resource, terraformVersion)
+	if c.UserAgentExtra != "" {
+		userAgent += " " + strings.TrimSpace(c.UserAgentExtra)
+	}
+	return userAgent
 }
 
 // todo: do is better name
 func (c *DatabricksClient) genericQuery(ctx context.Context, method, requestURL string, data interface{},
 	visitors ...func(*http.Request) error) (body []byte, err error) {
-	if c.httpClient == nil {
-		return nil, fmt.Errorf("DatabricksClient is not configured")
+	start := time.Now()
+	resp, err := c.rawQuery(ctx, method, requestURL, data, visitors...)
+	if err != nil {
+		return nil, err
 	}
-	if err = c.rateLimiter.Wait(ctx); err != nil {
+	defer func() {
+		if ferr := resp.Body.Close(); ferr != nil {
+			err = ferr
+		}
+	}()
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
 		return nil, err
 	}
+	log.Printf("[DEBUG] %s %v <- %s %s", resp.Status, c.redactedDump(body), method, requestURL)
+	c.logResponse(method, requestURL, start, resp, body, nil)
+	return body, nil
+}
+
+// rawQuery performs rate-limited and retried request and returns response with unread body
+func (c *DatabricksClient) rawQuery(ctx context.Context, method, requestURL string, data interface{},
+	visitors ...func(*http.Request) error) (*http.Response, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("DatabricksClient is not configured")
+	}
 	requestBody, err := makeRequestBody(method, &requestURL, data, true)
 	if err != nil {
 		return nil, err
 	}
+	callKey := fmt.Sprintf("%s %s", method, requestURL)
+	if err = c.recentlyFailed(callKey); err != nil {
+		return nil, err
+	}
+	if err = c.circuitOpen(); err != nil {
+		return nil, err
+	}
+	attempt := &lastAttempt{}
+	ctx = context.WithValue(ctx, retryState, attempt)
 	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
@@ -450,41 +750,123 @@ func (c *DatabricksClient) genericQuery(ctx context.Context, method, requestURL
 			return nil, err
 		}
 	}
-	headers := ""
-	if c.DebugHeaders {
-		for k, v := range request.Header {
-			headers += fmt.Sprintf("\n * %s: %s", k, onlyNBytes(strings.Join(v, ""), c.DebugTruncateBytes))
+	for _, requestVisitor := range c.RequestVisitors {
+		err = requestVisitor(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if withToken, ok := withIdempotencyToken(method, request.URL.Path, requestBody); ok {
+		requestBody = withToken
+		request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+		request.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(withToken)), nil
 		}
-		if len(headers) > 0 {
-			headers += "\n"
+		request.ContentLength = int64(len(requestBody))
+	}
+	if c.GzipRequests {
+		err = gzipRequestBody(request, requestBody)
+		if err != nil {
+			return nil, err
 		}
 	}
-	log.Printf("[DEBUG] %s %s %s%v", method, requestURL, headers, c.redactedDump(requestBody)) // lgtm[go/clear-text-logging]
+	log.Printf("[DEBUG] %s %s %s%v", method, requestURL,
+		c.redactedHeaders(request.Header), c.redactedDump(requestBody)) // lgtm[go/clear-text-logging]
+	c.logRequest(request, requestBody)
 
 	r, err := retryablehttp.FromRequest(request)
 	if err != nil {
 		return nil, err
 	}
+	start := time.Now()
 	resp, err := c.httpClient.Do(r)
+	apiMetrics.record(method, request.URL.Path, time.Since(start), attempt)
+	apiCallTracer().record(ctx, request, start, resp, attempt, err)
 	// retryablehttp library now returns only wrapped errors
 	var ae APIError
 	if errors.As(err, &ae) {
+		if ae.StatusCode >= 500 {
+			c.rememberFailure(callKey, ae)
+		}
+		c.logResponse(method, request.URL.String(), start, nil, nil, ae)
 		return nil, ae
 	}
 	if err != nil {
+		if !isContextError(err) {
+			c.rememberFailure(callKey, err)
+		}
+		c.logResponse(method, request.URL.String(), start, nil, nil, err)
 		return nil, err
 	}
-	defer func() {
-		if ferr := resp.Body.Close(); ferr != nil {
-			err = ferr
+	c.forgetFailure(callKey)
+	for _, responseVisitor := range c.ResponseVisitors {
+		err = responseVisitor(resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
 		}
-	}()
-	body, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
 	}
-	log.Printf("[DEBUG] %s %v <- %s %s", resp.Status, c.redactedDump(body), method, requestURL)
-	return body, nil
+	return resp, nil
+}
+
+// failedCall is the last failure of an identical call within the cooldown window
+type failedCall struct {
+	at  time.Time
+	err error
+}
+
+// recentlyFailed returns an error, if identical call has failed within FailedCallCooldownSeconds
+func (c *DatabricksClient) recentlyFailed(callKey string) error {
+	if c.FailedCallCooldownSeconds <= 0 {
+		return nil
+	}
+	c.failedCallsMutex.Lock()
+	defer c.failedCallsMutex.Unlock()
+	failed, ok := c.failedCalls[callKey]
+	if !ok {
+		return nil
+	}
+	cooldown := time.Duration(c.FailedCallCooldownSeconds) * time.Second
+	elapsed := time.Since(failed.at)
+	if elapsed >= cooldown {
+		delete(c.failedCalls, callKey)
+		return nil
+	}
+	log.Printf("[DEBUG] Suppressing %s, as it has failed %s ago", callKey, elapsed.Round(time.Millisecond))
+	recent := APIError{
+		ErrorCode: "RECENTLY_FAILED",
+		Message: fmt.Sprintf("%s has recently failed, not retrying for %s: %v",
+			callKey, (cooldown - elapsed).Round(time.Second), failed.err),
+	}
+	if ae, ok := failed.err.(APIError); ok {
+		recent.StatusCode = ae.StatusCode
+		recent.Resource = ae.Resource
+	}
+	return recent
+}
+
+func (c *DatabricksClient) rememberFailure(callKey string, err error) {
+	if c.FailedCallCooldownSeconds <= 0 {
+		return
+	}
+	c.failedCallsMutex.Lock()
+	defer c.failedCallsMutex.Unlock()
+	if c.failedCalls == nil {
+		c.failedCalls = map[string]failedCall{}
+	}
+	c.failedCalls[callKey] = failedCall{
+		at:  time.Now(),
+		err: err,
+	}
+}
+
+func (c *DatabricksClient) forgetFailure(callKey string) {
+	if c.FailedCallCooldownSeconds <= 0 {
+		return
+	}
+	c.failedCallsMutex.Lock()
+	defer c.failedCallsMutex.Unlock()
+	delete(c.failedCalls, callKey)
 }
 
 func makeRequestBody(method string, requestURL *string, data interface{}, marshalJSON bool) ([]byte, error) {
@@ -3,13 +3,18 @@ package common
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -313,6 +318,31 @@ func TestScim(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestScimIfMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/2.0/Groups/abc", req.URL.Path)
+		assert.Equal(t, "application/scim+json", req.Header.Get("Content-Type"))
+		if req.Header.Get("If-Match") != `W/"2"` {
+			rw.WriteHeader(412)
+			_, err := rw.Write([]byte(`{"detail": "version mismatch", "status": "412"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := rw.Write([]byte(`{}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, client.ScimIfMatch(ctx, "PUT", "/Groups/abc", `W/"2"`, map[string]string{}, nil))
+	err = client.ScimIfMatch(ctx, "PUT", "/Groups/abc", `W/"1"`, map[string]string{}, nil)
+	assert.True(t, IsVersionConflict(err), err)
+}
+
 func TestOldAPI(t *testing.T) {
 	ws, server := singleRequestServer(t, "GET", "/api/1.2/imaginary/endpoint", `{"a": "b"}`)
 	defer server.Close()
@@ -418,3 +448,611 @@ func TestClient_HandleErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestFailedCallCooldown(t *testing.T) {
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			calls[req.URL.Path]++
+			if req.URL.Path == "/api/2.0/healthy" {
+				_, err := rw.Write([]byte(`{}`))
+				assert.NoError(t, err)
+				return
+			}
+			rw.WriteHeader(500)
+			_, err := rw.Write([]byte(`{"error_code": "INTERNAL_ERROR", "message": "outage"}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	client := DatabricksClient{
+		Host:                      server.URL,
+		Token:                     "...",
+		FailedCallCooldownSeconds: 60,
+	}
+	err := client.Configure()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = client.Get(ctx, "/failing", nil, nil)
+	AssertErrorStartsWith(t, err, "outage")
+
+	err = client.Get(ctx, "/failing", nil, nil)
+	AssertErrorStartsWith(t, err, "GET /failing has recently failed, not retrying for 1m0s: outage")
+	assert.Equal(t, "RECENTLY_FAILED", err.(APIError).ErrorCode)
+	assert.Equal(t, 500, err.(APIError).StatusCode)
+	assert.Equal(t, 1, calls["/api/2.0/failing"])
+
+	err = client.Post(ctx, "/failing", nil, nil)
+	AssertErrorStartsWith(t, err, "outage")
+	assert.Equal(t, 2, calls["/api/2.0/failing"])
+
+	err = client.Get(ctx, "/other", nil, nil)
+	AssertErrorStartsWith(t, err, "outage")
+	assert.Equal(t, 1, calls["/api/2.0/other"])
+
+	for i := 0; i < 3; i++ {
+		err = client.Get(ctx, "/healthy", nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls["/api/2.0/healthy"])
+
+	// pretend that cooldown has passed
+	client.failedCalls["GET /failing"] = failedCall{
+		at:  time.Now().Add(-2 * time.Minute),
+		err: fmt.Errorf("outage"),
+	}
+	err = client.Get(ctx, "/failing", nil, nil)
+	AssertErrorStartsWith(t, err, "outage")
+	assert.Equal(t, 3, calls["/api/2.0/failing"])
+}
+
+func TestFailedCallCooldown_Disabled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			calls++
+			rw.WriteHeader(500)
+		}))
+	defer server.Close()
+	client := DatabricksClient{
+		Host:  server.URL,
+		Token: "...",
+	}
+	err := client.Configure()
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		err = client.Get(context.Background(), "/failing", nil, nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestRateLimitGatesRequests(t *testing.T) {
+	ws, server := singleRequestServer(t, "GET", "/api/2.0/imaginary/endpoint", `{}`)
+	defer server.Close()
+	ws.RateLimitPerSecond = 20
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	start := time.Now()
+	// first request is allowed immediately by the burst of one
+	for i := 0; i < 11; i++ {
+		err = ws.Get(context.Background(), "/imaginary/endpoint", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(450*time.Millisecond))
+}
+
+func TestRateLimitDefault(t *testing.T) {
+	ws := DatabricksClient{}
+	err := ws.Configure()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRateLimitPerSecond, ws.RateLimitPerSecond)
+}
+
+func TestRateLimitRespectsCancelledContext(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			calls++
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:               server.URL,
+		Token:              "..",
+		RateLimitPerSecond: 1,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	err = ws.Get(context.Background(), "/imaginary/endpoint", nil, nil)
+	require.NoError(t, err)
+
+	// the next token is available only in a second, so the call has to abort
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ws.Get(ctx, "/imaginary/endpoint", nil, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+	assert.Equal(t, 1, calls)
+}
+
+func TestApiFamily(t *testing.T) {
+	for path, family := range map[string]string{
+		"/api/2.0/clusters/list":             "clusters",
+		"/api/2.0/preview/scim/v2/Users/abc": "scim",
+		"/api/1.2/commands/execute":          "commands",
+		"/api/2.0/DBFS/put":                  "dbfs",
+		"/api/2.0":                           "",
+		"/oidc/v1/token":                     "",
+	} {
+		assert.Equal(t, family, apiFamily(path), path)
+	}
+}
+
+func TestRateLimitsPerFamily(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:               server.URL,
+		Token:              "..",
+		RateLimitPerSecond: 1,
+		RateLimits: map[string]int{
+			"SCIM": 1000,
+		},
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = ws.Get(context.Background(), "/clusters/list", nil, nil)
+	require.NoError(t, err)
+	// scim requests don't have to wait for the shared bucket
+	for i := 0; i < 10; i++ {
+		err = ws.Get(context.Background(), "/preview/scim/v2/Groups", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+
+	// while the next shared token is available only in a second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ws.Get(ctx, "/clusters/list", nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestRateLimitsMustBePositive(t *testing.T) {
+	ws := DatabricksClient{
+		RateLimits: map[string]int{
+			"dbfs": 0,
+		},
+	}
+	err := ws.Configure()
+	assert.EqualError(t, err, "rate limit for dbfs must be positive")
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:                  server.URL,
+		Token:                 "..",
+		RateLimitPerSecond:    1000,
+		MaxConcurrentRequests: 2,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ws.Get(context.Background(), "/clusters/list", nil, nil))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, maxInFlight)
+
+	// waiting for a slot respects the context
+	ws.httpClient.HTTPClient.Transport.(*rateLimitedTransport).inFlight <- struct{}{}
+	ws.httpClient.HTTPClient.Transport.(*rateLimitedTransport).inFlight <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ws.Get(ctx, "/clusters/list", nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestHTTPTimeoutExcludesWaitingForLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:                  server.URL,
+		Token:                 "..",
+		HTTPTimeoutSeconds:    1,
+		RateLimitPerSecond:    2,
+		MaxConcurrentRequests: 1,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	// the last of saturated limiter requests waits for longer than the timeout
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ws.Get(context.Background(), "/clusters/list", nil, nil))
+		}()
+	}
+	wg.Wait()
+	assert.Greater(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestHTTPTimeoutAppliesToRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			time.Sleep(1500 * time.Millisecond)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:               server.URL,
+		Token:              "..",
+		HTTPTimeoutSeconds: 1,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+	err = ws.Get(context.Background(), "/clusters/list", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request timed out after 1s")
+}
+
+func TestMaxConcurrentRequestsMustNotBeNegative(t *testing.T) {
+	ws := DatabricksClient{
+		MaxConcurrentRequests: -1,
+	}
+	err := ws.Configure()
+	assert.EqualError(t, err, "max concurrent requests must not be negative")
+}
+
+func TestDoReturnsRawResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/api/2.0/imaginary/endpoint?page=2", req.RequestURI)
+			assert.Equal(t, "Bearer ..", req.Header.Get("Authorization"))
+			rw.Header().Set("X-Next-Page", "3")
+			_, err := rw.Write([]byte(`{"a": "b"}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	resp, err := ws.Do(context.Background(), "GET", "/imaginary/endpoint", map[string]int{
+		"page": 2,
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "3", resp.Header.Get("X-Next-Page"))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": "b"}`, string(body))
+}
+
+func TestDo_Error(t *testing.T) {
+	defer CleanupEnvironment()()
+	ws := DatabricksClient{}
+	_, err := ws.Do(context.Background(), "GET", "/imaginary/endpoint", nil)
+	AssertErrorStartsWith(t, err, "authentication is not configured")
+}
+
+func TestRequestAndResponseVisitors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "Bearer ..", req.Header.Get("Authorization"))
+			rw.Header().Set("X-Request-Id", req.Header.Get("X-Correlation-Id"))
+			_, err := rw.Write([]byte(`{"a": "b"}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	requestIDs := []string{}
+	ws := DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+		RequestVisitors: []func(*http.Request) error{
+			func(r *http.Request) error {
+				// visitors see authenticated requests
+				assert.Equal(t, "Bearer ..", r.Header.Get("Authorization"))
+				r.Header.Set("X-Correlation-Id", "abc")
+				return nil
+			},
+		},
+		ResponseVisitors: []func(*http.Response) error{
+			func(r *http.Response) error {
+				requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+				return nil
+			},
+		},
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	var response map[string]string
+	err = ws.Get(context.Background(), "/imaginary/endpoint", nil, &response)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, response)
+	resp, err := ws.Do(context.Background(), "GET", "/imaginary/endpoint", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"abc", "abc"}, requestIDs)
+}
+
+func TestRequestAndResponseVisitors_Errors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			calls++
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+
+	ws.RequestVisitors = []func(*http.Request) error{
+		func(r *http.Request) error {
+			return fmt.Errorf("nope")
+		},
+	}
+	err = ws.Get(context.Background(), "/imaginary/endpoint", nil, nil)
+	assert.EqualError(t, err, "nope")
+	assert.Equal(t, 0, calls)
+
+	ws.RequestVisitors = nil
+	ws.ResponseVisitors = []func(*http.Response) error{
+		func(r *http.Response) error {
+			return fmt.Errorf("unexpected response")
+		},
+	}
+	err = ws.Get(context.Background(), "/imaginary/endpoint", nil, nil)
+	assert.EqualError(t, err, "unexpected response")
+	assert.Equal(t, 1, calls)
+}
+
+func TestHostAuthSelectsAuthorizerByTargetHost(t *testing.T) {
+	authorizations := map[string]string{}
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/api/2.0/imaginary/endpoint", req.RequestURI)
+			authorizations[name] = req.Header.Get("Authorization")
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		})
+	}
+	workspace := httptest.NewServer(handler("workspace"))
+	defer workspace.Close()
+	accounts := httptest.NewServer(handler("accounts"))
+	defer accounts.Close()
+	accountsURL, err := url.Parse(accounts.URL)
+	require.NoError(t, err)
+
+	ws := DatabricksClient{
+		Host:  workspace.URL,
+		Token: "default",
+		HostAuth: map[string]func(*http.Request) error{
+			accountsURL.Host: func(r *http.Request) error {
+				r.Header.Set("Authorization", "Bearer accounts")
+				return nil
+			},
+		},
+	}
+	err = ws.Configure()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = ws.Get(ctx, "/imaginary/endpoint", nil, nil)
+	require.NoError(t, err)
+	err = ws.Get(ctx, accounts.URL+"/imaginary/endpoint", nil, nil)
+	require.NoError(t, err)
+	resp, err := ws.Do(ctx, "GET", accounts.URL+"/imaginary/endpoint", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, map[string]string{
+		"workspace": "Bearer default",
+		"accounts":  "Bearer accounts",
+	}, authorizations)
+}
+
+func TestHostAuthMatchesURLKeys(t *testing.T) {
+	ws := DatabricksClient{
+		HostAuth: map[string]func(*http.Request) error{
+			"https://Accounts.Cloud.Databricks.com": func(r *http.Request) error {
+				r.Header.Set("Authorization", "Bearer accounts")
+				return nil
+			},
+		},
+		authVisitor: func(r *http.Request) error {
+			r.Header.Set("Authorization", "Bearer default")
+			return nil
+		},
+	}
+	for host, expected := range map[string]string{
+		"accounts.cloud.databricks.com": "Bearer accounts",
+		"abc.cloud.databricks.com":      "Bearer default",
+	} {
+		r := httptest.NewRequest("GET", "https://"+host+"/api/2.0/a", nil)
+		err := ws.authorizeByHost(r)
+		require.NoError(t, err)
+		assert.Equal(t, expected, r.Header.Get("Authorization"), host)
+	}
+}
+
+func TestRequestLoggingRedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			_, err := rw.Write([]byte(`{
+				"token_value": "dapi-response-secret",
+				"items": [{"password": "nested-response-secret"}],
+				"comment": "` + strings.Repeat("x", 200) + `"
+			}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:         server.URL,
+		Token:        "dapi-header-secret",
+		DebugHeaders: true,
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+	// field-level truncation falls back to default
+	ws.DebugTruncateBytes = 0
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	err = ws.Post(context.Background(), "/imaginary/endpoint", map[string]interface{}{
+		"password": "request-secret",
+		"configs": []interface{}{
+			map[string]interface{}{
+				"client_secret": "nested-request-secret",
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	logs := buf.String()
+	for _, secret := range []string{
+		"dapi-header-secret",
+		"request-secret",
+		"nested-request-secret",
+		"dapi-response-secret",
+		"nested-response-secret",
+	} {
+		assert.NotContains(t, logs, secret)
+	}
+	assert.Contains(t, logs, "* Authorization: **REDACTED**")
+	assert.Contains(t, logs, "* User-Agent: databricks-tf-provider/")
+	assert.Contains(t, logs, strings.Repeat("x", DefaultTruncateBytes)+"... (104 more bytes)")
+}
+
+func TestRequestLoggingWithoutHeaders(t *testing.T) {
+	ws := DatabricksClient{}
+	assert.Equal(t, "", ws.redactedHeaders(http.Header{
+		"Authorization": []string{"Bearer abc"},
+	}))
+}
+
+func TestRetryLogHasStructuredFields(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			attempts++
+			if attempts == 1 {
+				rw.Header().Set("X-Request-Id", "req-123")
+				rw.WriteHeader(429)
+				return
+			}
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+	ws.httpClient.RetryWaitMin = 10 * time.Millisecond
+	ws.httpClient.RetryWaitMax = 10 * time.Millisecond
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := context.WithValue(context.Background(), ResourceName, "cluster")
+	err = ws.Get(ctx, "/clusters/get", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Regexp(t, `\[DEBUG\] Retrying request: resource=cluster method=GET `+
+		`path=/api/2.0/clusters/get attempt=2 status=429 wait=\d+ms request_id=req-123`, buf.String())
+}
+
+func TestRetryLogFields(t *testing.T) {
+	assert.Equal(t, "resource=unknown method=POST path=/a attempt=3 wait=1s", retryLogFields{
+		Resource: "unknown",
+		Method:   "POST",
+		Path:     "/a",
+		Attempt:  3,
+		Wait:     time.Second,
+	}.String())
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	assert.True(t, IsAlreadyExists(APIError{StatusCode: 409}))
+	assert.True(t, IsAlreadyExists(APIError{StatusCode: 400, ErrorCode: "RESOURCE_ALREADY_EXISTS"}))
+	assert.False(t, IsAlreadyExists(APIError{StatusCode: 400}))
+	assert.False(t, IsAlreadyExists(fmt.Errorf("nope")))
+	assert.False(t, IsAlreadyExists(nil))
+}
+
+func TestIsMissingAndAlreadyExists_Wrapped(t *testing.T) {
+	missing := fmt.Errorf("cannot read job: %w", APIError{StatusCode: 404})
+	assert.True(t, IsMissing(missing))
+	assert.True(t, IsMissing(APIError{StatusCode: 400, ErrorCode: "RESOURCE_DOES_NOT_EXIST"}))
+	assert.False(t, IsMissing(fmt.Errorf("nope")))
+	assert.False(t, IsMissing(nil))
+
+	exists := fmt.Errorf("cannot create: %w", APIError{StatusCode: 400, ErrorCode: "RESOURCE_ALREADY_EXISTS"})
+	assert.True(t, IsAlreadyExists(exists))
+	assert.True(t, IsAlreadyExists(fmt.Errorf("wrapped: %w", APIError{StatusCode: 409})))
+	assert.False(t, IsAlreadyExists(missing))
+	assert.False(t, IsAlreadyExists(nil))
+
+	assert.True(t, IsVersionConflict(fmt.Errorf("wrapped: %w", APIError{StatusCode: 412})))
+}
+
+func TestIsVersionConflict(t *testing.T) {
+	assert.True(t, IsVersionConflict(APIError{StatusCode: 412}))
+	// name collisions are not version conflicts
+	assert.False(t, IsVersionConflict(APIError{StatusCode: 409}))
+	assert.False(t, IsVersionConflict(APIError{StatusCode: 400}))
+	assert.False(t, IsVersionConflict(fmt.Errorf("nope")))
+	assert.False(t, IsVersionConflict(nil))
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// idempotentCreates are endpoints, that accept idempotency_token. Retries of such requests,
+// after timeouts or HTTP 5xx, return the object created by the first attempt instead of
+// creating a duplicate cluster or job run.
+var idempotentCreates = map[string]bool{
+	"/api/2.0/clusters/create":  true,
+	"/api/2.0/jobs/runs/submit": true,
+	"/api/2.0/jobs/run-now":     true,
+	"/api/2.1/jobs/runs/submit": true,
+	"/api/2.1/jobs/run-now":     true,
+}
+
+// withIdempotencyToken adds random idempotency_token to JSON body of create requests, unless
+// the caller has set it. The same body is sent with every retry of the request.
+func withIdempotencyToken(method, path string, body []byte) ([]byte, bool) {
+	if method != "POST" || !idempotentCreates[strings.TrimSuffix(path, "/")] {
+		return body, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// large numbers, like cluster policy IDs, are kept as is
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if decoder.Decode(&fields) != nil || fields == nil {
+		return body, false
+	}
+	if _, ok := fields["idempotency_token"]; ok {
+		return body, false
+	}
+	fields["idempotency_token"] = randomHex(16)
+	withToken, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return body, false
+	}
+	log.Printf("[DEBUG] Using idempotency token %s for %s", fields["idempotency_token"], path)
+	return withToken, true
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetriedCreateKeepsIdempotencyToken(t *testing.T) {
+	tokens := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, "x", body["cluster_name"])
+		tokens = append(tokens, body["idempotency_token"].(string))
+		if len(tokens) == 1 {
+			// cluster is created, but response is lost
+			rw.WriteHeader(503)
+			_, err := rw.Write([]byte(`{"error_code": "TEMPORARILY_UNAVAILABLE", "message": "i/o timeout"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := rw.Write([]byte(`{"cluster_id": "abc"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	})
+	require.NoError(t, err)
+	client.httpClient.RetryWaitMin = 10 * time.Millisecond
+	client.httpClient.RetryWaitMax = 10 * time.Millisecond
+
+	var resp map[string]string
+	err = client.Post(context.Background(), "/clusters/create", map[string]string{
+		"cluster_name": "x",
+	}, &resp)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp["cluster_id"])
+	require.Len(t, tokens, 2)
+	assert.Len(t, tokens[0], 32)
+	assert.Equal(t, tokens[0], tokens[1])
+}
+
+func TestWithIdempotencyToken(t *testing.T) {
+	for _, c := range []struct {
+		method, path, body string
+	}{
+		{"POST", "/api/2.0/clusters/create", `{"idempotency_token": "mine"}`},
+		{"POST", "/api/2.0/clusters/edit", `{"cluster_id": "abc"}`},
+		{"GET", "/api/2.0/clusters/create", ``},
+		{"POST", "/api/2.0/clusters/create", `[]`},
+	} {
+		body, ok := withIdempotencyToken(c.method, c.path, []byte(c.body))
+		assert.False(t, ok, c.path)
+		assert.Equal(t, c.body, string(body))
+	}
+
+	withToken, ok := withIdempotencyToken("POST", "/api/2.0/jobs/run-now", []byte(`{"num": 12345678901234567890}`))
+	assert.True(t, ok)
+	assert.Contains(t, string(withToken), `"num": 12345678901234567890`)
+	assert.Contains(t, string(withToken), `"idempotency_token": "`)
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// endpointMetrics are collected for all calls of the same method and path
+type endpointMetrics struct {
+	Method       string
+	Path         string
+	Calls        int
+	Retries      int
+	Throttled    int
+	ServerErrors int
+	latencies    []time.Duration
+}
+
+// P95 is the latency of a call, including retries, that 95% of calls don't exceed
+func (m *endpointMetrics) P95() time.Duration {
+	if len(m.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, m.latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+}
+
+func (m *endpointMetrics) String() string {
+	return fmt.Sprintf("method=%s path=%s calls=%d retries=%d throttled=%d server_errors=%d p95=%s",
+		m.Method, m.Path, m.Calls, m.Retries, m.Throttled, m.ServerErrors,
+		m.P95().Round(time.Millisecond))
+}
+
+// callMetrics aggregates calls of all clients within the provider process
+type callMetrics struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointMetrics
+}
+
+var apiMetrics = &callMetrics{endpoints: map[string]*endpointMetrics{}}
+
+// metricsPath replaces identifiers in the path, so that calls for different
+// objects, like /api/2.0/preview/scim/v2/Users/123, are counted together
+func metricsPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if looksLikeID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func looksLikeID(segment string) bool {
+	if len(segment) < 6 || !strings.ContainsAny(segment, "0123456789") {
+		return false
+	}
+	for _, r := range segment {
+		isHex := (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+		if !isHex && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func (cm *callMetrics) record(method, path string, latency time.Duration, attempt *lastAttempt) {
+	path = metricsPath(path)
+	key := method + " " + path
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	m, ok := cm.endpoints[key]
+	if !ok {
+		m = &endpointMetrics{Method: method, Path: path}
+		cm.endpoints[key] = m
+	}
+	m.Calls++
+	if attempt.attempts > 1 {
+		m.Retries += attempt.attempts - 1
+	}
+	m.Throttled += attempt.throttled
+	m.ServerErrors += attempt.serverErrors
+	m.latencies = append(m.latencies, latency)
+}
+
+// snapshot returns copy of metrics, sorted by the most called endpoints
+func (cm *callMetrics) snapshot() []endpointMetrics {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	result := []endpointMetrics{}
+	for _, m := range cm.endpoints {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return result[i].Method+result[i].Path < result[j].Method+result[j].Path
+	})
+	return result
+}
+
+// LogAPIMetrics writes per-endpoint call counts, retries and latencies to debug log,
+// so that slow plans could be diagnosed. It is called once the provider process stops.
+func LogAPIMetrics() {
+	endpoints := apiMetrics.snapshot()
+	if len(endpoints) == 0 {
+		return
+	}
+	total := 0
+	for _, m := range endpoints {
+		total += m.Calls
+	}
+	log.Printf("[DEBUG] API metrics for %d calls:", total)
+	for _, m := range endpoints {
+		log.Printf("[DEBUG] API metrics: %s", &m)
+	}
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsPath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/api/2.0/clusters/get":                                       "/api/2.0/clusters/get",
+		"/api/2.0/preview/scim/v2/Users/1234567":                      "/api/2.0/preview/scim/v2/Users/{id}",
+		"/api/2.0/accounts/c8e0b0e2-3ff1-4b7f-9d1e-1d2c3c4d5e6f/logs": "/api/2.0/accounts/{id}/logs",
+		"/api/2.0/workspace/decade":                                   "/api/2.0/workspace/decade",
+	} {
+		assert.Equal(t, expected, metricsPath(path), path)
+	}
+}
+
+func TestEndpointMetricsP95(t *testing.T) {
+	m := endpointMetrics{}
+	assert.Equal(t, time.Duration(0), m.P95())
+	for i := 20; i > 0; i-- {
+		m.latencies = append(m.latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 19*time.Millisecond, m.P95())
+}
+
+func TestAPIMetrics(t *testing.T) {
+	defer func(prev *callMetrics) {
+		apiMetrics = prev
+	}(apiMetrics)
+	apiMetrics = &callMetrics{endpoints: map[string]*endpointMetrics{}}
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			attempts++
+			switch attempts {
+			case 1:
+				rw.WriteHeader(429)
+				return
+			case 2:
+				rw.WriteHeader(503)
+				_, err := rw.Write([]byte(`{"error_code": "INTERNAL_ERROR", "message": "ClusterNotReadyException"}`))
+				assert.NoError(t, err)
+				return
+			}
+			_, err := rw.Write([]byte(`{}`))
+			assert.NoError(t, err)
+		}))
+	defer server.Close()
+	ws := DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	}
+	err := ws.Configure()
+	require.NoError(t, err)
+	ws.httpClient.RetryWaitMin = 10 * time.Millisecond
+	ws.httpClient.RetryWaitMax = 10 * time.Millisecond
+
+	ctx := context.Background()
+	err = ws.Get(ctx, "/preview/scim/v2/Users/1234567", nil, nil)
+	require.NoError(t, err)
+	err = ws.Get(ctx, "/preview/scim/v2/Users/7654321", nil, nil)
+	require.NoError(t, err)
+	err = ws.Get(ctx, "/clusters/list", nil, nil)
+	require.NoError(t, err)
+
+	endpoints := apiMetrics.snapshot()
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "/api/2.0/preview/scim/v2/Users/{id}", endpoints[0].Path)
+	assert.Equal(t, 2, endpoints[0].Calls)
+	assert.Equal(t, 2, endpoints[0].Retries)
+	assert.Equal(t, 1, endpoints[0].Throttled)
+	assert.Equal(t, 1, endpoints[0].ServerErrors)
+	assert.Equal(t, "/api/2.0/clusters/list", endpoints[1].Path)
+	assert.Equal(t, 1, endpoints[1].Calls)
+	assert.Equal(t, 0, endpoints[1].Retries)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	LogAPIMetrics()
+	logs := buf.String()
+	assert.Contains(t, logs, "[DEBUG] API metrics for 3 calls:")
+	assert.Contains(t, logs, "[DEBUG] API metrics: method=GET path=/api/2.0/preview/scim/v2/Users/{id} "+
+		"calls=2 retries=2 throttled=1 server_errors=1 p95=")
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauthScopes are requested for OAuth access tokens of service principals
+var oauthScopes = []string{"all-apis"}
+
+func (c *DatabricksClient) oauthTokenURL() (string, error) {
+	host := strings.TrimSuffix(c.Host, "/")
+	if !c.isAccountsClient() {
+		return fmt.Sprintf("%s/oidc/v1/token", host), nil
+	}
+	if c.AccountID == "" {
+		return "", fmt.Errorf("account_id is required for OAuth on accounts console")
+	}
+	return fmt.Sprintf("%s/oidc/accounts/%s/v1/token", host, c.AccountID), nil
+}
+
+// configureWithOAuthM2M uses OAuth client credentials flow for service principals,
+// so that they don't have to mint personal access tokens
+func (c *DatabricksClient) configureWithOAuthM2M() (func(r *http.Request) error, error) {
+	if c.ClientID == "" || c.ClientSecret == "" {
+		return nil, nil
+	}
+	if c.Host == "" {
+		return nil, fmt.Errorf("host is empty, but is required by oauth-m2m")
+	}
+	c.fixHost()
+	tokenURL, err := c.oauthTokenURL()
+	if err != nil {
+		return nil, err
+	}
+	ctx := c.InitContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.httpClient != nil {
+		// token requests are rate-limited, retried and proxied like any other
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient.StandardClient())
+	}
+	ts := (&clientcredentials.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       oauthScopes,
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}).TokenSource(ctx)
+	// fail early on invalid credentials
+	_, err = ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get OAuth token for %s: %w", c.ClientID, err)
+	}
+	log.Printf("[INFO] Using OAuth client credentials of %s", c.ClientID)
+	return func(r *http.Request) error {
+		// token source renews access token before it expires
+		token, err := ts.Token()
+		if err != nil {
+			return fmt.Errorf("cannot get OAuth token for %s: %w", c.ClientID, err)
+		}
+		token.SetAuthHeader(r)
+		return nil
+	}, nil
+}
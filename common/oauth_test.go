@@ -0,0 +1,131 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oauthServer(t *testing.T, tokenPath string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case tokenPath:
+			clientID, clientSecret, ok := req.BasicAuth()
+			assert.True(t, ok)
+			if clientSecret != "b" {
+				rw.WriteHeader(401)
+				_, err := rw.Write([]byte(`{"error_code": "UNAUTHENTICATED", "message": "invalid client"}`))
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, "a", clientID)
+			assert.NoError(t, req.ParseForm())
+			assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+			assert.Equal(t, "all-apis", req.PostForm.Get("scope"))
+			_, err := rw.Write([]byte(`{"access_token": "xyz", "token_type": "Bearer", "expires_in": 3600}`))
+			assert.NoError(t, err)
+		case "/api/2.0/clusters/list-zones":
+			assert.Equal(t, "Bearer xyz", req.Header.Get("Authorization"))
+			_, err := rw.Write([]byte(`{"zones": ["a"]}`))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "Received unexpected call: "+req.RequestURI)
+		}
+	}))
+}
+
+func TestOAuthM2M(t *testing.T) {
+	server := oauthServer(t, "/oidc/v1/token")
+	defer server.Close()
+	client := &DatabricksClient{
+		Host:         server.URL,
+		ClientID:     "a",
+		ClientSecret: "b",
+	}
+	_, err := configureAndAuthenticate(client)
+	require.NoError(t, err)
+
+	var zones struct {
+		Zones []string `json:"zones"`
+	}
+	err = client.Get(context.Background(), "/clusters/list-zones", nil, &zones)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, zones.Zones)
+}
+
+func TestOAuthM2M_ExpiredTokenIsRenewed(t *testing.T) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/oidc/v1/token":
+			issued++
+			// expires within the renewal window of token source right away
+			_, err := rw.Write([]byte(fmt.Sprintf(`{"access_token": "xyz-%d",
+				"token_type": "Bearer", "expires_in": 5}`, issued)))
+			assert.NoError(t, err)
+		case "/api/2.0/clusters/list-zones":
+			assert.Equal(t, fmt.Sprintf("Bearer xyz-%d", issued), req.Header.Get("Authorization"))
+			_, err := rw.Write([]byte(`{"zones": ["a"]}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+	client := &DatabricksClient{
+		Host:         server.URL,
+		ClientID:     "a",
+		ClientSecret: "b",
+	}
+	_, err := configureAndAuthenticate(client)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		err = client.Get(context.Background(), "/clusters/list-zones", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, issued)
+}
+
+func TestOAuthM2M_InvalidSecret(t *testing.T) {
+	server := oauthServer(t, "/oidc/v1/token")
+	defer server.Close()
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:         server.URL,
+		ClientID:     "a",
+		ClientSecret: "c",
+	})
+	AssertErrorStartsWith(t, err, "cannot get OAuth token for a")
+}
+
+func TestOAuthM2M_NoHost(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		ClientID:     "a",
+		ClientSecret: "b",
+	})
+	AssertErrorStartsWith(t, err, "host is empty, but is required by oauth-m2m")
+}
+
+func TestOAuthTokenURL(t *testing.T) {
+	tokenURL, err := (&DatabricksClient{
+		Host: "https://abc.cloud.databricks.com/",
+	}).oauthTokenURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://abc.cloud.databricks.com/oidc/v1/token", tokenURL)
+
+	tokenURL, err = (&DatabricksClient{
+		Host:      "https://accounts.cloud.databricks.com",
+		AccountID: "xyz",
+	}).oauthTokenURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://accounts.cloud.databricks.com/oidc/accounts/xyz/v1/token", tokenURL)
+
+	_, err = (&DatabricksClient{
+		Host: "https://accounts.cloud.databricks.com",
+	}).oauthTokenURL()
+	AssertErrorStartsWith(t, err, "account_id is required for OAuth on accounts console")
+}
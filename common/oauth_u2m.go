@@ -0,0 +1,273 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/oauth2"
+)
+
+// AuthTypeOAuthU2M logs in users with browser and caches their refresh tokens
+const AuthTypeOAuthU2M = "oauth-u2m"
+
+const (
+	// u2mClientID is the public OAuth application of Databricks CLI
+	u2mClientID = "databricks-cli"
+	// u2mLoginTimeout is the time user has to complete the login in browser
+	u2mLoginTimeout = 5 * time.Minute
+)
+
+var (
+	// u2mRedirectAddr is where browser returns with authorization code
+	u2mRedirectAddr = "localhost:8020"
+	// u2mTokenCache keeps refresh tokens of users between runs
+	u2mTokenCache = "~/.databricks/token-cache.json"
+	// openBrowser is replaced in tests
+	openBrowser = openURLInBrowser
+	// u2mPrompt shows the login URL, when browser cannot be opened. Unlike logs,
+	// it's visible without TF_LOG.
+	u2mPrompt         io.Writer = os.Stderr
+	u2mTokenCacheLock sync.Mutex
+)
+
+func openURLInBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	}
+	return exec.Command("xdg-open", url).Start()
+}
+
+// randomURLSafe returns base64-encoded random bytes for PKCE verifier and state
+func randomURLSafe(size int) (string, error) {
+	raw := make([]byte, size)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func readTokenCache() (map[string]*oauth2.Token, string, error) {
+	path, err := homedir.Expand(u2mTokenCache)
+	if err != nil {
+		return nil, "", err
+	}
+	tokens := map[string]*oauth2.Token{}
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tokens, path, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	err = json.Unmarshal(raw, &tokens)
+	if err != nil {
+		return nil, "", fmt.Errorf("token cache %s is corrupt: %w", path, err)
+	}
+	return tokens, path, nil
+}
+
+func loadCachedToken(key string) *oauth2.Token {
+	u2mTokenCacheLock.Lock()
+	defer u2mTokenCacheLock.Unlock()
+	tokens, _, err := readTokenCache()
+	if err != nil {
+		log.Printf("[WARN] %s", err)
+		return nil
+	}
+	return tokens[key]
+}
+
+func storeCachedToken(key string, token *oauth2.Token) error {
+	u2mTokenCacheLock.Lock()
+	defer u2mTokenCacheLock.Unlock()
+	tokens, path, err := readTokenCache()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	raw, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, raw, 0600)
+}
+
+// cachingTokenSource stores every refreshed token, so that the next run doesn't need a login
+type cachingTokenSource struct {
+	key  string
+	base oauth2.TokenSource
+	mu   sync.Mutex
+	last string
+}
+
+func (ts *cachingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := ts.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if token.AccessToken != ts.last {
+		ts.last = token.AccessToken
+		if err = storeCachedToken(ts.key, token); err != nil {
+			log.Printf("[WARN] Cannot cache OAuth token: %s", err)
+		}
+	}
+	return token, nil
+}
+
+// loginInBrowser performs authorization code flow with PKCE and waits for redirect to localhost
+func loginInBrowser(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	verifier, err := randomURLSafe(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomURLSafe(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	listener, err := net.Listen("tcp", u2mRedirectAddr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for OAuth redirect on %s: %w", u2mRedirectAddr, err)
+	}
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		if q.Get("state") != state {
+			http.Error(rw, "Invalid OAuth state", http.StatusBadRequest)
+			return
+		}
+		if e := q.Get("error"); e != "" {
+			http.Error(rw, "Login failed. You can close this window.", http.StatusBadRequest)
+			select {
+			case failures <- fmt.Errorf("%s: %s", e, q.Get("error_description")):
+			default:
+			}
+			return
+		}
+		_, _ = rw.Write([]byte("Login succeeded. You can close this window and return to Terraform."))
+		select {
+		case codes <- q.Get("code"):
+		default:
+			// browser might repeat the redirect
+		}
+	})}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+	authURL := config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"))
+	log.Printf("[INFO] Please log in on %s", authURL)
+	if err = openBrowser(authURL); err != nil {
+		log.Printf("[WARN] Cannot open browser: %s. Please open the URL manually.", err)
+		fmt.Fprintf(u2mPrompt, "Cannot open browser: %s\nPlease open this URL to log in to Databricks:\n%s\n",
+			err, authURL)
+	}
+	ctx, cancel := context.WithTimeout(ctx, u2mLoginTimeout)
+	defer cancel()
+	select {
+	case code := <-codes:
+		return config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	case err = <-failures:
+		return nil, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login was not completed within %s", u2mLoginTimeout)
+	}
+}
+
+// configureWithOAuthU2M uses browser-based login of users instead of personal access tokens
+func (c *DatabricksClient) configureWithOAuthU2M() (func(r *http.Request) error, error) {
+	if c.AuthType != AuthTypeOAuthU2M {
+		return nil, nil
+	}
+	if c.Host == "" {
+		return nil, fmt.Errorf("host is empty, but is required by %s", AuthTypeOAuthU2M)
+	}
+	c.fixHost()
+	tokenURL, err := c.oauthTokenURL()
+	if err != nil {
+		return nil, err
+	}
+	ctx := c.InitContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient.StandardClient())
+	}
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = u2mClientID
+	}
+	config := &oauth2.Config{
+		ClientID: clientID,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   strings.TrimSuffix(tokenURL, "/token") + "/authorize",
+			TokenURL:  tokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: fmt.Sprintf("http://%s", u2mRedirectAddr),
+		Scopes:      append([]string{"offline_access"}, oauthScopes...),
+	}
+	key := fmt.Sprintf("%s %s", tokenURL, clientID)
+	token := loadCachedToken(key)
+	if token != nil {
+		// refresh token is exchanged by the token source, once access token expires
+		refreshed, err := config.TokenSource(ctx, token).Token()
+		if err != nil {
+			log.Printf("[INFO] Cached OAuth token for %s is not valid anymore: %s", c.Host, err)
+		}
+		token = refreshed
+	}
+	if token == nil {
+		token, err = loginInBrowser(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("cannot log in to %s: %w", c.Host, err)
+		}
+	}
+	ts := &cachingTokenSource{
+		key:  key,
+		base: config.TokenSource(ctx, token),
+	}
+	if _, err = ts.Token(); err != nil {
+		return nil, fmt.Errorf("cannot log in to %s: %w", c.Host, err)
+	}
+	log.Printf("[INFO] Using OAuth user login for %s", c.Host)
+	return func(r *http.Request) error {
+		token, err := ts.Token()
+		if err != nil {
+			return fmt.Errorf("cannot refresh OAuth token for %s: %w", c.Host, err)
+		}
+		token.SetAuthHeader(r)
+		return nil
+	}, nil
+}
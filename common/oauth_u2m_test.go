@@ -0,0 +1,225 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// u2mFixture is OIDC server, that issues authorization codes and tokens
+type u2mFixture struct {
+	*httptest.Server
+	mu         sync.Mutex
+	challenges map[string]string
+	logins     int
+	refreshes  int
+}
+
+func newU2MFixture(t *testing.T) *u2mFixture {
+	f := &u2mFixture{challenges: map[string]string{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/oidc/v1/token":
+			assert.NoError(t, req.ParseForm())
+			assert.Equal(t, "databricks-cli", req.PostForm.Get("client_id"))
+			switch req.PostForm.Get("grant_type") {
+			case "authorization_code":
+				f.logins++
+				verifier := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+				assert.Equal(t, f.challenges[req.PostForm.Get("code")],
+					base64.RawURLEncoding.EncodeToString(verifier[:]))
+			case "refresh_token":
+				f.refreshes++
+				if req.PostForm.Get("refresh_token") == "revoked" {
+					rw.WriteHeader(400)
+					_, err := rw.Write([]byte(`{"error": "invalid_grant"}`))
+					assert.NoError(t, err)
+					return
+				}
+			}
+			_, err := fmt.Fprintf(rw, `{"access_token": "access-%d-%d", "refresh_token": "refresh", `+
+				`"token_type": "Bearer", "expires_in": 3600}`, f.logins, f.refreshes)
+			assert.NoError(t, err)
+		case "/api/2.0/clusters/list-zones":
+			assert.Equal(t, "Bearer access-1-0", req.Header.Get("Authorization"))
+			_, err := rw.Write([]byte(`{"zones": ["a"]}`))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "Received unexpected call: "+req.RequestURI)
+		}
+	}))
+	return f
+}
+
+// browser logs in instantly and gets redirected back with authorization code
+func (f *u2mFixture) browser(t *testing.T) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		assert.Equal(t, f.URL+"/oidc/v1/authorize", fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, u.Path))
+		assert.Equal(t, "offline_access all-apis", q.Get("scope"))
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+		f.mu.Lock()
+		code := fmt.Sprintf("code-%d", len(f.challenges))
+		f.challenges[code] = q.Get("code_challenge")
+		f.mu.Unlock()
+		go func() {
+			resp, err := http.Get(fmt.Sprintf("%s?code=%s&state=%s", q.Get("redirect_uri"), code, q.Get("state")))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func setupU2M(t *testing.T) func() {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	prevAddr, prevCache, prevBrowser, prevPrompt := u2mRedirectAddr, u2mTokenCache, openBrowser, u2mPrompt
+	u2mRedirectAddr = fmt.Sprintf("localhost:%d", port)
+	u2mTokenCache = filepath.Join(t.TempDir(), "token-cache.json")
+	return func() {
+		u2mRedirectAddr, u2mTokenCache, openBrowser, u2mPrompt = prevAddr, prevCache, prevBrowser, prevPrompt
+	}
+}
+
+func TestOAuthU2M_LoginAndCache(t *testing.T) {
+	defer setupU2M(t)()
+	f := newU2MFixture(t)
+	defer f.Close()
+	openBrowser = f.browser(t)
+
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     f.URL,
+		AuthType: AuthTypeOAuthU2M,
+	})
+	require.NoError(t, err)
+	err = client.Get(context.Background(), "/clusters/list-zones", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.logins)
+
+	// the next run uses cached token without a browser
+	openBrowser = func(string) error {
+		assert.Fail(t, "browser must not be opened")
+		return nil
+	}
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:     f.URL,
+		AuthType: AuthTypeOAuthU2M,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.logins)
+	assert.Equal(t, 0, f.refreshes)
+}
+
+func TestOAuthU2M_ExpiredTokenIsRefreshed(t *testing.T) {
+	defer setupU2M(t)()
+	f := newU2MFixture(t)
+	defer f.Close()
+	openBrowser = f.browser(t)
+	key := fmt.Sprintf("%s/oidc/v1/token databricks-cli", f.URL)
+
+	// expired access token is refreshed silently
+	require.NoError(t, storeCachedToken(key, &oauth2.Token{RefreshToken: "refresh"}))
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     f.URL,
+		AuthType: AuthTypeOAuthU2M,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.logins)
+	assert.Equal(t, 1, f.refreshes)
+	assert.Equal(t, "access-0-1", loadCachedToken(key).AccessToken)
+
+	// revoked refresh token requires a new login
+	require.NoError(t, storeCachedToken(key, &oauth2.Token{RefreshToken: "revoked"}))
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:     f.URL,
+		AuthType: AuthTypeOAuthU2M,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.logins)
+	assert.Equal(t, "access-1-2", loadCachedToken(key).AccessToken)
+}
+
+func TestOAuthU2M_Errors(t *testing.T) {
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		AuthType: AuthTypeOAuthU2M,
+	})
+	assert.EqualError(t, err, "host is empty, but is required by oauth-u2m")
+
+	_, err = configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://abc.cloud.databricks.com",
+		Token:    "..",
+		AuthType: "magic",
+	})
+	assert.EqualError(t, err, "unknown auth type: magic")
+}
+
+func TestOAuthU2M_LoginDenied(t *testing.T) {
+	defer setupU2M(t)()
+	openBrowser = func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		go func() {
+			resp, err := http.Get(fmt.Sprintf("%s?error=access_denied&error_description=nope&state=%s",
+				q.Get("redirect_uri"), q.Get("state")))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://abc.cloud.databricks.com",
+		AuthType: AuthTypeOAuthU2M,
+	})
+	assert.EqualError(t, err, "cannot log in to https://abc.cloud.databricks.com: access_denied: nope")
+}
+
+func TestOAuthU2M_BrowserCannotBeOpened(t *testing.T) {
+	defer setupU2M(t)()
+	var prompt bytes.Buffer
+	u2mPrompt = &prompt
+	openBrowser = func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		// user opens the printed URL manually
+		go func() {
+			resp, err := http.Get(fmt.Sprintf("%s?error=access_denied&error_description=nope&state=%s",
+				q.Get("redirect_uri"), q.Get("state")))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+		return fmt.Errorf("no display")
+	}
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://abc.cloud.databricks.com",
+		AuthType: AuthTypeOAuthU2M,
+	})
+	assert.EqualError(t, err, "cannot log in to https://abc.cloud.databricks.com: access_denied: nope")
+	assert.Contains(t, prompt.String(), "Cannot open browser: no display\n")
+	assert.Contains(t, prompt.String(), "https://abc.cloud.databricks.com/oidc/v1/authorize?")
+}
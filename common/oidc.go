@@ -0,0 +1,191 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtTokenType           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// idTokenSource returns OIDC ID token of the CI/CD platform, that runs the provider
+type idTokenSource struct {
+	name  string
+	token func(ctx context.Context, audience string) (string, error)
+}
+
+// ambientIDTokenSource detects Terraform Cloud dynamic credentials or GitHub Actions
+// with `id-token: write` permission. Returns nil outside of those platforms.
+func (c *DatabricksClient) ambientIDTokenSource() *idTokenSource {
+	if os.Getenv("TFC_WORKLOAD_IDENTITY_TOKEN") != "" {
+		return &idTokenSource{
+			name: "Terraform Cloud",
+			token: func(ctx context.Context, audience string) (string, error) {
+				// audience is configured with TFC_WORKLOAD_IDENTITY_AUDIENCE of the workspace
+				return os.Getenv("TFC_WORKLOAD_IDENTITY_TOKEN"), nil
+			},
+		}
+	}
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return nil
+	}
+	return &idTokenSource{
+		name: "GitHub Actions",
+		token: func(ctx context.Context, audience string) (string, error) {
+			u, err := url.Parse(requestURL)
+			if err != nil {
+				return "", err
+			}
+			q := u.Query()
+			q.Set("audience", audience)
+			u.RawQuery = q.Encode()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+			if err != nil {
+				return "", err
+			}
+			req.Header.Set("Authorization", "Bearer "+requestToken)
+			var idToken struct {
+				Value string `json:"value"`
+			}
+			err = c.doTokenRequest(req, &idToken)
+			if err != nil {
+				return "", err
+			}
+			if idToken.Value == "" {
+				return "", fmt.Errorf("empty ID token")
+			}
+			return idToken.Value, nil
+		},
+	}
+}
+
+// doTokenRequest sends token request through standard client, so that it is proxied, retried
+// and rate-limited. Neither request nor response is logged, as both have credentials.
+func (c *DatabricksClient) doTokenRequest(req *http.Request, v interface{}) error {
+	client := http.DefaultClient
+	if c.httpClient != nil {
+		client = c.httpClient.StandardClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// oidcTokenSource exchanges ID tokens for Databricks OAuth tokens
+type oidcTokenSource struct {
+	ctx      context.Context
+	client   *DatabricksClient
+	idTokens *idTokenSource
+	tokenURL string
+	audience string
+}
+
+func (ts *oidcTokenSource) Token() (*oauth2.Token, error) {
+	idToken, err := ts.idTokens.token(ts.ctx, ts.audience)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %s ID token: %w", ts.idTokens.name, err)
+	}
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {idToken},
+		"subject_token_type": {jwtTokenType},
+		"scope":              {strings.Join(oauthScopes, " ")},
+		"client_id":          {ts.client.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ts.ctx, http.MethodPost, ts.tokenURL,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var exchanged struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = ts.client.doTokenRequest(req, &exchanged)
+	if err != nil {
+		return nil, fmt.Errorf("cannot exchange %s ID token: %w", ts.idTokens.name, err)
+	}
+	if exchanged.AccessToken == "" {
+		return nil, fmt.Errorf("cannot exchange %s ID token: empty access token", ts.idTokens.name)
+	}
+	token := &oauth2.Token{
+		AccessToken: exchanged.AccessToken,
+		TokenType:   exchanged.TokenType,
+	}
+	if exchanged.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(exchanged.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// configureWithOIDCTokenExchange uses workload identity federation of a service principal,
+// so that CI/CD pipelines run without any stored secrets
+func (c *DatabricksClient) configureWithOIDCTokenExchange() (func(r *http.Request) error, error) {
+	if c.ClientID == "" || c.ClientSecret != "" || c.Host == "" {
+		return nil, nil
+	}
+	idTokens := c.ambientIDTokenSource()
+	if idTokens == nil {
+		return nil, nil
+	}
+	c.fixHost()
+	tokenURL, err := c.oauthTokenURL()
+	if err != nil {
+		return nil, err
+	}
+	audience := c.OIDCAudience
+	if audience == "" {
+		audience = tokenURL
+	}
+	ctx := c.InitContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ts := oauth2.ReuseTokenSource(nil, &oidcTokenSource{
+		ctx:      ctx,
+		client:   c,
+		idTokens: idTokens,
+		tokenURL: tokenURL,
+		audience: audience,
+	})
+	// fail early on misconfigured federation policy
+	_, err = ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Using %s OIDC token federation for %s", idTokens.name, c.ClientID)
+	return func(r *http.Request) error {
+		token, err := ts.Token()
+		if err != nil {
+			return err
+		}
+		token.SetAuthHeader(r)
+		return nil
+	}, nil
+}
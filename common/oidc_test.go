@@ -0,0 +1,108 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oidcServer(t *testing.T, expectedAudience string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/github/token":
+			assert.Equal(t, "Bearer ghs", req.Header.Get("Authorization"))
+			assert.Equal(t, "1", req.URL.Query().Get("api-version"))
+			audience := expectedAudience
+			if audience == "" {
+				audience = server.URL + "/oidc/v1/token"
+			}
+			assert.Equal(t, audience, req.URL.Query().Get("audience"))
+			_, err := rw.Write([]byte(`{"value": "github-jwt"}`))
+			assert.NoError(t, err)
+		case "/oidc/v1/token":
+			assert.NoError(t, req.ParseForm())
+			assert.Equal(t, tokenExchangeGrantType, req.PostForm.Get("grant_type"))
+			assert.Equal(t, jwtTokenType, req.PostForm.Get("subject_token_type"))
+			assert.Equal(t, "all-apis", req.PostForm.Get("scope"))
+			assert.Equal(t, "a", req.PostForm.Get("client_id"))
+			if req.PostForm.Get("subject_token") == "untrusted" {
+				rw.WriteHeader(401)
+				_, err := rw.Write([]byte(`{"error": "invalid_request"}`))
+				assert.NoError(t, err)
+				return
+			}
+			assert.Contains(t, []string{"github-jwt", "tfc-jwt"}, req.PostForm.Get("subject_token"))
+			_, err := rw.Write([]byte(`{"access_token": "xyz", "token_type": "Bearer", "expires_in": 3600}`))
+			assert.NoError(t, err)
+		case "/api/2.0/clusters/list-zones":
+			assert.Equal(t, "Bearer xyz", req.Header.Get("Authorization"))
+			_, err := rw.Write([]byte(`{"zones": ["a"]}`))
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "Received unexpected call: "+req.RequestURI)
+		}
+	}))
+	return server
+}
+
+func TestOIDCTokenExchange_GitHubActions(t *testing.T) {
+	defer CleanupEnvironment()()
+	for _, audience := range []string{"", "custom"} {
+		server := oidcServer(t, audience)
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"/github/token?api-version=1")
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "ghs")
+		client, err := configureAndAuthenticate(&DatabricksClient{
+			Host:         server.URL,
+			ClientID:     "a",
+			OIDCAudience: audience,
+		})
+		require.NoError(t, err)
+		err = client.Get(context.Background(), "/clusters/list-zones", nil, nil)
+		assert.NoError(t, err)
+		server.Close()
+	}
+}
+
+func TestOIDCTokenExchange_TerraformCloud(t *testing.T) {
+	defer CleanupEnvironment()()
+	server := oidcServer(t, "")
+	defer server.Close()
+	os.Setenv("TFC_WORKLOAD_IDENTITY_TOKEN", "tfc-jwt")
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     server.URL,
+		ClientID: "a",
+	})
+	require.NoError(t, err)
+	err = client.Get(context.Background(), "/clusters/list-zones", nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestOIDCTokenExchange_Untrusted(t *testing.T) {
+	defer CleanupEnvironment()()
+	server := oidcServer(t, "")
+	defer server.Close()
+	os.Setenv("TFC_WORKLOAD_IDENTITY_TOKEN", "untrusted")
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     server.URL,
+		ClientID: "a",
+	})
+	AssertErrorStartsWith(t, err, "cannot exchange Terraform Cloud ID token")
+	assert.Contains(t, err.Error(), "invalid_request")
+}
+
+func TestOIDCTokenExchange_NotInPipeline(t *testing.T) {
+	defer CleanupEnvironment()()
+	os.Setenv("PATH", "testdata:/bin")
+	_, err := configureAndAuthenticate(&DatabricksClient{
+		Host:     "https://abc.cloud.databricks.com",
+		ClientID: "a",
+	})
+	AssertErrorStartsWith(t, err, "authentication is not configured for provider")
+}
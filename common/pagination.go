@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PageStyle is the pagination convention of a list API
+type PageStyle int
+
+const (
+	// OffsetPages are requested with offset query parameter, while response has_more is true
+	OffsetPages PageStyle = iota
+	// TokenPages are requested with page_token from next_page_token of the previous response
+	TokenPages
+	// ScimPages are requested with 1-based startIndex and count of ScimPageSize,
+	// until totalResults are fetched
+	ScimPages
+)
+
+// pageInfo has pagination fields of all styles
+type pageInfo struct {
+	HasMore       bool   `json:"has_more,omitempty"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+	TotalResults  int    `json:"totalResults,omitempty"`
+}
+
+// maxPages protects from APIs, that keep returning the same page
+const maxPages = 10000
+
+// Paginate performs GET on path with query and calls back for every page with its raw JSON body.
+// Callback returns the number of items on the page, which is used to request the next page.
+// The first page is requested without pagination parameters, so that APIs without pagination
+// return everything at once, except for SCIM APIs, whose page size is always bounded by count.
+func (c *DatabricksClient) Paginate(ctx context.Context, path string, style PageStyle,
+	query map[string]string, callback func(page json.RawMessage) (int, error)) error {
+	if style < OffsetPages || style > ScimPages {
+		return fmt.Errorf("unknown page style: %d", style)
+	}
+	params := map[string]string{}
+	for k, v := range query {
+		params[k] = v
+	}
+	if style == ScimPages && params["count"] == "" {
+		params["count"] = fmt.Sprint(c.scimPageSize())
+	}
+	fetched := 0
+	for pages := 0; pages < maxPages; pages++ {
+		requestURL := path
+		if query != nil || len(params) > 0 {
+			// GET requests with map data always have query separator
+			requestURL += "?" + encodeQuery(params)
+		}
+		var page json.RawMessage
+		var err error
+		if style == ScimPages {
+			err = c.Scim(ctx, http.MethodGet, requestURL, nil, &page)
+		} else {
+			err = c.Get(ctx, requestURL, nil, &page)
+		}
+		if err != nil {
+			return err
+		}
+		items, err := callback(page)
+		if err != nil {
+			return err
+		}
+		fetched += items
+		var info pageInfo
+		if len(page) > 0 {
+			if err = json.Unmarshal(page, &info); err != nil {
+				return fmt.Errorf("cannot parse pagination of %s: %w", path, err)
+			}
+		}
+		switch style {
+		case OffsetPages:
+			if !info.HasMore || items == 0 {
+				return nil
+			}
+			params["offset"] = fmt.Sprint(fetched)
+		case TokenPages:
+			if info.NextPageToken == "" {
+				return nil
+			}
+			params["page_token"] = info.NextPageToken
+		case ScimPages:
+			if fetched >= info.TotalResults || items == 0 {
+				return nil
+			}
+			params["startIndex"] = fmt.Sprint(fetched + 1)
+		}
+	}
+	return fmt.Errorf("%s has more than %d pages", path, maxPages)
+}
+
+func (c *DatabricksClient) scimPageSize() int {
+	if c.ScimPageSize <= 0 {
+		return DefaultScimPageSize
+	}
+	return c.ScimPageSize
+}
+
+// encodeQuery escapes parameters in a stable order, like GET requests with map data do
+func encodeQuery(params map[string]string) string {
+	keys := []string{}
+	for k, v := range params {
+		if v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := []string{}
+	for _, k := range keys {
+		s = append(s, fmt.Sprintf("%s=%s",
+			strings.Replace(url.QueryEscape(k), "+", "%20", -1),
+			strings.Replace(url.QueryEscape(params[k]), "+", "%20", -1)))
+	}
+	return strings.Join(s, "&")
+}
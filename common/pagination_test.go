@@ -0,0 +1,135 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagesServer responds with pages by request URI and records requests
+func pagesServer(t *testing.T, pages map[string]string) (*DatabricksClient, *[]string, func()) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.RequestURI)
+		page, ok := pages[req.RequestURI]
+		if !assert.True(t, ok, "unexpected request: %s", req.RequestURI) {
+			rw.WriteHeader(404)
+			return
+		}
+		_, err := rw.Write([]byte(page))
+		assert.NoError(t, err)
+	}))
+	client := &DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	}
+	require.NoError(t, client.Configure())
+	return client, &requests, server.Close
+}
+
+type namesPage struct {
+	Names     []string `json:"names"`
+	Resources []string `json:"Resources"`
+}
+
+func collectNames(names *[]string) func(raw json.RawMessage) (int, error) {
+	return func(raw json.RawMessage) (int, error) {
+		var page namesPage
+		err := json.Unmarshal(raw, &page)
+		*names = append(*names, page.Names...)
+		*names = append(*names, page.Resources...)
+		return len(page.Names) + len(page.Resources), err
+	}
+}
+
+func TestPaginate_Offset(t *testing.T) {
+	client, requests, stop := pagesServer(t, map[string]string{
+		"/api/2.0/jobs/list":          `{"names": ["a", "b"], "has_more": true}`,
+		"/api/2.0/jobs/list?offset=2": `{"names": ["c"], "has_more": false}`,
+	})
+	defer stop()
+	names := []string{}
+	err := client.Paginate(context.Background(), "/jobs/list", OffsetPages, nil, collectNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Len(t, *requests, 2)
+}
+
+func TestPaginate_Token(t *testing.T) {
+	client, _, stop := pagesServer(t, map[string]string{
+		"/api/2.0/repos?path_prefix=%2FRepos":                  `{"names": ["a"], "next_page_token": "x y"}`,
+		"/api/2.0/repos?page_token=x%20y&path_prefix=%2FRepos": `{"names": ["b"]}`,
+	})
+	defer stop()
+	names := []string{}
+	err := client.Paginate(context.Background(), "/repos", TokenPages, map[string]string{
+		"path_prefix": "/Repos",
+	}, collectNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestPaginate_Scim(t *testing.T) {
+	client, _, stop := pagesServer(t, map[string]string{
+		"/api/2.0/preview/scim/v2/Groups?count=100":              `{"Resources": ["a", "b"], "totalResults": 3}`,
+		"/api/2.0/preview/scim/v2/Groups?count=100&startIndex=3": `{"Resources": ["c"], "totalResults": 3}`,
+	})
+	defer stop()
+	names := []string{}
+	err := client.Paginate(context.Background(), "/preview/scim/v2/Groups", ScimPages,
+		map[string]string{}, collectNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func TestPaginate_ScimPageSize(t *testing.T) {
+	client, requests, stop := pagesServer(t, map[string]string{
+		"/api/2.0/preview/scim/v2/Users?count=2&filter=active":              `{"Resources": ["a", "b"], "totalResults": 3}`,
+		"/api/2.0/preview/scim/v2/Users?count=2&filter=active&startIndex=3": `{"Resources": ["c"], "totalResults": 3}`,
+	})
+	defer stop()
+	client.ScimPageSize = 2
+	names := []string{}
+	err := client.Paginate(context.Background(), "/preview/scim/v2/Users", ScimPages,
+		map[string]string{"filter": "active"}, collectNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Len(t, *requests, 2)
+}
+
+func TestPaginate_EmptyScimPageStops(t *testing.T) {
+	client, requests, stop := pagesServer(t, map[string]string{
+		"/api/2.0/preview/scim/v2/Users?count=100": `{"totalResults": 10}`,
+	})
+	defer stop()
+	names := []string{}
+	err := client.Paginate(context.Background(), "/preview/scim/v2/Users", ScimPages, nil, collectNames(&names))
+	require.NoError(t, err)
+	assert.Len(t, names, 0)
+	assert.Len(t, *requests, 1)
+}
+
+func TestPaginate_Errors(t *testing.T) {
+	client, _, stop := pagesServer(t, map[string]string{
+		"/api/2.0/jobs/list": `{"names": "not a list"}`,
+	})
+	defer stop()
+	err := client.Paginate(context.Background(), "/jobs/list", PageStyle(7), nil, nil)
+	assert.EqualError(t, err, "unknown page style: 7")
+
+	names := []string{}
+	err = client.Paginate(context.Background(), "/jobs/list", OffsetPages, nil, collectNames(&names))
+	assert.Error(t, err)
+
+	err = client.Paginate(context.Background(), "/jobs/list", OffsetPages, nil,
+		func(raw json.RawMessage) (int, error) {
+			return 0, fmt.Errorf("nope")
+		})
+	assert.EqualError(t, err, "nope")
+}
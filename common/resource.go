@@ -2,7 +2,10 @@ package common
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -28,10 +31,10 @@ func (r Resource) ToResource() *schema.Resource {
 		update = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 			c := m.(*DatabricksClient)
 			if err := r.Update(ctx, d, c); err != nil {
-				return diag.FromErr(err)
+				return DiagFromErr(err)
 			}
 			if err := r.Read(ctx, d, c); err != nil {
-				return diag.FromErr(err)
+				return DiagFromErr(err)
 			}
 			return nil
 		}
@@ -53,7 +56,7 @@ func (r Resource) ToResource() *schema.Resource {
 			return nil
 		}
 		if err != nil {
-			return diag.FromErr(err)
+			return DiagFromErr(err)
 		}
 		return nil
 	}
@@ -66,10 +69,10 @@ func (r Resource) ToResource() *schema.Resource {
 			c := m.(*DatabricksClient)
 			err := r.Create(ctx, d, c)
 			if err != nil {
-				return diag.FromErr(err)
+				return DiagFromErr(err)
 			}
 			if err = r.Read(ctx, d, c); err != nil {
-				return diag.FromErr(err)
+				return DiagFromErr(err)
 			}
 			return nil
 		},
@@ -77,7 +80,7 @@ func (r Resource) ToResource() *schema.Resource {
 		UpdateContext: update,
 		DeleteContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 			if err := r.Delete(ctx, d, m.(*DatabricksClient)); err != nil {
-				return diag.FromErr(err)
+				return DiagFromErr(err)
 			}
 			return nil
 		},
@@ -96,6 +99,36 @@ func (r Resource) ToResource() *schema.Resource {
 	}
 }
 
+// DiagFromErr converts errors to diagnostics, where APIError has its error_code, HTTP status,
+// endpoint and request ID in the detail, so that failures could be reported to support
+func DiagFromErr(err error) diag.Diagnostics {
+	var apiError APIError
+	if !errors.As(err, &apiError) {
+		return diag.FromErr(err)
+	}
+	detail := []string{}
+	if apiError.ErrorCode != "" {
+		detail = append(detail, fmt.Sprintf("Error code: %s", apiError.ErrorCode))
+	}
+	if apiError.StatusCode != 0 {
+		detail = append(detail, fmt.Sprintf("HTTP status: %d", apiError.StatusCode))
+	}
+	if apiError.Resource != "" {
+		detail = append(detail, fmt.Sprintf("Endpoint: %s", apiError.Resource))
+	}
+	if apiError.RequestID != "" {
+		detail = append(detail, fmt.Sprintf("Request ID: %s", apiError.RequestID))
+	}
+	if docs := apiError.DocumentationURL(); docs != "" {
+		detail = append(detail, fmt.Sprintf("Documentation: %s", docs))
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+		Detail:   strings.Join(detail, "\n"),
+	}}
+}
+
 func MakeEmptyBlockSuppressFunc(name string) func(k, old, new string, d *schema.ResourceData) bool {
 	return func(k, old, new string, d *schema.ResourceData) bool {
 		log.Printf("[DEBUG] k='%v', old='%v', new='%v'", k, old, new)
@@ -2,8 +2,12 @@ package common
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -65,3 +69,35 @@ func TestUpdate(t *testing.T) {
 	assert.False(t, r.Schema["foo"].ForceNew)
 	assert.Equal(t, "", d.Id())
 }
+
+func TestDiagFromErr(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Request-Id", "req-1")
+		rw.WriteHeader(400)
+		_, err := rw.Write([]byte(`{"error_code": "INVALID_STATE", "message": "Cluster is terminating"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:  server.URL,
+		Token: "..",
+	})
+	require.NoError(t, err)
+	err = client.Post(context.Background(), "/clusters/edit", map[string]string{}, nil)
+	assert.True(t, HasErrorCode(err, "INVALID_STATE"))
+	assert.True(t, HasErrorCode(fmt.Errorf("cannot edit: %w", err), "INVALID_STATE"))
+	assert.False(t, HasErrorCode(err, "RESOURCE_DOES_NOT_EXIST"))
+
+	diags := DiagFromErr(fmt.Errorf("cannot edit: %w", err))
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "cannot edit: Cluster is terminating", diags[0].Summary)
+	assert.Equal(t, "Error code: INVALID_STATE\nHTTP status: 400\nEndpoint: /api/2.0/clusters/edit\n"+
+		"Request ID: req-1\nDocumentation: https://docs.databricks.com/dev-tools/api/latest/clusters.html#edit",
+		diags[0].Detail)
+
+	diags = DiagFromErr(fmt.Errorf("nope"))
+	assert.Equal(t, "nope", diags[0].Summary)
+	assert.Equal(t, "", diags[0].Detail)
+	assert.Nil(t, DiagFromErr(nil))
+}
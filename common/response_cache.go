@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// cacheablePaths return the same responses during the whole run, so that data sources
+// in different modules don't fetch them over and over again
+var cacheablePaths = map[string]bool{
+	"/clusters/list-node-types": true,
+	"/clusters/spark-versions":  true,
+	"/clusters/list-zones":      true,
+}
+
+// responseCache keeps bodies of successful responses. Concurrent requests for
+// the same key wait for the first one, errors are not cached.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	mu     sync.Mutex
+	body   []byte
+	cached bool
+}
+
+func (rc *responseCache) get(key string, fetch func() ([]byte, error)) ([]byte, error) {
+	rc.mu.Lock()
+	entry, ok := rc.entries[key]
+	if !ok {
+		entry = &cachedResponse{}
+		rc.entries[key] = entry
+	}
+	rc.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.cached {
+		log.Printf("[DEBUG] Cached response <- %s", key)
+		return entry.body, nil
+	}
+	body, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	entry.body = body
+	entry.cached = true
+	return body, nil
+}
+
+// cachedGet performs GET request or serves it from memory, if CacheResponses is enabled
+func (c *DatabricksClient) cachedGet(ctx context.Context, path string, request interface{}) ([]byte, error) {
+	fetch := func() ([]byte, error) {
+		return c.authenticatedQuery(ctx, http.MethodGet, path, request, c.api2)
+	}
+	if c.responses == nil || !cacheablePaths[path] {
+		return fetch()
+	}
+	query, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return c.responses.get(fmt.Sprintf("%s %s %s", http.MethodGet, path, query), fetch)
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countingServer(t *testing.T, cacheResponses bool) (*DatabricksClient, map[string]int, func()) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		calls[req.RequestURI]++
+		failing := req.URL.Path == "/api/2.0/clusters/list-zones" && calls[req.RequestURI] == 1
+		mu.Unlock()
+		if failing {
+			rw.WriteHeader(400)
+			_, err := rw.Write([]byte(`{"error_code": "INVALID_REQUEST", "message": "try again"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := rw.Write([]byte(`{"versions": []}`))
+		assert.NoError(t, err)
+	}))
+	client := &DatabricksClient{
+		Host:           server.URL,
+		Token:          "..",
+		CacheResponses: cacheResponses,
+	}
+	require.NoError(t, client.Configure())
+	return client, calls, server.Close
+}
+
+func TestCacheResponses(t *testing.T) {
+	client, calls, stop := countingServer(t, true)
+	defer stop()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var response map[string]interface{}
+			err := client.Get(ctx, "/clusters/spark-versions", nil, &response)
+			assert.NoError(t, err)
+			assert.Contains(t, response, "versions")
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 2; i++ {
+		err := client.Get(ctx, "/clusters/list-node-types", map[string]string{"a": "b"}, nil)
+		require.NoError(t, err)
+		err = client.Get(ctx, "/clusters/get", map[string]string{"cluster_id": "abc"}, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{
+		"/api/2.0/clusters/spark-versions":      1,
+		"/api/2.0/clusters/list-node-types?a=b": 1,
+		// clusters change their state, so they are never cached
+		"/api/2.0/clusters/get?cluster_id=abc": 2,
+	}, calls)
+}
+
+func TestCacheResponses_ErrorsAreNotCached(t *testing.T) {
+	client, calls, stop := countingServer(t, true)
+	defer stop()
+	ctx := context.Background()
+	err := client.Get(ctx, "/clusters/list-zones", nil, nil)
+	assert.EqualError(t, err, "try again")
+	for i := 0; i < 2; i++ {
+		err = client.Get(ctx, "/clusters/list-zones", nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls["/api/2.0/clusters/list-zones"])
+}
+
+func TestCacheResponses_Disabled(t *testing.T) {
+	client, calls, stop := countingServer(t, false)
+	defer stop()
+	for i := 0; i < 2; i++ {
+		err := client.Get(context.Background(), "/clusters/spark-versions", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls["/api/2.0/clusters/spark-versions"])
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// CheckExpiringPATs warns, when the personal access token, that is used by the provider,
+// expires within ExpiringPATsWarningDays. ID of a token is the SHA-256 hash of its value,
+// so that the configured token is found among the tokens of the current user without
+// warning about others.
+func (c *DatabricksClient) CheckExpiringPATs(ctx context.Context) diag.Diagnostics {
+	if c.ExpiringPATsWarningDays <= 0 {
+		return nil
+	}
+	err := c.Authenticate()
+	if err != nil {
+		// the same error is reported by the first resource
+		return nil
+	}
+	switch c.EffectiveAuthType() {
+	case "pat", "databricks-cli", "credential-helper":
+	default:
+		// OAuth, AAD and Google tokens and session PATs are renewed by the provider
+		return nil
+	}
+	if c.Token == "" {
+		return nil
+	}
+	var tokens struct {
+		TokenInfos []tokenInfo `json:"token_infos,omitempty"`
+	}
+	err = c.Get(ctx, "/token/list", nil, &tokens)
+	if err != nil {
+		// the check is advisory and never fails provider configuration
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Cannot check expiry of personal access token",
+			Detail:   err.Error(),
+		}}
+	}
+	tokenID := fmt.Sprintf("%x", sha256.Sum256([]byte(c.Token)))
+	for _, ti := range tokens.TokenInfos {
+		if ti.TokenID != tokenID {
+			continue
+		}
+		if ti.ExpiryTime <= 0 {
+			return nil
+		}
+		expiry := time.Unix(0, ti.ExpiryTime*int64(time.Millisecond))
+		if expiry.After(time.Now().AddDate(0, 0, c.ExpiringPATsWarningDays)) {
+			return nil
+		}
+		name := ti.TokenID
+		if ti.Comment != "" {
+			name = fmt.Sprintf("%s (%s)", ti.Comment, ti.TokenID)
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Personal access token of the provider expires soon",
+			Detail: fmt.Sprintf("Personal access token %s, that is used on %s, expires on %s, "+
+				"which is in less than %d days. Create a new token and update the provider configuration.",
+				name, c.Host, expiry.UTC().Format(time.RFC1123), c.ExpiringPATsWarningDays),
+		}}
+	}
+	// basic auth, or token of another user, like the one of a service principal
+	return nil
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tokenListServer(t *testing.T, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/2.0/token/list", req.URL.Path)
+		rw.WriteHeader(status)
+		_, err := rw.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestCheckExpiringPATs(t *testing.T) {
+	millis := func(d time.Duration) int64 {
+		return time.Now().Add(d).UnixNano() / int64(time.Millisecond)
+	}
+	configured := fmt.Sprintf("%x", sha256.Sum256([]byte("dapi123")))
+	server := tokenListServer(t, 200, fmt.Sprintf(`{"token_infos": [
+		{"token_id": "forever"},
+		{"token_id": "other", "comment": "someone else", "expiry_time": %d},
+		{"token_id": "%s", "comment": "terraform", "expiry_time": %d}
+	]}`, millis(time.Hour), configured, millis(24*time.Hour)))
+	defer server.Close()
+
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:                    server.URL,
+		Token:                   "dapi123",
+		ExpiringPATsWarningDays: 7,
+	})
+	require.NoError(t, err)
+	diags := client.CheckExpiringPATs(context.Background())
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Personal access token of the provider expires soon", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, fmt.Sprintf("Personal access token terraform (%s), that is used on ", configured))
+	assert.Contains(t, diags[0].Detail, "which is in less than 7 days")
+	assert.NotContains(t, diags[0].Detail, "someone else")
+}
+
+func TestCheckExpiringPATs_CredentialHelper(t *testing.T) {
+	defer CleanupEnvironment()()
+	millis := func(d time.Duration) int64 {
+		return time.Now().Add(d).UnixNano() / int64(time.Millisecond)
+	}
+	var configured string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/2.0/token/list", req.URL.Path)
+		_, err := rw.Write([]byte(fmt.Sprintf(`{"token_infos": [
+			{"token_id": "%s", "comment": "keychain", "expiry_time": %d}
+		]}`, configured, millis(24*time.Hour))))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	// the helper prints dapi- followed by the host
+	configured = fmt.Sprintf("%x", sha256.Sum256([]byte("dapi-"+server.URL)))
+
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:                    server.URL,
+		CredentialHelper:        "testdata/credential-helper",
+		ExpiringPATsWarningDays: 7,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "credential-helper", client.EffectiveAuthType())
+	diags := client.CheckExpiringPATs(context.Background())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Personal access token of the provider expires soon", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "keychain")
+}
+
+func TestCheckExpiringPATs_OtherTokensExpire(t *testing.T) {
+	millis := func(d time.Duration) int64 {
+		return time.Now().Add(d).UnixNano() / int64(time.Millisecond)
+	}
+	configured := fmt.Sprintf("%x", sha256.Sum256([]byte("dapi123")))
+	server := tokenListServer(t, 200, fmt.Sprintf(`{"token_infos": [
+		{"token_id": "other", "expiry_time": %d},
+		{"token_id": "%s", "expiry_time": %d}
+	]}`, millis(time.Hour), configured, millis(30*24*time.Hour)))
+	defer server.Close()
+
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:                    server.URL,
+		Token:                   "dapi123",
+		ExpiringPATsWarningDays: 7,
+	})
+	require.NoError(t, err)
+	assert.Len(t, client.CheckExpiringPATs(context.Background()), 0)
+}
+
+func TestCheckExpiringPATs_Failure(t *testing.T) {
+	server := tokenListServer(t, 403, `{"error_code": "PERMISSION_DENIED", "message": "token is expired"}`)
+	defer server.Close()
+
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:                    server.URL,
+		Token:                   "..",
+		ExpiringPATsWarningDays: 7,
+	})
+	require.NoError(t, err)
+	diags := client.CheckExpiringPATs(context.Background())
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "token is expired", diags[0].Detail)
+}
+
+func TestCheckExpiringPATs_Skipped(t *testing.T) {
+	// neither disabled check nor basic auth call the token list
+	for _, client := range []*DatabricksClient{
+		{Host: "https://x.invalid", Token: ".."},
+		{Host: "https://x.invalid", Username: "a", Password: "b", ExpiringPATsWarningDays: 7},
+	} {
+		require.NoError(t, client.Configure())
+		assert.Len(t, client.CheckExpiringPATs(context.Background()), 0)
+	}
+}
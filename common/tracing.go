@@ -0,0 +1,269 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tracesBatchSize is the number of spans exported in a single OTLP request
+	tracesBatchSize = 256
+	// otlpSpanKindInternal marks the root span of provider process
+	otlpSpanKindInternal = 1
+	// otlpSpanKindClient marks outgoing calls
+	otlpSpanKindClient = 3
+	otlpStatusOk       = 1
+	otlpStatusError    = 2
+)
+
+// traceParent is W3C trace context, like the one set by CI systems in TRACEPARENT
+var traceParent = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int) otlpAttribute {
+	// OTLP JSON encodes 64-bit integers as strings
+	v := fmt.Sprint(value)
+	return otlpAttribute{Key: key, Value: otlpValue{IntValue: &v}}
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID      string          `json:"traceId"`
+	SpanID       string          `json:"spanId"`
+	ParentSpanID string          `json:"parentSpanId,omitempty"`
+	Name         string          `json:"name"`
+	Kind         int             `json:"kind"`
+	Start        string          `json:"startTimeUnixNano"`
+	End          string          `json:"endTimeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+	Status       otlpStatus      `json:"status"`
+}
+
+// apiTracer exports spans of API calls to OpenTelemetry collector with OTLP/HTTP JSON
+// protocol. All calls of the provider process are children of the same root span, so
+// that the whole plan or apply is a single trace.
+type apiTracer struct {
+	mu         sync.Mutex
+	endpoint   string
+	headers    map[string]string
+	service    string
+	traceID    string
+	rootSpanID string
+	parentID   string
+	started    time.Time
+	spans      []otlpSpan
+	exports    sync.WaitGroup
+}
+
+var (
+	tracerOnce sync.Once
+	tracer     *apiTracer
+)
+
+func randomHex(size int) string {
+	raw := make([]byte, size)
+	_, _ = rand.Read(raw)
+	return hex.EncodeToString(raw)
+}
+
+// newTracerFromEnvironment returns nil, unless OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, just like OpenTelemetry SDKs do
+func newTracerFromEnvironment() *apiTracer {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	t := &apiTracer{
+		endpoint:   endpoint,
+		headers:    map[string]string{},
+		service:    os.Getenv("OTEL_SERVICE_NAME"),
+		traceID:    randomHex(16),
+		rootSpanID: randomHex(8),
+		started:    time.Now(),
+	}
+	if t.service == "" {
+		t.service = "terraform-provider-databricks"
+	}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			t.headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	if m := traceParent.FindStringSubmatch(os.Getenv("TRACEPARENT")); len(m) == 3 {
+		// join the trace of CI pipeline, that runs terraform
+		t.traceID, t.parentID = m[1], m[2]
+	}
+	log.Printf("[INFO] Exporting API call traces to %s", endpoint)
+	return t
+}
+
+func apiCallTracer() *apiTracer {
+	tracerOnce.Do(func() {
+		tracer = newTracerFromEnvironment()
+	})
+	return tracer
+}
+
+// record adds span of API call, including all of its retries
+func (t *apiTracer) record(ctx context.Context, request *http.Request, start time.Time,
+	resp *http.Response, attempt *lastAttempt, err error) {
+	if t == nil {
+		return
+	}
+	span := otlpSpan{
+		TraceID:      t.traceID,
+		SpanID:       randomHex(8),
+		ParentSpanID: t.rootSpanID,
+		Name:         fmt.Sprintf("%s %s", request.Method, metricsPath(request.URL.Path)),
+		Kind:         otlpSpanKindClient,
+		Start:        fmt.Sprint(start.UnixNano()),
+		End:          fmt.Sprint(time.Now().UnixNano()),
+		Attributes: []otlpAttribute{
+			stringAttribute("http.method", request.Method),
+			stringAttribute("http.url", fmt.Sprintf("%s://%s%s",
+				request.URL.Scheme, request.URL.Host, request.URL.Path)),
+			stringAttribute("databricks.resource", ResourceName.GetOrUnknown(ctx)),
+		},
+		Status: otlpStatus{Code: otlpStatusOk},
+	}
+	status := attempt.status
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	if status > 0 {
+		span.Attributes = append(span.Attributes, intAttribute("http.status_code", status))
+	}
+	if attempt.attempts > 1 {
+		span.Attributes = append(span.Attributes, intAttribute("databricks.retries", attempt.attempts-1))
+	}
+	if attempt.requestID != "" {
+		span.Attributes = append(span.Attributes, stringAttribute("databricks.request_id", attempt.requestID))
+	}
+	var ae APIError
+	if errors.As(err, &ae) {
+		// retryablehttp wraps errors with full URL, that is already in attributes
+		err = ae
+	}
+	if err != nil {
+		span.Status = otlpStatus{Code: otlpStatusError, Message: err.Error()}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, span)
+	if len(t.spans) >= tracesBatchSize {
+		batch := t.spans
+		t.spans = nil
+		t.exports.Add(1)
+		go func() {
+			defer t.exports.Done()
+			t.export(batch)
+		}()
+	}
+}
+
+// export sends spans to collector. Failures are only logged, as tracing must never fail applies.
+func (t *apiTracer) export(spans []otlpSpan) {
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []otlpAttribute{
+						stringAttribute("service.name", t.service),
+						stringAttribute("service.version", version),
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "databricks", "version": version},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("[WARN] Cannot encode traces: %s", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[WARN] Cannot export traces: %s", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("[WARN] Cannot export traces: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		log.Printf("[WARN] Cannot export traces: %s", resp.Status)
+	}
+}
+
+// flush ends the root span and exports all spans, that are not exported yet
+func (t *apiTracer) flush() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	spans := append(t.spans, otlpSpan{
+		TraceID:      t.traceID,
+		SpanID:       t.rootSpanID,
+		ParentSpanID: t.parentID,
+		Name:         "terraform-provider-databricks",
+		Kind:         otlpSpanKindInternal,
+		Start:        fmt.Sprint(t.started.UnixNano()),
+		End:          fmt.Sprint(time.Now().UnixNano()),
+		Status:       otlpStatus{Code: otlpStatusOk},
+	})
+	t.spans = nil
+	t.mu.Unlock()
+	t.export(spans)
+	t.exports.Wait()
+}
+
+// FlushAPITraces exports the remaining spans of API calls, if tracing is enabled.
+// It is called once the provider process stops.
+func FlushAPITraces() {
+	apiCallTracer().flush()
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type otlpCollector struct {
+	*httptest.Server
+	mu    sync.Mutex
+	spans []otlpSpan
+}
+
+func newOTLPCollector(t *testing.T) *otlpCollector {
+	c := &otlpCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/traces", req.URL.Path)
+		assert.Equal(t, "secret", req.Header.Get("X-Api-Key"))
+		var export struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []otlpSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&export))
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range export.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	return c
+}
+
+func spanAttributes(span otlpSpan) map[string]string {
+	attrs := map[string]string{}
+	for _, a := range span.Attributes {
+		if a.Value.StringValue != nil {
+			attrs[a.Key] = *a.Value.StringValue
+		}
+		if a.Value.IntValue != nil {
+			attrs[a.Key] = *a.Value.IntValue
+		}
+	}
+	return attrs
+}
+
+func TestAPITraces(t *testing.T) {
+	defer CleanupEnvironment()()
+	collector := newOTLPCollector(t)
+	defer collector.Close()
+	os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL+"/")
+	os.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "X-Api-Key=secret")
+	os.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	tracerOnce.Do(func() {})
+	tracer = newTracerFromEnvironment()
+	defer func() {
+		tracer = nil
+	}()
+
+	workspace := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/2.0/clusters/get" {
+			rw.WriteHeader(404)
+			_, err := rw.Write([]byte(`{"error_code": "NOT_FOUND", "message": "nope"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := rw.Write([]byte(`{}`))
+		assert.NoError(t, err)
+	}))
+	defer workspace.Close()
+	client, err := configureAndAuthenticate(&DatabricksClient{
+		Host:  workspace.URL,
+		Token: "..",
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), ResourceName, "cluster")
+	require.NoError(t, client.Get(ctx, "/clusters/list-zones", nil, nil))
+	assert.Error(t, client.Get(ctx, "/clusters/get?cluster_id=abc", nil, nil))
+	FlushAPITraces()
+
+	require.Len(t, collector.spans, 3)
+	root := collector.spans[2]
+	assert.Equal(t, "terraform-provider-databricks", root.Name)
+	assert.Equal(t, "b7ad6b7169203331", root.ParentSpanID)
+	for _, span := range collector.spans {
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.TraceID)
+	}
+
+	zones := collector.spans[0]
+	assert.Equal(t, "GET /api/2.0/clusters/list-zones", zones.Name)
+	assert.Equal(t, root.SpanID, zones.ParentSpanID)
+	assert.Equal(t, otlpStatusOk, zones.Status.Code)
+	attrs := spanAttributes(zones)
+	assert.Equal(t, "GET", attrs["http.method"])
+	assert.Equal(t, workspace.URL+"/api/2.0/clusters/list-zones", attrs["http.url"])
+	assert.Equal(t, "200", attrs["http.status_code"])
+	assert.Equal(t, "cluster", attrs["databricks.resource"])
+
+	missing := collector.spans[1]
+	assert.Equal(t, otlpStatusError, missing.Status.Code)
+	assert.Equal(t, "nope", missing.Status.Message)
+	assert.Equal(t, "404", spanAttributes(missing)["http.status_code"])
+}
+
+func TestAPITraces_Disabled(t *testing.T) {
+	defer CleanupEnvironment()()
+	assert.Nil(t, newTracerFromEnvironment())
+	// nil tracer ignores everything
+	var disabled *apiTracer
+	disabled.record(context.Background(), nil, time.Now(), nil, nil, nil)
+	disabled.flush()
+}
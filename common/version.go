@@ -10,6 +10,10 @@ var (
 	Provider contextKey = 2
 	// Current is the current name of integration test
 	Current contextKey = 3
+	// retryState holds the last attempt of the current request
+	retryState contextKey = 4
+	// declaredTimeouts is set for resources with `timeouts {}` block
+	declaredTimeouts contextKey = 5
 )
 
 type contextKey int
@@ -2,9 +2,11 @@ package compute
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,7 +16,7 @@ import (
 )
 
 func (a ClustersAPI) defaultTimeout() time.Duration {
-	return 30 * time.Minute
+	return common.OperationTimeout(a.context, 30*time.Minute)
 }
 
 // NewClustersAPI creates ClustersAPI instance from provider meta
@@ -33,16 +35,15 @@ type ClustersAPI struct {
 
 // Create creates a new Spark cluster and waits till it's running
 func (a ClustersAPI) Create(cluster Cluster) (info ClusterInfo, err error) {
-	var ci ClusterID
-	err = a.client.Post(a.context, "/clusters/create", cluster, &ci)
+	created, err := a.CreateWithoutWait(cluster)
 	if err != nil {
 		return
 	}
-	info, err = a.waitForClusterStatus(ci.ClusterID, ClusterStateRunning)
+	info, err = a.waitForClusterStatus(created.ClusterID, ClusterStateRunning)
 	if err != nil {
 		// https://github.com/databrickslabs/terraform-provider-databricks/issues/383
 		log.Printf("[ERROR] Cleaning up created cluster, that failed to start: %s", err.Error())
-		deleteErr := a.PermanentDelete(ci.ClusterID)
+		deleteErr := a.PermanentDelete(created.ClusterID)
 		if deleteErr != nil {
 			log.Printf("[ERROR] Failed : %s", deleteErr.Error())
 			err = deleteErr
@@ -51,6 +52,20 @@ func (a ClustersAPI) Create(cluster Cluster) (info ClusterInfo, err error) {
 	return
 }
 
+// CreateWithoutWait creates a new Spark cluster and returns, while it's still pending
+func (a ClustersAPI) CreateWithoutWait(cluster Cluster) (info ClusterInfo, err error) {
+	var ci ClusterID
+	err = a.client.Post(a.context, "/clusters/create", cluster, &ci)
+	if err != nil {
+		return
+	}
+	info = ClusterInfo{
+		ClusterID: ci.ClusterID,
+		State:     ClusterStatePending,
+	}
+	return
+}
+
 // Edit edits the configuration of a cluster to match the provided attributes and size
 func (a ClustersAPI) Edit(cluster Cluster) (info ClusterInfo, err error) {
 	info, err = a.Get(cluster.ClusterID)
@@ -289,9 +304,15 @@ func (a ClustersAPI) Events(eventsRequest EventsRequest) ([]ClusterEvent, error)
 // up to 70 of the most recently terminated interactive clusters in the past 30 days,
 // and up to 30 of the most recently terminated job clusters in the past 30 days
 func (a ClustersAPI) List() ([]ClusterInfo, error) {
-	var clusterList ClusterList
-	err := a.client.Get(a.context, "/clusters/list", nil, &clusterList)
-	return clusterList.Clusters, err
+	var clusters []ClusterInfo
+	err := a.client.Paginate(a.context, "/clusters/list", common.OffsetPages, nil,
+		func(raw json.RawMessage) (int, error) {
+			var page ClusterList
+			err := json.Unmarshal(raw, &page)
+			clusters = append(clusters, page.Clusters...)
+			return len(page.Clusters), err
+		})
+	return clusters, err
 }
 
 // ListNodeTypes returns a sorted list of supported Spark node types
@@ -373,6 +394,9 @@ type NodeTypeRequest struct {
 	PhotonDriverCapable   bool   `json:"photon_driver_capable,omitempty"`
 	IsIOCacheEnabled      bool   `json:"is_io_cache_enabled,omitempty"`
 	SupportPortForwarding bool   `json:"support_port_forwarding,omitempty"`
+	LocalNVMeDisk         bool   `json:"local_nvme_disk,omitempty"`
+	Graviton              bool   `json:"graviton,omitempty"`
+	Fleet                 bool   `json:"fleet,omitempty"`
 }
 
 func defaultSmallestNodeType(a ClustersAPI) string {
@@ -412,9 +436,20 @@ func (a ClustersAPI) GetSmallestNodeType(r NodeTypeRequest) string {
 				nt.NodeInstanceType.LocalNVMeDisks < 1) {
 			continue
 		}
+		if r.LocalNVMeDisk && (nt.NodeInstanceType == nil ||
+			nt.NodeInstanceType.LocalNVMeDisks < 1) {
+			continue
+		}
 		if r.Category != "" && nt.Category != r.Category {
 			continue
 		}
+		if r.Graviton && !nt.IsGraviton {
+			continue
+		}
+		// AWS fleet node types, like md-fleet.xlarge, pick any instance type of the family
+		if r.Fleet && !strings.Contains(nt.NodeTypeID, "-fleet.") {
+			continue
+		}
 		if r.IsIOCacheEnabled && nt.IsIOCacheEnabled != r.IsIOCacheEnabled {
 			continue
 		}
@@ -439,6 +474,27 @@ func (a ClustersAPI) ListSparkVersions() (SparkVersionsList, error) {
 	return sparkVersions, err
 }
 
+// newerRuntime compares runtime versions, like 10.4.x-scala2.12 and 9.1.x-scala2.12,
+// by numbers of major and minor versions instead of lexicographically
+func newerRuntime(a, b string) bool {
+	av := strings.Split(strings.SplitN(a, "-", 2)[0], ".")
+	bv := strings.Split(strings.SplitN(b, "-", 2)[0], ".")
+	for i := 0; i < len(av) && i < len(bv); i++ {
+		an, aErr := strconv.Atoi(av[i])
+		bn, bErr := strconv.Atoi(bv[i])
+		if aErr != nil || bErr != nil {
+			if av[i] != bv[i] {
+				return av[i] > bv[i]
+			}
+			continue
+		}
+		if an != bn {
+			return an > bn
+		}
+	}
+	return a > b
+}
+
 // LatestSparkVersion returns latest version matching the request parameters
 func (sparkVersions SparkVersionsList) LatestSparkVersion(req SparkVersionRequest) (string, error) {
 	var versions []string
@@ -449,6 +505,7 @@ func (sparkVersions SparkVersionsList) LatestSparkVersion(req SparkVersionReques
 				(strings.Contains(version.Version, "-ml-") == req.ML) &&
 				(strings.Contains(version.Version, "-hls-") == req.Genomics) &&
 				(strings.Contains(version.Version, "-gpu-") == req.GPU) &&
+				(strings.Contains(version.Version, "-photon-") == req.Photon) &&
 				(strings.Contains(version.Description, "Beta") == req.Beta))
 			if matches && req.LongTermSupport {
 				matches = (matches && strings.Contains(version.Description, "LTS"))
@@ -465,7 +522,9 @@ func (sparkVersions SparkVersionsList) LatestSparkVersion(req SparkVersionReques
 		return "", fmt.Errorf("spark versions query returned no results. Please change your search criteria and try again")
 	} else if len(versions) > 1 {
 		if req.Latest {
-			sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+			sort.Slice(versions, func(i, j int) bool {
+				return newerRuntime(versions[i], versions[j])
+			})
 		} else {
 			return "", fmt.Errorf("spark versions query returned multiple results. Please change your search criteria and try again")
 		}
@@ -152,7 +152,7 @@ func (a CommandsAPI) createContext(language, clusterID string) (string, error) {
 }
 
 func (a CommandsAPI) waitForCommandFinished(commandID, contextID, clusterID string) error {
-	return resource.RetryContext(a.context, 10*time.Minute, func() *resource.RetryError {
+	return resource.RetryContext(a.context, common.OperationTimeout(a.context, 10*time.Minute), func() *resource.RetryError {
 		commandInfo, err := a.getCommand(commandID, contextID, clusterID)
 		if err != nil {
 			return resource.NonRetryableError(err)
@@ -169,7 +169,7 @@ func (a CommandsAPI) waitForCommandFinished(commandID, contextID, clusterID stri
 }
 
 func (a CommandsAPI) waitForContextReady(contextID, clusterID string) error {
-	return resource.RetryContext(a.context, 10*time.Minute, func() *resource.RetryError {
+	return resource.RetryContext(a.context, common.OperationTimeout(a.context, 10*time.Minute), func() *resource.RetryError {
 		status, err := a.getContext(contextID, clusterID)
 		if err != nil {
 			return resource.NonRetryableError(err)
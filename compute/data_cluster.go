@@ -0,0 +1,110 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// findClusterByName returns the only interactive cluster with the given name.
+// Job clusters are skipped, as they reuse names of their jobs.
+func (a ClustersAPI) findClusterByName(name string) (ClusterInfo, error) {
+	clusters, err := a.List()
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+	found := []ClusterInfo{}
+	for _, cluster := range clusters {
+		if cluster.ClusterName == name && cluster.ClusterSource != "JOB" {
+			found = append(found, cluster)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return ClusterInfo{}, fmt.Errorf("there is no cluster named '%s'", name)
+	case 1:
+		return found[0], nil
+	}
+	return ClusterInfo{}, fmt.Errorf("there are %d clusters named '%s', use cluster_id instead", len(found), name)
+}
+
+// computedSchema returns read-only attribute of data sources, where maps and lists are of strings
+func computedSchema(t schema.ValueType) *schema.Schema {
+	s := &schema.Schema{
+		Type:     t,
+		Computed: true,
+	}
+	switch t {
+	case schema.TypeMap, schema.TypeList:
+		s.Elem = &schema.Schema{Type: schema.TypeString}
+	}
+	return s
+}
+
+// DataSourceCluster looks up an existing cluster by ID or name, so that clusters created outside
+// of Terraform could be referenced by jobs and permissions
+func DataSourceCluster() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"cluster_id", "cluster_name"},
+			},
+			"cluster_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"spark_version":           computedSchema(schema.TypeString),
+			"node_type_id":            computedSchema(schema.TypeString),
+			"driver_node_type_id":     computedSchema(schema.TypeString),
+			"instance_pool_id":        computedSchema(schema.TypeString),
+			"driver_instance_pool_id": computedSchema(schema.TypeString),
+			"policy_id":               computedSchema(schema.TypeString),
+			"num_workers":             computedSchema(schema.TypeInt),
+			"autotermination_minutes": computedSchema(schema.TypeInt),
+			"state":                   computedSchema(schema.TypeString),
+			"custom_tags":             computedSchema(schema.TypeMap),
+			"default_tags":            computedSchema(schema.TypeMap),
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			clusters := NewClustersAPI(ctx, m)
+			var cluster ClusterInfo
+			var err error
+			if clusterID, ok := d.GetOk("cluster_id"); ok {
+				cluster, err = clusters.Get(clusterID.(string))
+			} else {
+				cluster, err = clusters.findClusterByName(d.Get("cluster_name").(string))
+			}
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(cluster.ClusterID)
+			for k, v := range map[string]interface{}{
+				"cluster_id":              cluster.ClusterID,
+				"cluster_name":            cluster.ClusterName,
+				"spark_version":           cluster.SparkVersion,
+				"node_type_id":            cluster.NodeTypeID,
+				"driver_node_type_id":     cluster.DriverNodeTypeID,
+				"instance_pool_id":        cluster.InstancePoolID,
+				"driver_instance_pool_id": cluster.DriverInstancePoolID,
+				"policy_id":               cluster.PolicyID,
+				"num_workers":             cluster.NumWorkers,
+				"autotermination_minutes": cluster.AutoterminationMinutes,
+				"state":                   string(cluster.State),
+				"custom_tags":             cluster.CustomTags,
+				"default_tags":            cluster.DefaultTags,
+			} {
+				if err = d.Set(k, v); err != nil {
+					return common.DiagFromErr(err)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package compute
+
+import (
+	"context"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// DataSourceClusterEvents returns recent events of a cluster, so that alerting could be
+// configured for things like repeated init script failures
+func DataSourceClusterEvents() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// event types are not validated, as new ones are added to the API over time
+			"event_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"start_time": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"end_time": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"order": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  string(SortDescending),
+				ValidateFunc: validation.StringInSlice([]string{
+					string(SortDescending),
+					string(SortAscending),
+				}, false),
+			},
+			"max_items": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      50,
+				ValidateFunc: validation.IntBetween(1, 10000),
+			},
+			"events": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"timestamp":           computedSchema(schema.TypeInt),
+						"type":                computedSchema(schema.TypeString),
+						"user":                computedSchema(schema.TypeString),
+						"current_num_workers": computedSchema(schema.TypeInt),
+						"target_num_workers":  computedSchema(schema.TypeInt),
+						"reason_code":         computedSchema(schema.TypeString),
+						"reason_type":         computedSchema(schema.TypeString),
+						"reason_parameters":   computedSchema(schema.TypeMap),
+					},
+				},
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			clusterID := d.Get("cluster_id").(string)
+			request := EventsRequest{
+				ClusterID: clusterID,
+				StartTime: int64(d.Get("start_time").(int)),
+				EndTime:   int64(d.Get("end_time").(int)),
+				Order:     SortOrder(d.Get("order").(string)),
+				MaxItems:  uint(d.Get("max_items").(int)),
+			}
+			// API returns at most 500 events per page
+			request.Limit = int64(request.MaxItems)
+			if request.Limit > 500 {
+				request.Limit = 500
+			}
+			for _, v := range d.Get("event_types").(*schema.Set).List() {
+				request.EventTypes = append(request.EventTypes, ClusterEventType(v.(string)))
+			}
+			events, err := NewClustersAPI(ctx, m).Events(request)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			result := []interface{}{}
+			for _, event := range events {
+				item := map[string]interface{}{
+					"timestamp":           int(event.Timestamp),
+					"type":                string(event.Type),
+					"user":                event.Details.User,
+					"current_num_workers": int(event.Details.CurrentNumWorkers),
+					"target_num_workers":  int(event.Details.TargetNumWorkers),
+				}
+				if event.Details.Reason != nil {
+					item["reason_code"] = event.Details.Reason.Code
+					item["reason_type"] = event.Details.Reason.Type
+					item["reason_parameters"] = event.Details.Reason.Parameters
+				}
+				result = append(result, item)
+			}
+			if err = d.Set("events", result); err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(clusterID)
+			return nil
+		},
+	}
+}
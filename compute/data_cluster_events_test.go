@@ -0,0 +1,95 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSourceClusterEvents(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				ExpectedRequest: EventsRequest{
+					ClusterID:  "abc",
+					StartTime:  1600000000000,
+					Order:      SortDescending,
+					EventTypes: []ClusterEventType{"INIT_SCRIPTS_FINISHED"},
+					Limit:      2,
+				},
+				Response: EventsResponse{
+					Events: []ClusterEvent{
+						{
+							ClusterID: "abc",
+							Timestamp: 1600000002000,
+							Type:      EvTypeInitScriptsFinished,
+						},
+						{
+							ClusterID: "abc",
+							Timestamp: 1600000001000,
+							Type:      EvTypeInitScriptsFinished,
+							Details: EventDetails{
+								User: "me@example.com",
+								Reason: &TerminationReason{
+									Code: "INIT_SCRIPT_FAILURE",
+									Type: "CLIENT_ERROR",
+									Parameters: map[string]string{
+										"instance_id": "i-123",
+									},
+								},
+							},
+						},
+					},
+					TotalCount: 5,
+					NextPage: &EventsRequest{
+						ClusterID: "abc",
+						Offset:    2,
+					},
+				},
+			},
+		},
+		Resource:    DataSourceClusterEvents(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL: `
+		cluster_id = "abc"
+		event_types = ["INIT_SCRIPTS_FINISHED"]
+		start_time = 1600000000000
+		max_items = 2
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, 2, d.Get("events.#"))
+	assert.Equal(t, 1600000002000, d.Get("events.0.timestamp"))
+	assert.Equal(t, "INIT_SCRIPTS_FINISHED", d.Get("events.1.type"))
+	assert.Equal(t, "me@example.com", d.Get("events.1.user"))
+	assert.Equal(t, "INIT_SCRIPT_FAILURE", d.Get("events.1.reason_code"))
+	assert.Equal(t, "i-123", d.Get("events.1.reason_parameters.instance_id"))
+}
+
+func TestDataSourceClusterEvents_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Cluster abc does not exist",
+				},
+			},
+		},
+		Resource:    DataSourceClusterEvents(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_id = "abc"`,
+	}.ExpectError(t, "Cluster abc does not exist")
+}
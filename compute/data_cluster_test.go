@@ -0,0 +1,142 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+var sharedCluster = ClusterInfo{
+	ClusterID:              "abc",
+	ClusterName:            "Shared Autoscaling",
+	SparkVersion:           "9.1.x-scala2.12",
+	NodeTypeID:             "i3.xlarge",
+	DriverNodeTypeID:       "i3.2xlarge",
+	AutoterminationMinutes: 30,
+	State:                  ClusterStateRunning,
+	CustomTags: map[string]string{
+		"Team": "data-engineering",
+	},
+	DefaultTags: map[string]string{
+		"Vendor": "Databricks",
+	},
+}
+
+func TestDataSourceCluster_ByID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: sharedCluster,
+			},
+		},
+		Resource:    DataSourceCluster(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_id = "abc"`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "Shared Autoscaling", d.Get("cluster_name"))
+	assert.Equal(t, "9.1.x-scala2.12", d.Get("spark_version"))
+	assert.Equal(t, "i3.2xlarge", d.Get("driver_node_type_id"))
+	assert.Equal(t, "RUNNING", d.Get("state"))
+	assert.Equal(t, 30, d.Get("autotermination_minutes"))
+	assert.Equal(t, "data-engineering", d.Get("custom_tags.Team"))
+	assert.Equal(t, "Databricks", d.Get("default_tags.Vendor"))
+}
+
+func TestDataSourceCluster_ByName(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list",
+				Response: ClusterList{
+					Clusters: []ClusterInfo{
+						{
+							ClusterID:     "def",
+							ClusterName:   "Shared Autoscaling",
+							ClusterSource: "JOB",
+						},
+						{
+							ClusterID:   "ghi",
+							ClusterName: "Other",
+						},
+						sharedCluster,
+					},
+				},
+			},
+		},
+		Resource:    DataSourceCluster(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_name = "Shared Autoscaling"`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "abc", d.Get("cluster_id"))
+	assert.Equal(t, "i3.xlarge", d.Get("node_type_id"))
+}
+
+func TestDataSourceCluster_ByNameNotFound(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list",
+				Response: ClusterList{},
+			},
+		},
+		Resource:    DataSourceCluster(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_name = "Shared Autoscaling"`,
+	}.ExpectError(t, "there is no cluster named 'Shared Autoscaling'")
+}
+
+func TestDataSourceCluster_ByNameDuplicates(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list",
+				Response: ClusterList{
+					Clusters: []ClusterInfo{sharedCluster, sharedCluster},
+				},
+			},
+		},
+		Resource:    DataSourceCluster(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_name = "Shared Autoscaling"`,
+	}.ExpectError(t, "there are 2 clusters named 'Shared Autoscaling', use cluster_id instead")
+}
+
+func TestDataSourceCluster_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Cluster abc does not exist",
+				},
+			},
+		},
+		Resource:    DataSourceCluster(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `cluster_id = "abc"`,
+	}.ExpectError(t, "Cluster abc does not exist")
+}
@@ -0,0 +1,76 @@
+package compute
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// DataSourceClusters returns IDs of all clusters, optionally filtered by part of the name,
+// creator or state
+func DataSourceClusters() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster_name_contains": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"creator_user_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(ClusterStatePending),
+					string(ClusterStateRunning),
+					string(ClusterStateRestarting),
+					string(ClusterStateResizing),
+					string(ClusterStateTerminating),
+					string(ClusterStateTerminated),
+					string(ClusterStateError),
+					string(ClusterStateUnknown),
+				}, false),
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			clusters, err := NewClustersAPI(ctx, m).List()
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			nameContains := strings.ToLower(d.Get("cluster_name_contains").(string))
+			creator := d.Get("creator_user_name").(string)
+			state := ClusterState(d.Get("state").(string))
+			ids := []string{}
+			for _, cluster := range clusters {
+				if nameContains != "" && !strings.Contains(strings.ToLower(cluster.ClusterName), nameContains) {
+					continue
+				}
+				if creator != "" && cluster.CreatorUserName != creator {
+					continue
+				}
+				if state != "" && cluster.State != state {
+					continue
+				}
+				ids = append(ids, cluster.ClusterID)
+			}
+			sort.Strings(ids)
+			if err = d.Set("ids", ids); err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(strings.Join([]string{nameContains, creator, string(state)}, "|"))
+			return nil
+		},
+	}
+}
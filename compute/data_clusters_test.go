@@ -0,0 +1,95 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+var clustersFixture = qa.HTTPFixture{
+	Method:   "GET",
+	Resource: "/api/2.0/clusters/list",
+	Response: ClusterList{
+		Clusters: []ClusterInfo{
+			{
+				ClusterID:       "c",
+				ClusterName:     "Shared Autoscaling",
+				CreatorUserName: "admin@example.com",
+				State:           ClusterStateRunning,
+			},
+			{
+				ClusterID:       "b",
+				ClusterName:     "Shared Pool",
+				CreatorUserName: "me@example.com",
+				State:           ClusterStateTerminated,
+			},
+			{
+				ClusterID:       "a",
+				ClusterName:     "Personal",
+				CreatorUserName: "me@example.com",
+				State:           ClusterStateRunning,
+			},
+		},
+	},
+}
+
+func TestDataSourceClusters_All(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures:    []qa.HTTPFixture{clustersFixture},
+		Resource:    DataSourceClusters(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, d.Get("ids"))
+}
+
+func TestDataSourceClusters_Filters(t *testing.T) {
+	for _, c := range []struct {
+		hcl string
+		ids []interface{}
+	}{
+		{`cluster_name_contains = "shared"`, []interface{}{"b", "c"}},
+		{`creator_user_name = "me@example.com"`, []interface{}{"a", "b"}},
+		{`state = "RUNNING"`, []interface{}{"a", "c"}},
+		{`
+		cluster_name_contains = "Shared"
+		state = "RUNNING"
+		`, []interface{}{"c"}},
+		{`creator_user_name = "nobody@example.com"`, []interface{}{}},
+	} {
+		d, err := qa.ResourceFixture{
+			Fixtures:    []qa.HTTPFixture{clustersFixture},
+			Resource:    DataSourceClusters(),
+			Read:        true,
+			NonWritable: true,
+			ID:          "_",
+			HCL:         c.hcl,
+		}.Apply(t)
+		assert.NoError(t, err, err)
+		assert.Equal(t, c.ids, d.Get("ids"), c.hcl)
+	}
+}
+
+func TestDataSourceClusters_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_REQUEST",
+					Message:   "Nope",
+				},
+			},
+		},
+		Resource:    DataSourceClusters(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+	}.ExpectError(t, "Nope")
+}
@@ -0,0 +1,74 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// findPoolByName returns the only instance pool with the given name
+func (a InstancePoolsAPI) findPoolByName(name string) (InstancePoolAndStats, error) {
+	pools, err := a.List()
+	if err != nil {
+		return InstancePoolAndStats{}, err
+	}
+	found := []InstancePoolAndStats{}
+	for _, pool := range pools.InstancePools {
+		if pool.InstancePoolName == name {
+			found = append(found, pool)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return InstancePoolAndStats{}, fmt.Errorf("there is no instance pool named '%s'", name)
+	case 1:
+		return found[0], nil
+	}
+	return InstancePoolAndStats{}, fmt.Errorf("there are %d instance pools named '%s'", len(found), name)
+}
+
+// DataSourceInstancePool looks up an existing instance pool by name, so that job clusters
+// could use centrally managed pools without hardcoded IDs
+func DataSourceInstancePool() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"instance_pool_id":                      computedSchema(schema.TypeString),
+			"node_type_id":                          computedSchema(schema.TypeString),
+			"preloaded_spark_versions":              computedSchema(schema.TypeList),
+			"min_idle_instances":                    computedSchema(schema.TypeInt),
+			"max_capacity":                          computedSchema(schema.TypeInt),
+			"idle_instance_autotermination_minutes": computedSchema(schema.TypeInt),
+			"state":                                 computedSchema(schema.TypeString),
+			"custom_tags":                           computedSchema(schema.TypeMap),
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			pool, err := NewInstancePoolsAPI(ctx, m).findPoolByName(d.Get("name").(string))
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(pool.InstancePoolID)
+			for k, v := range map[string]interface{}{
+				"instance_pool_id":                      pool.InstancePoolID,
+				"node_type_id":                          pool.NodeTypeID,
+				"preloaded_spark_versions":              pool.PreloadedSparkVersions,
+				"min_idle_instances":                    pool.MinIdleInstances,
+				"max_capacity":                          pool.MaxCapacity,
+				"idle_instance_autotermination_minutes": pool.IdleInstanceAutoTerminationMinutes,
+				"state":                                 string(pool.State),
+				"custom_tags":                           pool.CustomTags,
+			} {
+				if err = d.Set(k, v); err != nil {
+					return common.DiagFromErr(err)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+var sharedPools = InstancePoolList{
+	InstancePools: []InstancePoolAndStats{
+		{
+			InstancePoolID:                     "abc",
+			InstancePoolName:                   "Shared Pool",
+			NodeTypeID:                         "i3.xlarge",
+			MinIdleInstances:                   2,
+			MaxCapacity:                        100,
+			IdleInstanceAutoTerminationMinutes: 15,
+			PreloadedSparkVersions:             []string{"9.1.x-scala2.12"},
+			State:                              "ACTIVE",
+			CustomTags: map[string]string{
+				"Team": "data-engineering",
+			},
+		},
+		{
+			InstancePoolID:   "def",
+			InstancePoolName: "Team Pool",
+			NodeTypeID:       "m5.large",
+		},
+		{
+			InstancePoolID:   "ghi",
+			InstancePoolName: "Team Pool",
+			NodeTypeID:       "m5.large",
+		},
+	},
+}
+
+func TestDataSourceInstancePool(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/list",
+				Response: sharedPools,
+			},
+		},
+		Resource:    DataSourceInstancePool(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Shared Pool"`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "abc", d.Get("instance_pool_id"))
+	assert.Equal(t, "i3.xlarge", d.Get("node_type_id"))
+	assert.Equal(t, "9.1.x-scala2.12", d.Get("preloaded_spark_versions.0"))
+	assert.Equal(t, 2, d.Get("min_idle_instances"))
+	assert.Equal(t, 100, d.Get("max_capacity"))
+	assert.Equal(t, 15, d.Get("idle_instance_autotermination_minutes"))
+	assert.Equal(t, "ACTIVE", d.Get("state"))
+	assert.Equal(t, "data-engineering", d.Get("custom_tags.Team"))
+}
+
+func TestDataSourceInstancePool_NotFound(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/list",
+				Response: sharedPools,
+			},
+		},
+		Resource:    DataSourceInstancePool(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Unknown"`,
+	}.ExpectError(t, "there is no instance pool named 'Unknown'")
+}
+
+func TestDataSourceInstancePool_Duplicates(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/list",
+				Response: sharedPools,
+			},
+		},
+		Resource:    DataSourceInstancePool(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Team Pool"`,
+	}.ExpectError(t, "there are 2 instance pools named 'Team Pool'")
+}
+
+func TestDataSourceInstancePool_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/list",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_REQUEST",
+					Message:   "Nope",
+				},
+			},
+		},
+		Resource:    DataSourceInstancePool(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Shared Pool"`,
+	}.ExpectError(t, "Nope")
+}
@@ -109,3 +109,72 @@ func TestNodeType(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Random_03", d.Id())
 }
+
+func TestNodeType_GravitonFleetAndNVMe(t *testing.T) {
+	nodeTypes := NodeTypeList{
+		[]NodeType{
+			{
+				NodeTypeID:     "m5d.large",
+				InstanceTypeID: "m5d.large",
+				MemoryMB:       8192,
+				NumCores:       2,
+				NodeInstanceType: &NodeInstanceType{
+					LocalNVMeDisks: 1,
+				},
+			},
+			{
+				NodeTypeID:     "m5d-fleet.large",
+				InstanceTypeID: "m5d.large",
+				MemoryMB:       8192,
+				NumCores:       2,
+				NodeInstanceType: &NodeInstanceType{
+					LocalNVMeDisks: 1,
+				},
+			},
+			{
+				NodeTypeID:     "m6gd.large",
+				InstanceTypeID: "m6gd.large",
+				MemoryMB:       8192,
+				NumCores:       2,
+				IsGraviton:     true,
+				NodeInstanceType: &NodeInstanceType{
+					LocalNVMeDisks: 1,
+				},
+			},
+			{
+				NodeTypeID:     "m5.large",
+				InstanceTypeID: "m5.large",
+				MemoryMB:       8192,
+				NumCores:       2,
+			},
+		},
+	}
+	for _, c := range []struct {
+		state    map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{}, "m5.large"},
+		{map[string]interface{}{"local_nvme_disk": true}, "m5d-fleet.large"},
+		{map[string]interface{}{"fleet": true}, "m5d-fleet.large"},
+		{map[string]interface{}{"graviton": true}, "m6gd.large"},
+		{map[string]interface{}{"graviton": true, "fleet": true}, "i3.xlarge"},
+	} {
+		d, err := qa.ResourceFixture{
+			Fixtures: []qa.HTTPFixture{
+				{
+					Method:       "GET",
+					ReuseRequest: true,
+					Resource:     "/api/2.0/clusters/list-node-types",
+					Response:     nodeTypes,
+				},
+			},
+			Read:        true,
+			Resource:    DataSourceNodeType(),
+			NonWritable: true,
+			State:       c.state,
+			ID:          ".",
+		}.Apply(t)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, d.Id(), c.state)
+	}
+}
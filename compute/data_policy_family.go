@@ -0,0 +1,103 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// GetPolicyFamily returns policy family by its ID
+func (a ClusterPoliciesAPI) GetPolicyFamily(familyID string) (family PolicyFamily, err error) {
+	err = a.client.Get(a.context, "/policy-families/"+familyID, nil, &family)
+	return
+}
+
+// ListPolicyFamilies returns all policy families available in the workspace
+func (a ClusterPoliciesAPI) ListPolicyFamilies() ([]PolicyFamily, error) {
+	families := []PolicyFamily{}
+	request := struct {
+		MaxResults int    `url:"max_results"`
+		PageToken  string `url:"page_token,omitempty"`
+	}{MaxResults: 100}
+	for {
+		var page PolicyFamilyList
+		err := a.client.Get(a.context, "/policy-families", request, &page)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, page.PolicyFamilies...)
+		if page.NextPageToken == "" {
+			return families, nil
+		}
+		request.PageToken = page.NextPageToken
+	}
+}
+
+func (a ClusterPoliciesAPI) findPolicyFamilyByName(name string) (PolicyFamily, error) {
+	families, err := a.ListPolicyFamilies()
+	if err != nil {
+		return PolicyFamily{}, err
+	}
+	for _, family := range families {
+		if family.Name == name {
+			return family, nil
+		}
+	}
+	return PolicyFamily{}, fmt.Errorf("there is no policy family named '%s'", name)
+}
+
+// DataSourcePolicyFamily looks up policy family by ID or name, so that policy_family_id
+// of databricks_cluster_policy doesn't have to be copied from the UI
+func DataSourcePolicyFamily() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"policy_family_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"policy_family_id", "name"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"definition": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			policies := NewClusterPoliciesAPI(ctx, m)
+			var family PolicyFamily
+			var err error
+			if familyID, ok := d.GetOk("policy_family_id"); ok {
+				family, err = policies.GetPolicyFamily(familyID.(string))
+			} else {
+				family, err = policies.findPolicyFamilyByName(d.Get("name").(string))
+			}
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(family.PolicyFamilyID)
+			for k, v := range map[string]interface{}{
+				"policy_family_id": family.PolicyFamilyID,
+				"name":             family.Name,
+				"description":      family.Description,
+				"definition":       family.Definition,
+			} {
+				if err = d.Set(k, v); err != nil {
+					return common.DiagFromErr(err)
+				}
+			}
+			return nil
+		},
+	}
+}
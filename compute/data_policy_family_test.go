@@ -0,0 +1,113 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+var personalVM = PolicyFamily{
+	PolicyFamilyID: "personal-vm",
+	Name:           "Personal Compute",
+	Description:    "Use with small-to-medium data or libraries like pandas and scikit-learn.",
+	Definition:     `{"spark_conf.spark.databricks.cluster.profile":{"type":"fixed","value":"singleNode"}}`,
+}
+
+func TestDataSourcePolicyFamily_ByID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policy-families/personal-vm",
+				Response: personalVM,
+			},
+		},
+		Resource:    DataSourcePolicyFamily(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `policy_family_id = "personal-vm"`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "personal-vm", d.Id())
+	assert.Equal(t, "Personal Compute", d.Get("name"))
+	assert.Equal(t, personalVM.Definition, d.Get("definition"))
+}
+
+func TestDataSourcePolicyFamily_ByName(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policy-families?max_results=100",
+				Response: PolicyFamilyList{
+					PolicyFamilies: []PolicyFamily{
+						{
+							PolicyFamilyID: "job-cluster",
+							Name:           "Job Compute",
+						},
+					},
+					NextPageToken: "next",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policy-families?max_results=100&page_token=next",
+				Response: PolicyFamilyList{
+					PolicyFamilies: []PolicyFamily{personalVM},
+				},
+			},
+		},
+		Resource:    DataSourcePolicyFamily(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Personal Compute"`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "personal-vm", d.Id())
+	assert.Equal(t, "personal-vm", d.Get("policy_family_id"))
+	assert.Equal(t, personalVM.Description, d.Get("description"))
+}
+
+func TestDataSourcePolicyFamily_NameNotFound(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policy-families?max_results=100",
+				Response: PolicyFamilyList{
+					PolicyFamilies: []PolicyFamily{personalVM},
+				},
+			},
+		},
+		Resource:    DataSourcePolicyFamily(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `name = "Power User Compute"`,
+	}.ExpectError(t, "there is no policy family named 'Power User Compute'")
+}
+
+func TestDataSourcePolicyFamily_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policy-families/personal-vm",
+				Status:   404,
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_DOES_NOT_EXIST",
+					Message:   "Policy family personal-vm does not exist",
+				},
+			},
+		},
+		Resource:    DataSourcePolicyFamily(),
+		Read:        true,
+		NonWritable: true,
+		ID:          "_",
+		HCL:         `policy_family_id = "personal-vm"`,
+	}.ExpectError(t, "Policy family personal-vm does not exist")
+}
@@ -21,11 +21,11 @@ func DataSourceSparkVersion() *schema.Resource {
 			var this SparkVersionRequest
 			err := common.DataToStructPointer(d, s, &this)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			version, err := NewClustersAPI(ctx, m).LatestSparkVersion(this)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.SetId(version)
 			return nil
@@ -194,3 +194,68 @@ func TestSparkVersionErrorBadAnswer(t *testing.T) {
 	assert.Error(t, err)
 	require.Equal(t, true, strings.Contains(err.Error(), "Invalid JSON received"))
 }
+
+func TestSparkVersionPhotonAndDoubleDigitMajor(t *testing.T) {
+	fixtures := []qa.HTTPFixture{
+		{
+			Method:       "GET",
+			Resource:     "/api/2.0/clusters/spark-versions",
+			ReuseRequest: true,
+			Response: SparkVersionsList{
+				SparkVersions: []SparkVersion{
+					{
+						Version:     "9.1.x-scala2.12",
+						Description: "9.1 LTS (includes Apache Spark 3.1.2, Scala 2.12)",
+					},
+					{
+						Version:     "10.4.x-scala2.12",
+						Description: "10.4 LTS (includes Apache Spark 3.2.1, Scala 2.12)",
+					},
+					{
+						Version:     "10.4.x-photon-scala2.12",
+						Description: "10.4 LTS Photon (includes Apache Spark 3.2.1, Scala 2.12)",
+					},
+					{
+						Version:     "9.1.x-photon-scala2.12",
+						Description: "9.1 LTS Photon (includes Apache Spark 3.1.2, Scala 2.12)",
+					},
+					{
+						Version:     "10.4.x-cpu-ml-scala2.12",
+						Description: "10.4 LTS ML (includes Apache Spark 3.2.1, Scala 2.12)",
+					},
+					{
+						Version:     "9.1.x-cpu-ml-scala2.12",
+						Description: "9.1 LTS ML (includes Apache Spark 3.1.2, Scala 2.12)",
+					},
+				},
+			},
+		},
+	}
+	for _, c := range []struct {
+		state    map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"long_term_support": true}, "10.4.x-scala2.12"},
+		{map[string]interface{}{"photon": true}, "10.4.x-photon-scala2.12"},
+		{map[string]interface{}{"ml": true, "long_term_support": true}, "10.4.x-cpu-ml-scala2.12"},
+		{map[string]interface{}{"photon": true, "spark_version": "3.1"}, "9.1.x-photon-scala2.12"},
+	} {
+		d, err := qa.ResourceFixture{
+			Fixtures:    fixtures,
+			Read:        true,
+			Resource:    DataSourceSparkVersion(),
+			NonWritable: true,
+			State:       c.state,
+			ID:          ".",
+		}.Apply(t)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, d.Id(), c.state)
+	}
+}
+
+func TestNewerRuntime(t *testing.T) {
+	assert.True(t, newerRuntime("10.4.x-scala2.12", "9.1.x-scala2.12"))
+	assert.True(t, newerRuntime("10.10.x-scala2.12", "10.4.x-scala2.12"))
+	assert.False(t, newerRuntime("7.3.x-scala2.12", "7.4.x-scala2.12"))
+	assert.True(t, newerRuntime("11.0.x-scala2.12", "11.0.x-scala2.11"))
+}
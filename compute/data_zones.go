@@ -3,6 +3,7 @@ package compute
 import (
 	"context"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -13,14 +14,14 @@ func DataSourceClusterZones() *schema.Resource {
 		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 			zonesInfo, err := NewClustersAPI(ctx, m).ListZones()
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.SetId(zonesInfo.DefaultZone)
 			if err = d.Set("default_zone", zonesInfo.DefaultZone); err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			if err = d.Set("zones", zonesInfo.Zones); err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			return nil
 		},
@@ -3,10 +3,14 @@ package compute
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 // NewLibrariesAPI creates LibrariesAPI instance from provider meta
@@ -40,6 +44,84 @@ func (a LibrariesAPI) ClusterStatus(clusterID string) (cls ClusterLibraryStatuse
 	return
 }
 
+// waitForLibraryInstalled waits only for the library with the given key, so that statuses
+// of other libraries on the same cluster do not affect it
+func (a LibrariesAPI) waitForLibraryInstalled(clusterID, key string) error {
+	_, err := a.waitForLibraries(clusterID, key, true)
+	return err
+}
+
+// waitForLibraries waits until libraries of the cluster are installed, or only the library with
+// the given key, if it's not empty. Unless wait is true, the statuses are returned right away.
+// Installation is lost if the cluster restarts in the middle of it, which leaves libraries
+// PENDING forever. Their installation is requested again once the cluster is running, but only
+// if it was seen running before, with different restart time or in another state. Libraries
+// of a terminated cluster are installed on its next start, so waiting stops.
+func (a LibrariesAPI) waitForLibraries(clusterID, key string, wait bool) (result ClusterLibraryStatuses, err error) {
+	clusters := NewClustersAPI(a.context, a.client)
+	restarted, observed := false, false
+	var lastRestarted int64
+	timeout := common.OperationTimeout(a.context, 30*time.Minute)
+	err = resource.RetryContext(a.context, timeout, func() *resource.RetryError {
+		cls, err := a.ClusterStatus(clusterID)
+		if common.IsMissing(err) {
+			// eventual consistency error
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		result = cls
+		if key != "" {
+			status, ok := cls.Find(key)
+			if !ok {
+				return resource.RetryableError(fmt.Errorf("library %s is not yet listed on cluster %s", key, clusterID))
+			}
+			result.LibraryStatuses = []LibraryStatus{status}
+		}
+		if !wait {
+			return nil
+		}
+		retry, pendingErr := result.IsRetryNeeded()
+		if !retry {
+			if pendingErr != nil {
+				return resource.NonRetryableError(pendingErr)
+			}
+			return nil
+		}
+		clusterInfo, err := clusters.Get(clusterID)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		switch clusterInfo.State {
+		case ClusterStateTerminating, ClusterStateTerminated, ClusterStateError:
+			log.Printf("[INFO] Cluster %s is %s, so pending libraries are installed on its next start",
+				clusterID, clusterInfo.State)
+			return nil
+		}
+		if !clusterInfo.IsRunningOrResizing() {
+			// cluster that is still starting for the first time hasn't lost anything
+			restarted = restarted || observed
+			return resource.RetryableError(fmt.Errorf("cluster %s is %s: %w", clusterID, clusterInfo.State, pendingErr))
+		}
+		if observed && clusterInfo.LastRestartedTime != lastRestarted {
+			restarted = true
+		}
+		observed, lastRestarted = true, clusterInfo.LastRestartedTime
+		if restarted {
+			restarted = false
+			pending := result.pendingLibraries()
+			log.Printf("[INFO] Cluster %s was restarted, requesting installation of %d pending libraries again",
+				clusterID, len(pending))
+			if err = a.Install(ClusterLibraryList{ClusterID: clusterID, Libraries: pending}); err != nil {
+				return resource.NonRetryableError(err)
+			}
+		}
+		return resource.RetryableError(pendingErr)
+	})
+	return
+}
+
 // Library is a construct that contains information of the location of the library and how to download it
 type Library struct { // TODO: discuss if we can make a dedicated entity just for terraform...
 	Jar string `json:"jar,omitempty" tf:"group:lib"`
@@ -125,6 +207,33 @@ type ClusterLibraryStatuses struct {
 	LibraryStatuses []LibraryStatus `json:"library_statuses,omitempty"`
 }
 
+// Find returns status of the library with the given key
+func (cls ClusterLibraryStatuses) Find(key string) (LibraryStatus, bool) {
+	for _, status := range cls.LibraryStatuses {
+		if status.Library == nil {
+			continue
+		}
+		if _, k := status.Library.TypeAndKey(); k == key {
+			return status, true
+		}
+	}
+	return LibraryStatus{}, false
+}
+
+// pendingLibraries returns libraries, that are not yet installed
+func (cls ClusterLibraryStatuses) pendingLibraries() (pending []Library) {
+	for _, status := range cls.LibraryStatuses {
+		if status.Library == nil {
+			continue
+		}
+		switch status.Status {
+		case "PENDING", "RESOLVING", "INSTALLING":
+			pending = append(pending, *status.Library)
+		}
+	}
+	return
+}
+
 // ToLibraryList convert to envity for convenient comparison
 func (cls ClusterLibraryStatuses) ToLibraryList() ClusterLibraryList {
 	cll := ClusterLibraryList{ClusterID: cls.ClusterID}
@@ -139,6 +248,15 @@ func (cls ClusterLibraryStatuses) ToLibraryList() ClusterLibraryList {
 	return cll
 }
 
+// failedLibraryHint explains how to fix libraries in FAILED state, as they are not retried
+func failedLibraryHint(libraryType string) string {
+	switch libraryType {
+	case "library_pypi", "library_maven", "library_cran":
+		return "Check that the package exists and that the cluster can reach its repository"
+	}
+	return "Check that the file exists and that the cluster can read it"
+}
+
 // IsRetryNeeded returns first bool if there needs to be retry.
 // If there needs to be retry, error message will explain why.
 // If retry does not need to happen and error is not nil - it failed.
@@ -173,7 +291,8 @@ func (cls ClusterLibraryStatuses) IsRetryNeeded() (bool, error) {
 			//Some step in installation failed. More information can be found in the messages field.
 		case "FAILED":
 			libraryType, key := lib.Library.TypeAndKey()
-			errors = append(errors, fmt.Sprintf("%s[%s] failed: %s", libraryType, key, strings.Join(lib.Messages, ", ")))
+			errors = append(errors, fmt.Sprintf("%s[%s] failed: %s. %s", libraryType, key,
+				strings.Join(lib.Messages, ", "), failedLibraryHint(libraryType)))
 			continue
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,10 +99,50 @@ func TestClusterLibraryStatuses_Errors(t *testing.T) {
 		},
 	}.IsRetryNeeded()
 	require.Error(t, err)
-	assert.Equal(t, "library_whl[a] failed: b\nlibrary_maven[a.b.c] failed: b\nlibrary_cran[a] failed: b", err.Error())
+	assert.Equal(t, "library_whl[a] failed: b. Check that the file exists and that the cluster can read it\n"+
+		"library_maven[a.b.c] failed: b. Check that the package exists and that the cluster can reach its repository\n"+
+		"library_cran[a] failed: b. Check that the package exists and that the cluster can reach its repository",
+		err.Error())
 	assert.False(t, need)
 }
 
+func TestWaitForLibraries_ColdStartIsNotRestart(t *testing.T) {
+	clusterIn := func(state ClusterState, lastRestarted int64) qa.HTTPFixture {
+		return qa.HTTPFixture{
+			Method:   "GET",
+			Resource: "/api/2.0/clusters/get?cluster_id=abc",
+			Response: ClusterInfo{
+				ClusterID:         "abc",
+				State:             state,
+				LastRestartedTime: lastRestarted,
+			},
+		}
+	}
+	pending := qa.HTTPFixture{
+		Method:   "GET",
+		Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+		Response: libraryStatuses("PENDING"),
+	}
+	// there's no fixture for libraries/install, so any re-install fails the test
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		pending,
+		clusterIn(ClusterStatePending, 0),
+		pending,
+		clusterIn(ClusterStatePending, 0),
+		pending,
+		clusterIn(ClusterStateRunning, 1637002526000),
+		{
+			Method:       "GET",
+			Resource:     "/api/2.0/libraries/cluster-status?cluster_id=abc",
+			Response:     libraryStatuses("INSTALLED"),
+			ReuseRequest: true,
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewLibrariesAPI(ctx, client).waitForLibraryInstalled("abc", "requests")
+		assert.NoError(t, err)
+	})
+}
+
 func TestAccLibraryCreate(t *testing.T) {
 	cloud := os.Getenv("CLOUD_ENV")
 	if cloud == "" {
@@ -37,6 +37,41 @@ const (
 	AzureAvailabilitySpotWithFallback = "SPOT_WITH_FALLBACK_AZURE"
 )
 
+// https://docs.gcp.databricks.com/dev-tools/api/latest/clusters.html#gcpavailability
+const (
+	// GcpAvailabilityPreemptible is Preemptible instance type for clusters
+	GcpAvailabilityPreemptible = "PREEMPTIBLE_GCP"
+	// GcpAvailabilityOnDemand is OnDemand instance type for clusters
+	GcpAvailabilityOnDemand = "ON_DEMAND_GCP"
+	// GcpAvailabilityPreemptibleWithFallback is Preemptible instance type for clusters with option
+	// to fallback into on-demand if instance cannot be acquired
+	GcpAvailabilityPreemptibleWithFallback = "PREEMPTIBLE_WITH_FALLBACK_GCP"
+)
+
+// https://docs.databricks.com/dev-tools/api/latest/clusters.html#runtimeengine
+const (
+	// RuntimeEnginePhoton runs the cluster with Photon vectorized query engine
+	RuntimeEnginePhoton = "PHOTON"
+	// RuntimeEngineStandard runs the cluster without Photon
+	RuntimeEngineStandard = "STANDARD"
+)
+
+// https://docs.databricks.com/dev-tools/api/latest/clusters.html#datasecuritymode
+const (
+	// DataSecurityModeNone disables data governance features of the cluster
+	DataSecurityModeNone = "NONE"
+	// DataSecurityModeSingleUser is Unity Catalog cluster, that is used only by single_user_name
+	DataSecurityModeSingleUser = "SINGLE_USER"
+	// DataSecurityModeUserIsolation is Unity Catalog cluster, that is shared by multiple users
+	DataSecurityModeUserIsolation = "USER_ISOLATION"
+	// DataSecurityModeLegacyTableACL is cluster with legacy table access control
+	DataSecurityModeLegacyTableACL = "LEGACY_TABLE_ACL"
+	// DataSecurityModeLegacyPassthrough is high concurrency cluster with credential passthrough
+	DataSecurityModeLegacyPassthrough = "LEGACY_PASSTHROUGH"
+	// DataSecurityModeLegacySingleUser is standard cluster with credential passthrough
+	DataSecurityModeLegacySingleUser = "LEGACY_SINGLE_USER"
+)
+
 // AzureDiskVolumeType is disk type on azure vms
 type AzureDiskVolumeType string
 
@@ -146,8 +181,11 @@ type AzureAttributes struct {
 // GcpAttributes encapsultes GCP specific attributes
 // https://docs.gcp.databricks.com/dev-tools/api/latest/clusters.html#clustergcpattributes
 type GcpAttributes struct {
-	UsePreemptibleExecutors bool   `json:"use_preemptible_executors,omitempty" tf:"computed"`
-	GoogleServiceAccount    string `json:"google_service_account,omitempty" tf:"computed"`
+	UsePreemptibleExecutors bool         `json:"use_preemptible_executors,omitempty" tf:"computed"`
+	GoogleServiceAccount    string       `json:"google_service_account,omitempty" tf:"computed"`
+	Availability            Availability `json:"availability,omitempty" tf:"computed"`
+	BootDiskSize            int32        `json:"boot_disk_size,omitempty" tf:"computed"`
+	LocalSsdCount           int32        `json:"local_ssd_count,omitempty" tf:"computed"`
 }
 
 // DbfsStorageInfo contains the destination string for DBFS
@@ -172,6 +210,26 @@ type LocalFileInfo struct {
 	Destination string `json:"destination,omitempty" tf:"optional"`
 }
 
+// WorkspaceFileInfo represents a file in the workspace, e.g. /Users/me@example.com/init.sh
+type WorkspaceFileInfo struct {
+	Destination string `json:"destination"`
+}
+
+// AbfssStorageInfo contains the destination on ADLS Gen2, e.g. abfss://container@account.dfs.core.windows.net/init.sh
+type AbfssStorageInfo struct {
+	Destination string `json:"destination"`
+}
+
+// GcsStorageInfo contains the destination on Google Cloud Storage, e.g. gs://bucket/init.sh
+type GcsStorageInfo struct {
+	Destination string `json:"destination"`
+}
+
+// VolumesStorageInfo contains the destination on Unity Catalog volume, e.g. /Volumes/main/default/scripts/init.sh
+type VolumesStorageInfo struct {
+	Destination string `json:"destination"`
+}
+
 // StorageInfo contains the struct for either DBFS or S3 storage depending on which one is relevant.
 type StorageInfo struct {
 	Dbfs *DbfsStorageInfo `json:"dbfs,omitempty" tf:"group:storage"`
@@ -180,9 +238,13 @@ type StorageInfo struct {
 
 // InitScriptStorageInfo captures the allowed sources of init scripts.
 type InitScriptStorageInfo struct {
-	Dbfs *DbfsStorageInfo `json:"dbfs,omitempty" tf:"group:storage"`
-	S3   *S3StorageInfo   `json:"s3,omitempty" tf:"group:storage"`
-	File *LocalFileInfo   `json:"file,omitempty" tf:"optional"`
+	Dbfs      *DbfsStorageInfo    `json:"dbfs,omitempty" tf:"group:storage"`
+	S3        *S3StorageInfo      `json:"s3,omitempty" tf:"group:storage"`
+	File      *LocalFileInfo      `json:"file,omitempty" tf:"optional"`
+	Workspace *WorkspaceFileInfo  `json:"workspace,omitempty" tf:"group:storage"`
+	Abfss     *AbfssStorageInfo   `json:"abfss,omitempty" tf:"group:storage"`
+	Gcs       *GcsStorageInfo     `json:"gcs,omitempty" tf:"group:storage"`
+	Volumes   *VolumesStorageInfo `json:"volumes,omitempty" tf:"group:storage"`
 }
 
 // SparkNodeAwsAttributes is the struct that determines if the node is a spot instance or not
@@ -250,6 +312,7 @@ type NodeType struct {
 	NodeInstanceType      *NodeInstanceType             `json:"node_instance_type,omitempty"`
 	PhotonWorkerCapable   bool                          `json:"photon_worker_capable,omitempty"`
 	PhotonDriverCapable   bool                          `json:"photon_driver_capable,omitempty"`
+	IsGraviton            bool                          `json:"is_graviton,omitempty"`
 }
 
 // DockerBasicAuth contains the auth information when fetching containers
@@ -264,12 +327,48 @@ type DockerImage struct {
 	BasicAuth *DockerBasicAuth `json:"basic_auth,omitempty"`
 }
 
+// keepCredentials restores registry credentials of the image from the known configuration,
+// as the API never returns the password and may omit basic_auth of images completely
+func (di *DockerImage) keepCredentials(known *DockerImage) {
+	if di == nil || known == nil || known.BasicAuth == nil || di.URL != known.URL {
+		return
+	}
+	if di.BasicAuth == nil {
+		di.BasicAuth = &DockerBasicAuth{Username: known.BasicAuth.Username}
+	}
+	if di.BasicAuth.Password == "" && di.BasicAuth.Username == known.BasicAuth.Username {
+		di.BasicAuth.Password = known.BasicAuth.Password
+	}
+}
+
+// keepDockerCredentials restores credentials of images with the same URL
+func keepDockerCredentials(images, known []DockerImage) {
+	for i := range images {
+		for j := range known {
+			images[i].keepCredentials(&known[j])
+		}
+	}
+}
+
+// WorkloadClients limits, which kinds of clients could attach to the cluster. Both flags
+// are always sent, as clients, that are not mentioned, are allowed by the API.
+type WorkloadClients struct {
+	Jobs      bool `json:"jobs"`
+	Notebooks bool `json:"notebooks"`
+}
+
+// WorkloadType restricts clusters to run only jobs or only notebooks
+type WorkloadType struct {
+	Clients *WorkloadClients `json:"clients"`
+}
+
 // Cluster contains the information when trying to submit api calls or editing a cluster
 type Cluster struct {
 	ClusterID   string `json:"cluster_id,omitempty"`
 	ClusterName string `json:"cluster_name,omitempty"`
 
 	SparkVersion              string     `json:"spark_version"` // TODO: perhaps make a default
+	RuntimeEngine             string     `json:"runtime_engine,omitempty"`
 	NumWorkers                int32      `json:"num_workers" tf:"group:size"`
 	Autoscale                 *AutoScale `json:"autoscale,omitempty" tf:"group:size"`
 	EnableElasticDisk         bool       `json:"enable_elastic_disk,omitempty" tf:"computed"`
@@ -293,8 +392,10 @@ type Cluster struct {
 	InitScripts    []InitScriptStorageInfo `json:"init_scripts,omitempty" tf:"max_items:10"` // TODO: tf:alias
 	ClusterLogConf *StorageInfo            `json:"cluster_log_conf,omitempty"`
 	DockerImage    *DockerImage            `json:"docker_image,omitempty"`
+	WorkloadType   *WorkloadType           `json:"workload_type,omitempty"`
 
 	SingleUserName   string `json:"single_user_name,omitempty"`
+	DataSecurityMode string `json:"data_security_mode,omitempty"`
 	IdempotencyToken string `json:"idempotency_token,omitempty"`
 }
 
@@ -305,47 +406,51 @@ type ClusterList struct {
 
 // ClusterInfo contains the information when getting cluster info from the get request.
 type ClusterInfo struct {
-	NumWorkers                int32              `json:"num_workers,omitempty"`
-	AutoScale                 *AutoScale         `json:"autoscale,omitempty"`
-	ClusterID                 string             `json:"cluster_id,omitempty"`
-	CreatorUserName           string             `json:"creator_user_name,omitempty"`
-	Driver                    *SparkNode         `json:"driver,omitempty"`
-	Executors                 []SparkNode        `json:"executors,omitempty"`
-	SparkContextID            int64              `json:"spark_context_id,omitempty"`
-	JdbcPort                  int32              `json:"jdbc_port,omitempty"`
-	ClusterName               string             `json:"cluster_name,omitempty"`
-	SparkVersion              string             `json:"spark_version"`
-	SparkConf                 map[string]string  `json:"spark_conf,omitempty"`
-	AwsAttributes             *AwsAttributes     `json:"aws_attributes,omitempty"`
-	AzureAttributes           *AzureAttributes   `json:"azure_attributes,omitempty"`
-	GcpAttributes             *GcpAttributes     `json:"gcp_attributes,omitempty"`
-	NodeTypeID                string             `json:"node_type_id,omitempty"`
-	DriverNodeTypeID          string             `json:"driver_node_type_id,omitempty"`
-	SSHPublicKeys             []string           `json:"ssh_public_keys,omitempty"`
-	CustomTags                map[string]string  `json:"custom_tags,omitempty"`
-	ClusterLogConf            *StorageInfo       `json:"cluster_log_conf,omitempty"`
-	InitScripts               []StorageInfo      `json:"init_scripts,omitempty"`
-	SparkEnvVars              map[string]string  `json:"spark_env_vars,omitempty"`
-	AutoterminationMinutes    int32              `json:"autotermination_minutes,omitempty"`
-	EnableElasticDisk         bool               `json:"enable_elastic_disk,omitempty"`
-	EnableLocalDiskEncryption bool               `json:"enable_local_disk_encryption,omitempty"`
-	InstancePoolID            string             `json:"instance_pool_id,omitempty"`
-	DriverInstancePoolID      string             `json:"driver_instance_pool_id,omitempty" tf:"computed"`
-	PolicyID                  string             `json:"policy_id,omitempty"`
-	SingleUserName            string             `json:"single_user_name,omitempty"`
-	ClusterSource             Availability       `json:"cluster_source,omitempty"`
-	DockerImage               *DockerImage       `json:"docker_image,omitempty"`
-	State                     ClusterState       `json:"state"`
-	StateMessage              string             `json:"state_message,omitempty"`
-	StartTime                 int64              `json:"start_time,omitempty"`
-	TerminateTime             int64              `json:"terminate_time,omitempty"`
-	LastStateLossTime         int64              `json:"last_state_loss_time,omitempty"`
-	LastActivityTime          int64              `json:"last_activity_time,omitempty"`
-	ClusterMemoryMb           int64              `json:"cluster_memory_mb,omitempty"`
-	ClusterCores              float32            `json:"cluster_cores,omitempty"`
-	DefaultTags               map[string]string  `json:"default_tags"`
-	ClusterLogStatus          *LogSyncStatus     `json:"cluster_log_status,omitempty"`
-	TerminationReason         *TerminationReason `json:"termination_reason,omitempty"`
+	NumWorkers                int32                   `json:"num_workers,omitempty"`
+	AutoScale                 *AutoScale              `json:"autoscale,omitempty"`
+	ClusterID                 string                  `json:"cluster_id,omitempty"`
+	CreatorUserName           string                  `json:"creator_user_name,omitempty"`
+	Driver                    *SparkNode              `json:"driver,omitempty"`
+	Executors                 []SparkNode             `json:"executors,omitempty"`
+	SparkContextID            int64                   `json:"spark_context_id,omitempty"`
+	JdbcPort                  int32                   `json:"jdbc_port,omitempty"`
+	ClusterName               string                  `json:"cluster_name,omitempty"`
+	SparkVersion              string                  `json:"spark_version"`
+	RuntimeEngine             string                  `json:"runtime_engine,omitempty"`
+	SparkConf                 map[string]string       `json:"spark_conf,omitempty"`
+	AwsAttributes             *AwsAttributes          `json:"aws_attributes,omitempty"`
+	AzureAttributes           *AzureAttributes        `json:"azure_attributes,omitempty"`
+	GcpAttributes             *GcpAttributes          `json:"gcp_attributes,omitempty"`
+	NodeTypeID                string                  `json:"node_type_id,omitempty"`
+	DriverNodeTypeID          string                  `json:"driver_node_type_id,omitempty"`
+	SSHPublicKeys             []string                `json:"ssh_public_keys,omitempty"`
+	CustomTags                map[string]string       `json:"custom_tags,omitempty"`
+	ClusterLogConf            *StorageInfo            `json:"cluster_log_conf,omitempty"`
+	InitScripts               []InitScriptStorageInfo `json:"init_scripts,omitempty"`
+	SparkEnvVars              map[string]string       `json:"spark_env_vars,omitempty"`
+	AutoterminationMinutes    int32                   `json:"autotermination_minutes,omitempty"`
+	EnableElasticDisk         bool                    `json:"enable_elastic_disk,omitempty"`
+	EnableLocalDiskEncryption bool                    `json:"enable_local_disk_encryption,omitempty"`
+	InstancePoolID            string                  `json:"instance_pool_id,omitempty"`
+	DriverInstancePoolID      string                  `json:"driver_instance_pool_id,omitempty" tf:"computed"`
+	PolicyID                  string                  `json:"policy_id,omitempty"`
+	SingleUserName            string                  `json:"single_user_name,omitempty"`
+	DataSecurityMode          string                  `json:"data_security_mode,omitempty"`
+	ClusterSource             Availability            `json:"cluster_source,omitempty"`
+	DockerImage               *DockerImage            `json:"docker_image,omitempty"`
+	WorkloadType              *WorkloadType           `json:"workload_type,omitempty"`
+	State                     ClusterState            `json:"state"`
+	StateMessage              string                  `json:"state_message,omitempty"`
+	StartTime                 int64                   `json:"start_time,omitempty"`
+	TerminateTime             int64                   `json:"terminate_time,omitempty"`
+	LastStateLossTime         int64                   `json:"last_state_loss_time,omitempty"`
+	LastRestartedTime         int64                   `json:"last_restarted_time,omitempty"`
+	LastActivityTime          int64                   `json:"last_activity_time,omitempty"`
+	ClusterMemoryMb           int64                   `json:"cluster_memory_mb,omitempty"`
+	ClusterCores              float32                 `json:"cluster_cores,omitempty"`
+	DefaultTags               map[string]string       `json:"default_tags"`
+	ClusterLogStatus          *LogSyncStatus          `json:"cluster_log_status,omitempty"`
+	TerminationReason         *TerminationReason      `json:"termination_reason,omitempty"`
 }
 
 // IsRunningOrResizing returns true if cluster is running or resizing
@@ -360,10 +465,26 @@ type ClusterID struct {
 
 // ClusterPolicy defines cluster policy
 type ClusterPolicy struct {
-	PolicyID           string `json:"policy_id,omitempty"`
-	Name               string `json:"name"`
-	Definition         string `json:"definition"`
-	CreatedAtTimeStamp int64  `json:"created_at_timestamp"`
+	PolicyID                        string `json:"policy_id,omitempty"`
+	Name                            string `json:"name"`
+	Definition                      string `json:"definition,omitempty"`
+	PolicyFamilyID                  string `json:"policy_family_id,omitempty"`
+	PolicyFamilyDefinitionOverrides string `json:"policy_family_definition_overrides,omitempty"`
+	CreatedAtTimeStamp              int64  `json:"created_at_timestamp"`
+}
+
+// PolicyFamily is the template of cluster policies with definition maintained by Databricks
+type PolicyFamily struct {
+	PolicyFamilyID string `json:"policy_family_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	Definition     string `json:"definition"`
+}
+
+// PolicyFamilyList is a page of policy families
+type PolicyFamilyList struct {
+	PolicyFamilies []PolicyFamily `json:"policy_families"`
+	NextPageToken  string         `json:"next_page_token,omitempty"`
 }
 
 // ClusterPolicyCreate is the endity used for request
@@ -393,6 +514,13 @@ type InstancePoolAzureAttributes struct {
 	SpotBidMaxPrice float64      `json:"spot_bid_max_price,omitempty"`
 }
 
+// InstancePoolGcpAttributes contains gcp attributes for GCP Databricks deployments for instance pools
+// https://docs.gcp.databricks.com/dev-tools/api/latest/instance-pools.html#clusterinstancepoolgcpattributes
+type InstancePoolGcpAttributes struct {
+	Availability  Availability `json:"gcp_availability,omitempty"`
+	LocalSsdCount int32        `json:"local_ssd_count,omitempty"`
+}
+
 // InstancePoolDiskType contains disk type information for each of the different cloud service providers
 type InstancePoolDiskType struct {
 	AzureDiskVolumeType string `json:"azure_disk_volume_type,omitempty"`
@@ -415,6 +543,7 @@ type InstancePool struct {
 	IdleInstanceAutoTerminationMinutes int32                        `json:"idle_instance_autotermination_minutes"`
 	AwsAttributes                      *InstancePoolAwsAttributes   `json:"aws_attributes,omitempty"`
 	AzureAttributes                    *InstancePoolAzureAttributes `json:"azure_attributes,omitempty"`
+	GcpAttributes                      *InstancePoolGcpAttributes   `json:"gcp_attributes,omitempty"`
 	NodeTypeID                         string                       `json:"node_type_id"`
 	CustomTags                         map[string]string            `json:"custom_tags,omitempty"`
 	EnableElasticDisk                  bool                         `json:"enable_elastic_disk,omitempty"`
@@ -439,6 +568,7 @@ type InstancePoolAndStats struct {
 	MaxCapacity                        int32                        `json:"max_capacity,omitempty"`
 	AwsAttributes                      *InstancePoolAwsAttributes   `json:"aws_attributes,omitempty"`
 	AzureAttributes                    *InstancePoolAzureAttributes `json:"azure_attributes,omitempty"`
+	GcpAttributes                      *InstancePoolGcpAttributes   `json:"gcp_attributes,omitempty"`
 	NodeTypeID                         string                       `json:"node_type_id"`
 	DefaultTags                        map[string]string            `json:"default_tags,omitempty" tf:"computed"`
 	CustomTags                         map[string]string            `json:"custom_tags,omitempty"`
@@ -489,7 +619,11 @@ func (l *NodeTypeList) Sort() {
 		if l.NodeTypes[i].NumGPUs != l.NodeTypes[j].NumGPUs {
 			return l.NodeTypes[i].NumGPUs < l.NodeTypes[j].NumGPUs
 		}
-		return l.NodeTypes[i].InstanceTypeID < l.NodeTypes[j].InstanceTypeID
+		if l.NodeTypes[i].InstanceTypeID != l.NodeTypes[j].InstanceTypeID {
+			return l.NodeTypes[i].InstanceTypeID < l.NodeTypes[j].InstanceTypeID
+		}
+		// fleet node types share instance type with regular ones
+		return l.NodeTypes[i].NodeTypeID < l.NodeTypes[j].NodeTypeID
 	})
 }
 
@@ -557,7 +691,8 @@ type JobSettings struct {
 
 // JobList ...
 type JobList struct {
-	Jobs []Job `json:"jobs"`
+	Jobs    []Job `json:"jobs"`
+	HasMore bool  `json:"has_more,omitempty"`
 }
 
 // Job contains the information when using a GET request from the Databricks Jobs api
@@ -758,6 +893,7 @@ type SparkVersionRequest struct {
 	ML              bool   `json:"ml,omitempty" tf:"optional,default:false"`
 	Genomics        bool   `json:"genomics,omitempty" tf:"optional,default:false"`
 	GPU             bool   `json:"gpu,omitempty" tf:"optional,default:false"`
+	Photon          bool   `json:"photon,omitempty" tf:"optional,default:false"`
 	Scala           string `json:"scala,omitempty" tf:"optional,default:2.12"`
 	SparkVersion    string `json:"spark_version,omitempty" tf:"optional,default:"`
 }
@@ -91,3 +91,33 @@ func TestClusterState_CanReach(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerImage_KeepCredentials(t *testing.T) {
+	known := []DockerImage{
+		{URL: "a", BasicAuth: &DockerBasicAuth{Username: "u", Password: "p"}},
+		{URL: "b", BasicAuth: &DockerBasicAuth{Username: "v", Password: "q"}},
+	}
+	images := []DockerImage{
+		{URL: "a", BasicAuth: &DockerBasicAuth{Username: "u"}},
+		{URL: "b"},
+		{URL: "c"},
+	}
+	keepDockerCredentials(images, known)
+	if images[0].BasicAuth.Password != "p" {
+		t.Errorf("password of a is not restored: %v", images[0].BasicAuth)
+	}
+	if images[1].BasicAuth == nil || *images[1].BasicAuth != *known[1].BasicAuth {
+		t.Errorf("basic_auth of b is not restored: %v", images[1].BasicAuth)
+	}
+	if images[2].BasicAuth != nil {
+		t.Errorf("basic_auth of c must stay empty: %v", images[2].BasicAuth)
+	}
+
+	changed := &DockerImage{URL: "a", BasicAuth: &DockerBasicAuth{Username: "other"}}
+	changed.keepCredentials(&known[0])
+	if changed.BasicAuth.Password != "" {
+		t.Errorf("password must not be restored for different username")
+	}
+	var missing *DockerImage
+	missing.keepCredentials(&known[0])
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
@@ -37,6 +37,21 @@ func ResourceCluster() *schema.Resource {
 			Update: schema.DefaultTimeout(DefaultProvisionTimeout),
 			Delete: schema.DefaultTimeout(DefaultProvisionTimeout),
 		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, c interface{}) error {
+			err := validateDataSecurityModeDiff(d, "")
+			if err != nil {
+				return err
+			}
+			if !d.HasChange("runtime_engine") && !d.HasChange("node_type_id") &&
+				!d.HasChange("driver_node_type_id") && !d.HasChange("spark_version") {
+				return nil
+			}
+			return validateRuntimeEngine(ctx, c,
+				d.Get("runtime_engine").(string),
+				d.Get("spark_version").(string),
+				d.Get("node_type_id").(string),
+				d.Get("driver_node_type_id").(string))
+		},
 	}.ToResource()
 }
 
@@ -76,6 +91,56 @@ func resourceClusterSchema() map[string]*schema.Schema {
 		s["aws_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("aws_attributes.#")
 		s["azure_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("azure_attributes.#")
 		s["gcp_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("gcp_attributes.#")
+		if v, err := common.SchemaPath(s, "azure_attributes", "availability"); err == nil {
+			v.ValidateFunc = validation.StringInSlice([]string{
+				AzureAvailabilityOnDemand,
+				AzureAvailabilitySpot,
+				AzureAvailabilitySpotWithFallback,
+			}, false)
+		}
+		if v, err := common.SchemaPath(s, "azure_attributes", "spot_bid_max_price"); err == nil {
+			// -1 evicts spot instances only because of capacity, never because of price
+			v.ValidateFunc = validation.FloatAtLeast(-1)
+		}
+		if v, err := common.SchemaPath(s, "azure_attributes", "first_on_demand"); err == nil {
+			v.ValidateFunc = validation.IntAtLeast(0)
+		}
+		s["runtime_engine"].ValidateFunc = validation.StringInSlice([]string{
+			RuntimeEnginePhoton,
+			RuntimeEngineStandard,
+		}, false)
+		s["data_security_mode"].ValidateFunc = validation.StringInSlice(dataSecurityModes, false)
+		if v, err := common.SchemaPath(s, "gcp_attributes", "availability"); err == nil {
+			v.ValidateFunc = validation.StringInSlice([]string{
+				GcpAvailabilityOnDemand,
+				GcpAvailabilityPreemptible,
+				GcpAvailabilityPreemptibleWithFallback,
+			}, false)
+		}
+		if v, err := common.SchemaPath(s, "cluster_log_conf", "dbfs", "destination"); err == nil {
+			v.ValidateFunc = validation.StringMatch(regexp.MustCompile(`^dbfs:/`),
+				"must start with dbfs:/")
+		}
+		if v, err := common.SchemaPath(s, "cluster_log_conf", "s3", "destination"); err == nil {
+			v.ValidateFunc = validation.StringMatch(regexp.MustCompile(`^s3a?://`),
+				"must start with s3:// or s3a://")
+		}
+		if v, err := common.SchemaPath(s, "cluster_log_conf", "s3", "encryption_type"); err == nil {
+			v.ValidateFunc = validation.StringInSlice([]string{"sse-s3", "sse-kms"}, false)
+		}
+		for _, client := range []string{"jobs", "notebooks"} {
+			if v, err := common.SchemaPath(s, "workload_type", "clients", client); err == nil {
+				v.Required = false
+				v.Optional = true
+				v.Default = true
+			}
+		}
+		// last delivery of logs helps to debug missing logs
+		s["cluster_log_status"] = common.StructToSchema(struct {
+			ClusterLogStatus *LogSyncStatus `json:"cluster_log_status,omitempty" tf:"computed"`
+		}{}, func(ss map[string]*schema.Schema) map[string]*schema.Schema {
+			return ss
+		})["cluster_log_status"]
 
 		s["instance_pool_id"].ConflictsWith = []string{"driver_node_type_id", "node_type_id"}
 		s["driver_instance_pool_id"].ConflictsWith = []string{"driver_node_type_id", "node_type_id"}
@@ -93,6 +158,21 @@ func resourceClusterSchema() map[string]*schema.Schema {
 				return old == new
 			},
 		}
+		// clusters, that are used only from time to time, don't have to hold up applies
+		s["no_wait"] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+				return old == "" && new == "false"
+			},
+		}
+		s["is_single_node"] = &schema.Schema{
+			Type:          schema.TypeBool,
+			Optional:      true,
+			Default:       false,
+			ConflictsWith: []string{"autoscale"},
+		}
 		s["state"] = &schema.Schema{
 			Type:     schema.TypeString,
 			Computed: true,
@@ -115,6 +195,66 @@ func resourceClusterSchema() map[string]*schema.Schema {
 	})
 }
 
+// singleNodeSparkConf and singleNodeCustomTags are required for clusters without workers.
+// See https://docs.databricks.com/clusters/single-node.html
+var (
+	singleNodeSparkConf = map[string]string{
+		"spark.databricks.cluster.profile": "singleNode",
+		"spark.master":                     "local[*]",
+	}
+	singleNodeCustomTags = map[string]string{
+		"ResourceClass": "SingleNode",
+	}
+)
+
+// setSingleNode adds spark_conf and custom_tags of single node clusters, unless they are
+// explicitly configured, e.g. with different number of cores in spark.master
+func setSingleNode(d *schema.ResourceData, cluster *Cluster) error {
+	if !d.Get("is_single_node").(bool) {
+		return nil
+	}
+	if cluster.NumWorkers > 0 {
+		return fmt.Errorf("num_workers must be 0 for single node clusters, got %d", cluster.NumWorkers)
+	}
+	if cluster.SparkConf == nil {
+		cluster.SparkConf = map[string]string{}
+	}
+	for k, v := range singleNodeSparkConf {
+		if _, ok := cluster.SparkConf[k]; !ok {
+			cluster.SparkConf[k] = v
+		}
+	}
+	if cluster.CustomTags == nil {
+		cluster.CustomTags = map[string]string{}
+	}
+	for k, v := range singleNodeCustomTags {
+		if _, ok := cluster.CustomTags[k]; !ok {
+			cluster.CustomTags[k] = v
+		}
+	}
+	return nil
+}
+
+// hideSingleNodeConf removes spark_conf and custom_tags added by setSingleNode from the
+// cluster read from API, so that they don't show up as diff
+func hideSingleNodeConf(d *schema.ResourceData, clusterInfo *ClusterInfo) {
+	if !d.Get("is_single_node").(bool) {
+		return
+	}
+	sparkConf := d.Get("spark_conf").(map[string]interface{})
+	for k := range singleNodeSparkConf {
+		if _, ok := sparkConf[k]; !ok {
+			delete(clusterInfo.SparkConf, k)
+		}
+	}
+	customTags := d.Get("custom_tags").(map[string]interface{})
+	for k := range singleNodeCustomTags {
+		if _, ok := customTags[k]; !ok {
+			delete(clusterInfo.CustomTags, k)
+		}
+	}
+}
+
 func validateClusterDefinition(cluster Cluster) error {
 	if cluster.NumWorkers > 0 || cluster.Autoscale != nil {
 		return nil
@@ -128,6 +268,105 @@ func validateClusterDefinition(cluster Cluster) error {
 	return fmt.Errorf("NumWorkers could be 0 only for SingleNode clusters. See https://docs.databricks.com/clusters/single-node.html for more details")
 }
 
+// validateRuntimeEngine checks, that runtime_engine doesn't contradict spark_version and that
+// node types of Photon clusters support Photon. Node types are checked only if they are known
+// during plan, e.g. node types of instance pools are not.
+func validateRuntimeEngine(ctx context.Context, m interface{},
+	runtimeEngine, sparkVersion, nodeTypeID, driverNodeTypeID string) error {
+	if runtimeEngine == RuntimeEngineStandard && strings.Contains(sparkVersion, "-photon-") {
+		return fmt.Errorf("runtime_engine %s conflicts with Photon spark_version %s",
+			runtimeEngine, sparkVersion)
+	}
+	if runtimeEngine != RuntimeEnginePhoton || nodeTypeID == "" {
+		return nil
+	}
+	if driverNodeTypeID == "" {
+		driverNodeTypeID = nodeTypeID
+	}
+	nodeTypes, err := NewClustersAPI(ctx, m).ListNodeTypes()
+	if err != nil {
+		return err
+	}
+	for _, nt := range nodeTypes.NodeTypes {
+		if nt.NodeTypeID == nodeTypeID && !nt.PhotonWorkerCapable {
+			return fmt.Errorf("node type %s doesn't support Photon", nodeTypeID)
+		}
+		if nt.NodeTypeID == driverNodeTypeID && !nt.PhotonDriverCapable {
+			return fmt.Errorf("driver node type %s doesn't support Photon", driverNodeTypeID)
+		}
+	}
+	return nil
+}
+
+var dataSecurityModes = []string{
+	DataSecurityModeNone,
+	DataSecurityModeSingleUser,
+	DataSecurityModeUserIsolation,
+	DataSecurityModeLegacyTableACL,
+	DataSecurityModeLegacyPassthrough,
+	DataSecurityModeLegacySingleUser,
+}
+
+// validateDataSecurityMode checks combinations of data_security_mode, single_user_name and
+// credential passthrough, that are rejected by the API only when the cluster starts
+func validateDataSecurityMode(mode, singleUserName string, sparkConf map[string]interface{}) error {
+	switch mode {
+	case DataSecurityModeSingleUser:
+		if singleUserName == "" {
+			return fmt.Errorf("data_security_mode %s requires single_user_name", mode)
+		}
+	case DataSecurityModeNone, DataSecurityModeUserIsolation, DataSecurityModeLegacyTableACL:
+		if singleUserName != "" {
+			return fmt.Errorf("single_user_name can't be used with data_security_mode %s", mode)
+		}
+	}
+	passthrough := sparkConf["spark.databricks.passthrough.enabled"] == "true"
+	if passthrough && (mode == DataSecurityModeSingleUser || mode == DataSecurityModeUserIsolation) {
+		return fmt.Errorf("credential passthrough can't be used with Unity Catalog data_security_mode %s", mode)
+	}
+	return nil
+}
+
+// validateDataSecurityModeDiff validates fields with the given prefix, like "new_cluster.0.", only
+// once they are known, as single_user_name may come from a resource, that is not created yet
+func validateDataSecurityModeDiff(d *schema.ResourceDiff, prefix string) error {
+	for _, field := range []string{"data_security_mode", "single_user_name", "spark_conf"} {
+		if !d.NewValueKnown(prefix + field) {
+			return nil
+		}
+	}
+	return validateDataSecurityMode(
+		d.Get(prefix+"data_security_mode").(string),
+		d.Get(prefix+"single_user_name").(string),
+		d.Get(prefix+"spark_conf").(map[string]interface{}))
+}
+
+// validateInitScripts checks destinations of init scripts, as ABFSS and GCS are available only on
+// their own clouds
+func validateInitScripts(c *common.DatabricksClient, scripts []InitScriptStorageInfo) error {
+	for i, is := range scripts {
+		switch {
+		case is.Workspace != nil && !strings.HasPrefix(is.Workspace.Destination, "/"):
+			return fmt.Errorf("init_scripts.%d.workspace: destination must be an absolute path, got %s",
+				i, is.Workspace.Destination)
+		case is.Volumes != nil && !strings.HasPrefix(is.Volumes.Destination, "/Volumes/"):
+			return fmt.Errorf("init_scripts.%d.volumes: destination must start with /Volumes/, got %s",
+				i, is.Volumes.Destination)
+		case is.Abfss != nil && !strings.HasPrefix(is.Abfss.Destination, "abfss://"):
+			return fmt.Errorf("init_scripts.%d.abfss: destination must start with abfss://, got %s",
+				i, is.Abfss.Destination)
+		case is.Abfss != nil && !c.IsAzure():
+			return fmt.Errorf("init_scripts.%d.abfss: only supported on Azure", i)
+		case is.Gcs != nil && !strings.HasPrefix(is.Gcs.Destination, "gs://"):
+			return fmt.Errorf("init_scripts.%d.gcs: destination must start with gs://, got %s",
+				i, is.Gcs.Destination)
+		case is.Gcs != nil && !c.IsGcp():
+			return fmt.Errorf("init_scripts.%d.gcs: only supported on GCP", i)
+		}
+	}
+	return nil
+}
+
 func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
 	var cluster Cluster
 	clusters := NewClustersAPI(ctx, c)
@@ -135,11 +374,22 @@ func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, c *commo
 	if err != nil {
 		return err
 	}
+	if err = setSingleNode(d, &cluster); err != nil {
+		return err
+	}
 	if err = validateClusterDefinition(cluster); err != nil {
 		return err
 	}
+	if err = validateInitScripts(c, cluster.InitScripts); err != nil {
+		return err
+	}
 	modifyClusterRequest(&cluster)
-	clusterInfo, err := clusters.Create(cluster)
+	var clusterInfo ClusterInfo
+	if d.Get("no_wait").(bool) {
+		clusterInfo, err = clusters.CreateWithoutWait(cluster)
+	} else {
+		clusterInfo, err = clusters.Create(cluster)
+	}
 	if err != nil {
 		return err
 	}
@@ -179,11 +429,12 @@ func setPinnedStatus(d *schema.ResourceData, clusterAPI ClustersAPI) error {
 	if err != nil {
 		return err
 	}
-	pinnedEvent := EvTypeUnpinned
-	if len(events) > 0 {
-		pinnedEvent = events[0].Type
+	if len(events) == 0 {
+		// events are retained only for limited time, so the pin of long-lived
+		// cluster may no longer be visible. Keep the known state in this case.
+		return d.Set("is_pinned", d.Get("is_pinned").(bool))
 	}
-	return d.Set("is_pinned", pinnedEvent == EvTypePinned)
+	return d.Set("is_pinned", events[0].Type == EvTypePinned)
 }
 
 func resourceClusterRead(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
@@ -192,6 +443,13 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, c *common.
 	if err != nil {
 		return err
 	}
+	withLibraries := managesLibraries(d)
+	var known Cluster
+	if err = common.DataToStructPointer(d, clusterSchema, &known); err != nil {
+		return err
+	}
+	clusterInfo.DockerImage.keepCredentials(known.DockerImage)
+	hideSingleNodeConf(d, &clusterInfo)
 	if err = common.StructToData(clusterInfo, clusterSchema, d); err != nil {
 		return err
 	}
@@ -199,6 +457,9 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, c *common.
 		return err
 	}
 	d.Set("url", c.FormatURL("#setting/clusters/", d.Id(), "/configuration"))
+	if !withLibraries {
+		return nil
+	}
 	librariesAPI := NewLibrariesAPI(ctx, c)
 	libsClusterStatus, err := waitForLibrariesInstalled(librariesAPI, clusterInfo)
 	if err != nil {
@@ -208,40 +469,34 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, c *common.
 	return common.StructToData(libList, clusterSchema, d)
 }
 
+// managesLibraries is true for clusters with library blocks, so that libraries of clusters
+// without them can be installed by databricks_library. Imported clusters get all libraries.
+func managesLibraries(d *schema.ResourceData) bool {
+	if d.Get("spark_version").(string) == "" {
+		return true
+	}
+	old, new := d.GetChange("library")
+	return old.(*schema.Set).Len() > 0 || new.(*schema.Set).Len() > 0
+}
+
 func waitForLibrariesInstalled(
-	libraries LibrariesAPI, clusterInfo ClusterInfo) (result *ClusterLibraryStatuses, err error) {
-	err = resource.RetryContext(libraries.context, 30*time.Minute, func() *resource.RetryError {
-		libsClusterStatus, err := libraries.ClusterStatus(clusterInfo.ClusterID)
-		if common.IsMissing(err) {
-			// eventual consistency error
-			return resource.RetryableError(err)
-		}
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		if !clusterInfo.IsRunningOrResizing() {
-			log.Printf("[INFO] Cluster %#v (%s) is currently not running, so just returning list of %d libraries",
-				clusterInfo.ClusterName, clusterInfo.ClusterID, len(libsClusterStatus.LibraryStatuses))
-			result = &libsClusterStatus
-			return nil
-		}
-		retry, err := libsClusterStatus.IsRetryNeeded()
-		if retry {
-			return resource.RetryableError(err)
-		}
-		if err != nil {
-			return resource.NonRetryableError(err)
-		}
-		result = &libsClusterStatus
-		return nil
-	})
-	return
+	libraries LibrariesAPI, clusterInfo ClusterInfo) (*ClusterLibraryStatuses, error) {
+	wait := clusterInfo.IsRunningOrResizing()
+	if !wait {
+		log.Printf("[INFO] Cluster %#v (%s) is currently not running, so just returning list of libraries",
+			clusterInfo.ClusterName, clusterInfo.ClusterID)
+	}
+	result, err := libraries.waitForLibraries(clusterInfo.ClusterID, "", wait)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 func hasClusterConfigChanged(d *schema.ResourceData) bool {
 	for k := range clusterSchema {
 		// TODO: create a map if we'll add more non-cluster config parameters in the future
-		if k == "library" || k == "is_pinned" {
+		if k == "library" || k == "is_pinned" || k == "no_wait" {
 			continue
 		}
 		if d.HasChange(k) {
@@ -262,10 +517,18 @@ func resourceClusterUpdate(ctx context.Context, d *schema.ResourceData, c *commo
 	var clusterInfo ClusterInfo
 	if hasClusterConfigChanged(d) {
 		log.Printf("[DEBUG] Cluster state has changed!")
+		err = setSingleNode(d, &cluster)
+		if err != nil {
+			return err
+		}
 		err = validateClusterDefinition(cluster)
 		if err != nil {
 			return err
 		}
+		err = validateInitScripts(c, cluster.InitScripts)
+		if err != nil {
+			return err
+		}
 		modifyClusterRequest(&cluster)
 		clusterInfo, err = clusters.Edit(cluster)
 		if err != nil {
@@ -290,6 +553,9 @@ func resourceClusterUpdate(ctx context.Context, d *schema.ResourceData, c *commo
 		}
 	}
 
+	if !managesLibraries(d) {
+		return nil
+	}
 	var libraryList ClusterLibraryList
 	if err = common.DataToStructPointer(d, clusterSchema, &libraryList); err != nil {
 		return err
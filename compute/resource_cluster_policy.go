@@ -61,6 +61,12 @@ func parsePolicyFromData(d *schema.ResourceData) (*ClusterPolicy, error) {
 	if name, ok := d.GetOk("name"); ok {
 		clusterPolicy.Name = name.(string)
 	}
+	if family, ok := d.GetOk("policy_family_id"); ok {
+		// definition is derived from the family, so only overrides are sent
+		clusterPolicy.PolicyFamilyID = family.(string)
+		clusterPolicy.PolicyFamilyDefinitionOverrides = d.Get("policy_family_definition_overrides").(string)
+		return clusterPolicy, nil
+	}
 	if data, ok := d.GetOk("definition"); ok {
 		clusterPolicy.Definition = data.(string)
 	}
@@ -87,7 +93,22 @@ func ResourceClusterPolicy() *schema.Resource {
 				Optional: true,
 				Description: "Policy definition JSON document expressed in\n" +
 					"Databricks Policy Definition Language.",
+				ValidateFunc:  validation.StringIsJSON,
+				ConflictsWith: []string{"policy_family_id"},
+			},
+			"policy_family_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "ID of the policy family. The definition of the policy\n" +
+					"is inherited from the family and cannot be set directly.",
+			},
+			"policy_family_definition_overrides": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Policy definition JSON document, that is merged\n" +
+					"with the definition of the policy family.",
 				ValidateFunc: validation.StringIsJSON,
+				RequiredWith: []string{"policy_family_id"},
 			},
 		},
 		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
@@ -109,9 +130,20 @@ func ResourceClusterPolicy() *schema.Resource {
 			if err = d.Set("name", clusterPolicy.Name); err != nil {
 				return err
 			}
+			if clusterPolicy.PolicyFamilyID != "" {
+				// effective definition of family policies is managed by Databricks
+				clusterPolicy.Definition = ""
+			}
 			if err = d.Set("definition", clusterPolicy.Definition); err != nil {
 				return err
 			}
+			if err = d.Set("policy_family_id", clusterPolicy.PolicyFamilyID); err != nil {
+				return err
+			}
+			if err = d.Set("policy_family_definition_overrides",
+				clusterPolicy.PolicyFamilyDefinitionOverrides); err != nil {
+				return err
+			}
 			if err = d.Set("policy_id", clusterPolicy.PolicyID); err != nil {
 				return err
 			}
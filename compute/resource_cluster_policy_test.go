@@ -112,6 +112,70 @@ func TestResourceClusterPolicyCreate(t *testing.T) {
 	assert.Equal(t, "abc", d.Id())
 }
 
+func TestResourceClusterPolicyCreate_PolicyFamily(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/policies/clusters/create",
+				ExpectedRequest: ClusterPolicy{
+					Name:                            "Personal Compute",
+					PolicyFamilyID:                  "personal-vm",
+					PolicyFamilyDefinitionOverrides: `{"autotermination_minutes": {"type": "fixed", "value": 60}}`,
+				},
+				Response: ClusterPolicy{
+					PolicyID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policies/clusters/get?policy_id=abc",
+				Response: ClusterPolicy{
+					PolicyID:                        "abc",
+					Name:                            "Personal Compute",
+					Definition:                      `{"autotermination_minutes": {"type": "fixed", "value": 60}, "node_type_id": {"type": "unlimited"}}`,
+					PolicyFamilyID:                  "personal-vm",
+					PolicyFamilyDefinitionOverrides: `{"autotermination_minutes": {"type": "fixed", "value": 60}}`,
+				},
+			},
+		},
+		Resource: ResourceClusterPolicy(),
+		HCL: `
+		name = "Personal Compute"
+		policy_family_id = "personal-vm"
+		policy_family_definition_overrides = "{\"autotermination_minutes\": {\"type\": \"fixed\", \"value\": 60}}"
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "personal-vm", d.Get("policy_family_id"))
+	assert.Equal(t, "", d.Get("definition"))
+}
+
+func TestResourceClusterPolicyCreate_DefinitionConflictsWithFamily(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceClusterPolicy(),
+		HCL: `
+		name = "Personal Compute"
+		definition = "{}"
+		policy_family_id = "personal-vm"
+		`,
+		Create: true,
+	}.ExpectError(t, "invalid config supplied. [definition] Conflicting configuration arguments")
+}
+
+func TestResourceClusterPolicyCreate_OverridesRequireFamily(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceClusterPolicy(),
+		HCL: `
+		name = "Personal Compute"
+		policy_family_definition_overrides = "{}"
+		`,
+		Create: true,
+	}.ExpectError(t, "invalid config supplied. [policy_family_definition_overrides] Missing required argument")
+}
+
 func TestResourceClusterPolicyCreate_Error(t *testing.T) {
 	d, err := qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -172,6 +236,47 @@ func TestResourceClusterPolicyUpdate(t *testing.T) {
 	assert.Equal(t, "abc", d.Id())
 }
 
+func TestResourceClusterPolicyUpdate_PolicyFamily(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/policies/clusters/edit",
+				ExpectedRequest: ClusterPolicy{
+					PolicyID:                        "abc",
+					Name:                            "Personal Compute",
+					PolicyFamilyID:                  "personal-vm",
+					PolicyFamilyDefinitionOverrides: `{"autotermination_minutes":{"type":"fixed","value":30}}`,
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/policies/clusters/get?policy_id=abc",
+				Response: ClusterPolicy{
+					PolicyID:                        "abc",
+					Name:                            "Personal Compute",
+					Definition:                      `{"autotermination_minutes":{"type":"fixed","value":30}}`,
+					PolicyFamilyID:                  "personal-vm",
+					PolicyFamilyDefinitionOverrides: `{"autotermination_minutes":{"type":"fixed","value":30}}`,
+				},
+			},
+		},
+		Resource: ResourceClusterPolicy(),
+		InstanceState: map[string]string{
+			"name":                               "Personal Compute",
+			"policy_family_id":                   "personal-vm",
+			"policy_family_definition_overrides": `{"autotermination_minutes":{"type":"fixed","value":60}}`,
+		},
+		HCL: `
+		name = "Personal Compute"
+		policy_family_id = "personal-vm"
+		policy_family_definition_overrides = "{\"autotermination_minutes\":{\"type\":\"fixed\",\"value\":30}}"
+		`,
+		Update: true,
+		ID:     "abc",
+	}.ApplyNoError(t)
+}
+
 func TestResourceClusterPolicyUpdate_Error(t *testing.T) {
 	d, err := qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -81,6 +81,823 @@ func TestResourceClusterCreate(t *testing.T) {
 	assert.Equal(t, "abc", d.Id())
 }
 
+func TestResourceClusterCreate_GcpAttributes(t *testing.T) {
+	gcpAttributes := &GcpAttributes{
+		GoogleServiceAccount: "sa@project.iam.gserviceaccount.com",
+		Availability:         GcpAvailabilityPreemptibleWithFallback,
+		BootDiskSize:         100,
+		LocalSsdCount:        1,
+	}
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "GCP",
+					SparkVersion:           "9.1.x-scala2.12",
+					NodeTypeID:             "n1-standard-4",
+					AutoterminationMinutes: 15,
+					GcpAttributes:          gcpAttributes,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "GCP",
+					SparkVersion:           "9.1.x-scala2.12",
+					NodeTypeID:             "n1-standard-4",
+					AutoterminationMinutes: 15,
+					GcpAttributes:          gcpAttributes,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "GCP"
+		spark_version = "9.1.x-scala2.12"
+		node_type_id = "n1-standard-4"
+		num_workers = 1
+		autotermination_minutes = 15
+		gcp_attributes {
+			google_service_account = "sa@project.iam.gserviceaccount.com"
+			availability = "PREEMPTIBLE_WITH_FALLBACK_GCP"
+			boot_disk_size = 100
+			local_ssd_count = 1
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "PREEMPTIBLE_WITH_FALLBACK_GCP", d.Get("gcp_attributes.0.availability"))
+	assert.Equal(t, 100, d.Get("gcp_attributes.0.boot_disk_size"))
+	assert.Equal(t, 1, d.Get("gcp_attributes.0.local_ssd_count"))
+}
+
+func TestResourceClusterCreate_GcpInvalidAvailability(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "GCP"
+		spark_version = "9.1.x-scala2.12"
+		node_type_id = "n1-standard-4"
+		num_workers = 1
+		gcp_attributes {
+			availability = "SPOT"
+		}
+		`,
+	}.ExpectError(t, "invalid config supplied. [gcp_attributes.#.availability] expected "+
+		"gcp_attributes.0.availability to be one of [ON_DEMAND_GCP PREEMPTIBLE_GCP PREEMPTIBLE_WITH_FALLBACK_GCP], got SPOT")
+}
+
+func TestResourceClusterCreate_WorkspaceAndVolumesInitScripts(t *testing.T) {
+	initScripts := []InitScriptStorageInfo{
+		{Workspace: &WorkspaceFileInfo{Destination: "/Users/me@example.com/init.sh"}},
+		{Volumes: &VolumesStorageInfo{Destination: "/Volumes/main/default/scripts/init.sh"}},
+	}
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "Init",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					InitScripts:            initScripts,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "Init",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					InitScripts:            initScripts,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Init"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		autotermination_minutes = 15
+		init_scripts {
+			workspace {
+				destination = "/Users/me@example.com/init.sh"
+			}
+		}
+		init_scripts {
+			volumes {
+				destination = "/Volumes/main/default/scripts/init.sh"
+			}
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "/Users/me@example.com/init.sh", d.Get("init_scripts.0.workspace.0.destination"))
+	assert.Equal(t, "/Volumes/main/default/scripts/init.sh", d.Get("init_scripts.1.volumes.0.destination"))
+}
+
+func TestResourceClusterCreate_AbfssInitScriptNotOnAzure(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Init"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		init_scripts {
+			abfss {
+				destination = "abfss://scripts@acme.dfs.core.windows.net/init.sh"
+			}
+		}
+		`,
+	}.ExpectError(t, "init_scripts.0.abfss: only supported on Azure")
+}
+
+func TestValidateInitScripts(t *testing.T) {
+	azure := &common.DatabricksClient{Host: "https://adb-123.4.azuredatabricks.net"}
+	gcp := &common.DatabricksClient{Host: "https://123.4.gcp.databricks.com"}
+	for _, c := range []struct {
+		client *common.DatabricksClient
+		script InitScriptStorageInfo
+		err    string
+	}{
+		{azure, InitScriptStorageInfo{Abfss: &AbfssStorageInfo{"abfss://a@b.dfs.core.windows.net/x.sh"}}, ""},
+		{azure, InitScriptStorageInfo{Abfss: &AbfssStorageInfo{"wasbs://a@b/x.sh"}},
+			"init_scripts.0.abfss: destination must start with abfss://, got wasbs://a@b/x.sh"},
+		{gcp, InitScriptStorageInfo{Gcs: &GcsStorageInfo{"gs://bucket/x.sh"}}, ""},
+		{azure, InitScriptStorageInfo{Gcs: &GcsStorageInfo{"gs://bucket/x.sh"}},
+			"init_scripts.0.gcs: only supported on GCP"},
+		{gcp, InitScriptStorageInfo{Gcs: &GcsStorageInfo{"bucket/x.sh"}},
+			"init_scripts.0.gcs: destination must start with gs://, got bucket/x.sh"},
+		{gcp, InitScriptStorageInfo{Workspace: &WorkspaceFileInfo{"Shared/x.sh"}},
+			"init_scripts.0.workspace: destination must be an absolute path, got Shared/x.sh"},
+		{azure, InitScriptStorageInfo{Volumes: &VolumesStorageInfo{"/Users/x.sh"}},
+			"init_scripts.0.volumes: destination must start with /Volumes/, got /Users/x.sh"},
+	} {
+		err := validateInitScripts(c.client, []InitScriptStorageInfo{c.script})
+		if c.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, c.err)
+		}
+	}
+}
+
+func TestResourceClusterCreate_IsSingleNode(t *testing.T) {
+	sparkConf := map[string]string{
+		"spark.databricks.cluster.profile": "singleNode",
+		"spark.master":                     "local[*]",
+	}
+	customTags := map[string]string{
+		"ResourceClass": "SingleNode",
+	}
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             0,
+					ClusterName:            "Single Node",
+					SparkVersion:           "7.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 20,
+					SparkConf:              sparkConf,
+					CustomTags:             customTags,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					ClusterName:            "Single Node",
+					SparkVersion:           "7.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 20,
+					SparkConf:              sparkConf,
+					CustomTags:             customTags,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Single Node"
+		spark_version = "7.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		autotermination_minutes = 20
+		is_single_node = true
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, true, d.Get("is_single_node"))
+	assert.Len(t, d.Get("spark_conf"), 0)
+	assert.Len(t, d.Get("custom_tags"), 0)
+}
+
+func TestResourceClusterCreate_SingleNodeWithWorkers(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Single Node"
+		spark_version = "7.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 2
+		is_single_node = true
+		`,
+	}.ExpectError(t, "num_workers must be 0 for single node clusters, got 2")
+}
+
+func TestSetSingleNode_KeepsExplicitConf(t *testing.T) {
+	d := ResourceCluster().TestResourceData()
+	require.NoError(t, d.Set("is_single_node", true))
+	cluster := Cluster{
+		SparkConf: map[string]string{"spark.master": "local[4]"},
+	}
+	require.NoError(t, setSingleNode(d, &cluster))
+	assert.Equal(t, "local[4]", cluster.SparkConf["spark.master"])
+	assert.Equal(t, "singleNode", cluster.SparkConf["spark.databricks.cluster.profile"])
+	assert.Equal(t, "SingleNode", cluster.CustomTags["ResourceClass"])
+	assert.NoError(t, validateClusterDefinition(cluster))
+}
+
+func TestResourceClusterCreate_Photon(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list-node-types",
+				Response: NodeTypeList{
+					NodeTypes: []NodeType{
+						{
+							NodeTypeID:          "i3.xlarge",
+							PhotonWorkerCapable: true,
+							PhotonDriverCapable: true,
+						},
+					},
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "Photon",
+					SparkVersion:           "11.3.x-scala2.12",
+					RuntimeEngine:          RuntimeEnginePhoton,
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "Photon",
+					SparkVersion:           "11.3.x-scala2.12",
+					RuntimeEngine:          RuntimeEnginePhoton,
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Photon"
+		spark_version = "11.3.x-scala2.12"
+		runtime_engine = "PHOTON"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		autotermination_minutes = 15
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "PHOTON", d.Get("runtime_engine"))
+}
+
+func TestResourceClusterCreate_PhotonNotSupportedByNodeType(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list-node-types",
+				Response: NodeTypeList{
+					NodeTypes: []NodeType{
+						{
+							NodeTypeID:          "i3.xlarge",
+							PhotonWorkerCapable: true,
+							PhotonDriverCapable: true,
+						},
+						{
+							NodeTypeID: "m4.large",
+						},
+					},
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Photon"
+		spark_version = "11.3.x-scala2.12"
+		runtime_engine = "PHOTON"
+		node_type_id = "i3.xlarge"
+		driver_node_type_id = "m4.large"
+		num_workers = 1
+		`,
+	}.ExpectError(t, "driver node type m4.large doesn't support Photon")
+}
+
+func TestResourceClusterCreate_StandardEngineWithPhotonRuntime(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Photon"
+		spark_version = "11.3.x-photon-scala2.12"
+		runtime_engine = "STANDARD"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		`,
+	}.ExpectError(t, "runtime_engine STANDARD conflicts with Photon spark_version 11.3.x-photon-scala2.12")
+}
+
+func TestResourceClusterCreate_InvalidRuntimeEngine(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Photon"
+		spark_version = "11.3.x-scala2.12"
+		runtime_engine = "TURBO"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		`,
+	}.ExpectError(t, "invalid config supplied. [runtime_engine] expected runtime_engine "+
+		"to be one of [PHOTON STANDARD], got TURBO")
+}
+
+func TestResourceClusterCreate_SingleUserDataSecurityMode(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "Unity Catalog",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					DataSecurityMode:       DataSecurityModeSingleUser,
+					SingleUserName:         "me@example.com",
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "Unity Catalog",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					DataSecurityMode:       DataSecurityModeSingleUser,
+					SingleUserName:         "me@example.com",
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Unity Catalog"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		autotermination_minutes = 15
+		data_security_mode = "SINGLE_USER"
+		single_user_name = "me@example.com"
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "SINGLE_USER", d.Get("data_security_mode"))
+	assert.Equal(t, "me@example.com", d.Get("single_user_name"))
+}
+
+func TestResourceClusterCreate_SingleUserWithoutUserName(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Unity Catalog"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		data_security_mode = "SINGLE_USER"
+		`,
+	}.ExpectError(t, "data_security_mode SINGLE_USER requires single_user_name")
+}
+
+func TestResourceClusterCreate_SingleUserNameUnknownAtPlan(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_REQUEST",
+					Message:   "reached the API",
+				},
+				Status: 400,
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		// single_user_name is application_id of service principal, that is not created yet
+		HCL: `
+		cluster_name = "Unity Catalog"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		data_security_mode = "SINGLE_USER"
+		single_user_name = "` + qa.UnknownValue + `"
+		`,
+	}.ExpectError(t, "reached the API")
+}
+
+func TestResourceClusterCreate_PassthroughWithUnityCatalog(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Unity Catalog"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		data_security_mode = "USER_ISOLATION"
+		spark_conf = {
+			"spark.databricks.passthrough.enabled" = "true"
+		}
+		`,
+	}.ExpectError(t, "credential passthrough can't be used with Unity Catalog data_security_mode USER_ISOLATION")
+}
+
+func TestResourceClusterCreate_InvalidDataSecurityMode(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Unity Catalog"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		data_security_mode = "SHARED"
+		`,
+	}.ExpectError(t, "invalid config supplied. [data_security_mode] expected data_security_mode "+
+		"to be one of [NONE SINGLE_USER USER_ISOLATION LEGACY_TABLE_ACL LEGACY_PASSTHROUGH LEGACY_SINGLE_USER], got SHARED")
+}
+
+func TestValidateDataSecurityMode(t *testing.T) {
+	assert.NoError(t, validateDataSecurityMode("", "me@example.com", nil))
+	assert.NoError(t, validateDataSecurityMode(DataSecurityModeLegacySingleUser, "me@example.com",
+		map[string]interface{}{"spark.databricks.passthrough.enabled": "true"}))
+	assert.NoError(t, validateDataSecurityMode(DataSecurityModeUserIsolation, "", nil))
+	assert.EqualError(t, validateDataSecurityMode(DataSecurityModeUserIsolation, "me@example.com", nil),
+		"single_user_name can't be used with data_security_mode USER_ISOLATION")
+	assert.EqualError(t, validateDataSecurityMode(DataSecurityModeSingleUser, "me@example.com",
+		map[string]interface{}{"spark.databricks.passthrough.enabled": "true"}),
+		"credential passthrough can't be used with Unity Catalog data_security_mode SINGLE_USER")
+}
+
+func TestResourceClusterCreate_WorkloadType(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "Jobs only",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					WorkloadType: &WorkloadType{
+						Clients: &WorkloadClients{
+							Jobs:      true,
+							Notebooks: false,
+						},
+					},
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "Jobs only",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					WorkloadType: &WorkloadType{
+						Clients: &WorkloadClients{
+							Jobs: true,
+						},
+					},
+					State: ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Jobs only"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		autotermination_minutes = 15
+		workload_type {
+			clients {
+				notebooks = false
+			}
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, true, d.Get("workload_type.0.clients.0.jobs"))
+	assert.Equal(t, false, d.Get("workload_type.0.clients.0.notebooks"))
+}
+
+func TestResourceClusterCreate_NoWait(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             1,
+					ClusterName:            "Dev",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+				},
+			},
+			{
+				// only the read after create checks the cluster, that is still starting
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             1,
+					ClusterName:            "Dev",
+					SparkVersion:           "11.3.x-scala2.12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					State:                  ClusterStatePending,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Dev"
+		spark_version = "11.3.x-scala2.12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		autotermination_minutes = 15
+		no_wait = true
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "PENDING", d.Get("state"))
+}
+
+func TestResourceClusterCreate_AzureSpot(t *testing.T) {
+	azureAttributes := &AzureAttributes{
+		Availability:    AzureAvailabilitySpotWithFallback,
+		FirstOnDemand:   1,
+		SpotBidMaxPrice: -1,
+	}
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/create",
+				ExpectedRequest: Cluster{
+					NumWorkers:             2,
+					ClusterName:            "Spot",
+					SparkVersion:           "9.1.x-scala2.12",
+					NodeTypeID:             "Standard_DS3_v2",
+					AutoterminationMinutes: 15,
+					AzureAttributes:        azureAttributes,
+				},
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:       "GET",
+				ReuseRequest: true,
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             2,
+					ClusterName:            "Spot",
+					SparkVersion:           "9.1.x-scala2.12",
+					NodeTypeID:             "Standard_DS3_v2",
+					AutoterminationMinutes: 15,
+					AzureAttributes:        azureAttributes,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Spot"
+		spark_version = "9.1.x-scala2.12"
+		node_type_id = "Standard_DS3_v2"
+		num_workers = 2
+		autotermination_minutes = 15
+		azure_attributes {
+			availability = "SPOT_WITH_FALLBACK_AZURE"
+			first_on_demand = 1
+			spot_bid_max_price = -1
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "SPOT_WITH_FALLBACK_AZURE", d.Get("azure_attributes.0.availability"))
+	assert.Equal(t, 1, d.Get("azure_attributes.0.first_on_demand"))
+	assert.Equal(t, -1.0, d.Get("azure_attributes.0.spot_bid_max_price"))
+}
+
+func TestResourceClusterCreate_AzureInvalidSpotBidMaxPrice(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Spot"
+		spark_version = "9.1.x-scala2.12"
+		node_type_id = "Standard_DS3_v2"
+		num_workers = 2
+		azure_attributes {
+			availability = "SPOT_AZURE"
+			spot_bid_max_price = -2
+		}
+		`,
+	}.ExpectError(t, "invalid config supplied. [azure_attributes.#.spot_bid_max_price] expected "+
+		"azure_attributes.0.spot_bid_max_price to be at least (-1.000000), got -2.000000")
+}
+
+func TestResourceClusterCreate_AzureInvalidAvailability(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Spot"
+		spark_version = "9.1.x-scala2.12"
+		node_type_id = "Standard_DS3_v2"
+		num_workers = 2
+		azure_attributes {
+			availability = "SPOT"
+		}
+		`,
+	}.ExpectError(t, "invalid config supplied. [azure_attributes.#.availability] expected "+
+		"azure_attributes.0.availability to be one of [ON_DEMAND_AZURE SPOT_AZURE SPOT_WITH_FALLBACK_AZURE], got SPOT")
+}
+
 func TestResourceClusterCreatePinned(t *testing.T) {
 	d, err := qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -455,6 +1272,171 @@ func TestResourceClusterRead(t *testing.T) {
 	}
 }
 
+func TestResourceClusterRead_WithoutLibraries(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             100,
+					ClusterName:            "Shared Autoscaling",
+					SparkVersion:           "7.1-scala12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					State:                  ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Resource: ResourceCluster(),
+		Read:     true,
+		ID:       "abc",
+		HCL: `
+		cluster_name = "Shared Autoscaling"
+		spark_version = "7.1-scala12"
+		node_type_id = "i3.xlarge"
+		num_workers = 100
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	// libraries of databricks_library resources are not read into the cluster
+	assert.Equal(t, 0, d.Get("library.#"))
+}
+
+func TestResourceClusterRead_PinnedWithoutRecentEvents(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             100,
+					ClusterName:            "Shared Autoscaling",
+					SparkVersion:           "7.1-scala12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					State:                  ClusterStateTerminated,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Resource: ResourceCluster(),
+		Read:     true,
+		ID:       "abc",
+		HCL: `
+		cluster_name = "Shared Autoscaling"
+		spark_version = "7.1-scala12"
+		node_type_id = "i3.xlarge"
+		num_workers = 100
+		is_pinned = true
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	// pin event of the cluster is older than retention of events
+	assert.Equal(t, true, d.Get("is_pinned"))
+}
+
+func TestResourceClusterRead_ClusterLogConf(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID:              "abc",
+					NumWorkers:             100,
+					ClusterName:            "Shared Autoscaling",
+					SparkVersion:           "7.1-scala12",
+					NodeTypeID:             "i3.xlarge",
+					AutoterminationMinutes: 15,
+					State:                  ClusterStateRunning,
+					ClusterLogConf: &StorageInfo{
+						S3: &S3StorageInfo{
+							Destination:      "s3://acmecorp-main/cluster-logs",
+							Region:           "us-east-1",
+							EnableEncryption: true,
+							EncryptionType:   "sse-kms",
+							KmsKey:           "arn:aws:kms:us-east-1:123:key/abc",
+							CannedACL:        "bucket-owner-full-control",
+						},
+					},
+					ClusterLogStatus: &LogSyncStatus{
+						LastAttempted: 1637002526000,
+						LastException: "AmazonS3Exception: Access Denied",
+					},
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/events",
+				Response: EventsResponse{
+					Events:     []ClusterEvent{},
+					TotalCount: 0,
+				},
+			},
+		},
+		Resource: ResourceCluster(),
+		Read:     true,
+		ID:       "abc",
+		HCL: `
+		cluster_name = "Shared Autoscaling"
+		spark_version = "7.1-scala12"
+		node_type_id = "i3.xlarge"
+		num_workers = 100
+		cluster_log_conf {
+			s3 {
+				destination = "s3://acmecorp-main/logs"
+				region = "us-east-1"
+			}
+		}
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	// changes made outside of terraform are detected
+	assert.Equal(t, "s3://acmecorp-main/cluster-logs", d.Get("cluster_log_conf.0.s3.0.destination"))
+	assert.Equal(t, "sse-kms", d.Get("cluster_log_conf.0.s3.0.encryption_type"))
+	assert.Equal(t, "arn:aws:kms:us-east-1:123:key/abc", d.Get("cluster_log_conf.0.s3.0.kms_key"))
+	assert.Equal(t, "bucket-owner-full-control", d.Get("cluster_log_conf.0.s3.0.canned_acl"))
+	assert.Equal(t, 1637002526000, d.Get("cluster_log_status.0.last_attempted"))
+	assert.Equal(t, "AmazonS3Exception: Access Denied", d.Get("cluster_log_status.0.last_exception"))
+}
+
+func TestResourceClusterCreate_InvalidClusterLogConf(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceCluster(),
+		HCL: `
+		cluster_name = "Shared Autoscaling"
+		spark_version = "7.1-scala12"
+		node_type_id = "i3.xlarge"
+		num_workers = 1
+		cluster_log_conf {
+			dbfs {
+				destination = "/cluster-logs"
+			}
+		}
+		`,
+	}.ExpectError(t, "invalid config supplied. [cluster_log_conf.#.dbfs.#.destination] "+
+		"invalid value for cluster_log_conf.0.dbfs.0.destination (must start with dbfs:/)")
+}
+
 func TestResourceClusterRead_NotFound(t *testing.T) {
 	qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -60,15 +60,20 @@ func ResourceInstancePool() *schema.Resource {
 		s["node_type_id"].ForceNew = true
 		s["custom_tags"].ForceNew = true
 		s["preloaded_spark_versions"].ForceNew = true
+		// the API installs at most one runtime version on pool instances
+		s["preloaded_spark_versions"].MaxItems = 1
 		s["preloaded_docker_image"].ForceNew = true
 		s["azure_attributes"].ForceNew = true
+		s["gcp_attributes"].ForceNew = true
 		s["disk_spec"].ForceNew = true
 		s["enable_elastic_disk"].ForceNew = true
 		s["enable_elastic_disk"].Default = true
-		s["aws_attributes"].ConflictsWith = []string{"azure_attributes"}
-		s["azure_attributes"].ConflictsWith = []string{"aws_attributes"}
+		s["aws_attributes"].ConflictsWith = []string{"azure_attributes", "gcp_attributes"}
+		s["azure_attributes"].ConflictsWith = []string{"aws_attributes", "gcp_attributes"}
+		s["gcp_attributes"].ConflictsWith = []string{"aws_attributes", "azure_attributes"}
 		s["aws_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("aws_attributes.#")
 		s["azure_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("azure_attributes.#")
+		s["gcp_attributes"].DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("gcp_attributes.#")
 		if v, err := common.SchemaPath(s, "aws_attributes", "availability"); err == nil {
 			v.ForceNew = true
 			v.Default = AwsAvailabilitySpot
@@ -94,6 +99,20 @@ func ResourceInstancePool() *schema.Resource {
 		}
 		if v, err := common.SchemaPath(s, "azure_attributes", "spot_bid_max_price"); err == nil {
 			v.ForceNew = true
+			// -1 evicts spot instances only because of capacity, never because of price
+			v.ValidateFunc = validation.FloatAtLeast(-1)
+		}
+		if v, err := common.SchemaPath(s, "gcp_attributes", "gcp_availability"); err == nil {
+			v.ForceNew = true
+			v.Default = GcpAvailabilityOnDemand
+			v.ValidateFunc = validation.StringInSlice([]string{
+				GcpAvailabilityOnDemand,
+				GcpAvailabilityPreemptible,
+				GcpAvailabilityPreemptibleWithFallback,
+			}, false)
+		}
+		if v, err := common.SchemaPath(s, "gcp_attributes", "local_ssd_count"); err == nil {
+			v.ForceNew = true
 		}
 		if v, err := common.SchemaPath(s, "disk_spec", "disk_type", "azure_disk_volume_type"); err == nil {
 			v.ForceNew = true
@@ -119,6 +138,7 @@ func ResourceInstancePool() *schema.Resource {
 		}
 		if v, err := common.SchemaPath(s, "preloaded_docker_image", "basic_auth", "password"); err == nil {
 			v.ForceNew = true
+			v.Sensitive = true
 		}
 		return s
 	})
@@ -141,6 +161,11 @@ func ResourceInstancePool() *schema.Resource {
 			if err != nil {
 				return err
 			}
+			var known InstancePool
+			if err = common.DataToStructPointer(d, s, &known); err != nil {
+				return err
+			}
+			keepDockerCredentials(ip.PreloadedDockerImages, known.PreloadedDockerImages)
 			return common.StructToData(ip, s, d)
 		},
 		Update: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
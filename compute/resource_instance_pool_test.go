@@ -115,6 +115,173 @@ func TestResourceInstancePoolCreate(t *testing.T) {
 	assert.Equal(t, "abc", d.Id())
 }
 
+func TestResourceInstancePoolCreate_Gcp(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/instance-pools/create",
+				ExpectedRequest: InstancePool{
+					InstancePoolName:                   "Shared Pool",
+					MaxCapacity:                        1000,
+					NodeTypeID:                         "n1-standard-4",
+					IdleInstanceAutoTerminationMinutes: 15,
+					EnableElasticDisk:                  true,
+					GcpAttributes: &InstancePoolGcpAttributes{
+						Availability:  GcpAvailabilityPreemptible,
+						LocalSsdCount: 2,
+					},
+				},
+				Response: InstancePoolAndStats{
+					InstancePoolID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/get?instance_pool_id=abc",
+				Response: InstancePoolAndStats{
+					InstancePoolID:                     "abc",
+					InstancePoolName:                   "Shared Pool",
+					MaxCapacity:                        1000,
+					NodeTypeID:                         "n1-standard-4",
+					IdleInstanceAutoTerminationMinutes: 15,
+					EnableElasticDisk:                  true,
+					GcpAttributes: &InstancePoolGcpAttributes{
+						Availability:  GcpAvailabilityPreemptible,
+						LocalSsdCount: 2,
+					},
+				},
+			},
+		},
+		Resource: ResourceInstancePool(),
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "n1-standard-4"
+		idle_instance_autotermination_minutes = 15
+		gcp_attributes {
+			gcp_availability = "PREEMPTIBLE_GCP"
+			local_ssd_count = 2
+		}
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "PREEMPTIBLE_GCP", d.Get("gcp_attributes.0.gcp_availability"))
+	assert.Equal(t, 2, d.Get("gcp_attributes.0.local_ssd_count"))
+}
+
+func TestResourceInstancePoolCreate_GcpConflictsWithAws(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceInstancePool(),
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "n1-standard-4"
+		idle_instance_autotermination_minutes = 15
+		aws_attributes {
+			availability = "SPOT"
+		}
+		gcp_attributes {
+			gcp_availability = "PREEMPTIBLE_GCP"
+		}
+		`,
+		Create: true,
+	}.ExpectError(t, "invalid config supplied. [aws_attributes] Conflicting configuration arguments. "+
+		"[gcp_attributes] Conflicting configuration arguments")
+}
+
+func TestResourceInstancePoolCreate_AzureSpot(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/instance-pools/create",
+				ExpectedRequest: InstancePool{
+					InstancePoolName:                   "Shared Pool",
+					MaxCapacity:                        1000,
+					NodeTypeID:                         "Standard_DS3_v2",
+					IdleInstanceAutoTerminationMinutes: 15,
+					EnableElasticDisk:                  true,
+					AzureAttributes: &InstancePoolAzureAttributes{
+						Availability:    AzureAvailabilitySpot,
+						SpotBidMaxPrice: -1,
+					},
+				},
+				Response: InstancePoolAndStats{
+					InstancePoolID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/get?instance_pool_id=abc",
+				Response: InstancePoolAndStats{
+					InstancePoolID:                     "abc",
+					InstancePoolName:                   "Shared Pool",
+					MaxCapacity:                        1000,
+					NodeTypeID:                         "Standard_DS3_v2",
+					IdleInstanceAutoTerminationMinutes: 15,
+					EnableElasticDisk:                  true,
+					AzureAttributes: &InstancePoolAzureAttributes{
+						Availability:    AzureAvailabilitySpot,
+						SpotBidMaxPrice: -1,
+					},
+				},
+			},
+		},
+		Resource: ResourceInstancePool(),
+		Azure:    true,
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "Standard_DS3_v2"
+		idle_instance_autotermination_minutes = 15
+		azure_attributes {
+			availability = "SPOT_AZURE"
+			spot_bid_max_price = -1
+		}
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "SPOT_AZURE", d.Get("azure_attributes.0.availability"))
+	assert.Equal(t, -1.0, d.Get("azure_attributes.0.spot_bid_max_price"))
+}
+
+func TestResourceInstancePoolCreate_AzureInvalidSpotBidMaxPrice(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceInstancePool(),
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "Standard_DS3_v2"
+		idle_instance_autotermination_minutes = 15
+		azure_attributes {
+			availability = "SPOT_AZURE"
+			spot_bid_max_price = -2
+		}
+		`,
+		Create: true,
+	}.ExpectError(t, "invalid config supplied. [azure_attributes.#.spot_bid_max_price] expected "+
+		"azure_attributes.0.spot_bid_max_price to be at least (-1.000000), got -2.000000")
+}
+
+func TestResourceInstancePoolCreate_PreloadedSparkVersionsMaxItems(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceInstancePool(),
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "i3.xlarge"
+		idle_instance_autotermination_minutes = 15
+		preloaded_spark_versions = ["9.1.x-scala2.12", "10.0.x-scala2.12"]
+		`,
+		Create: true,
+	}.ExpectError(t, "invalid config supplied. [preloaded_spark_versions] Too many list items")
+}
+
 func TestResourceInstancePoolCreate_Error(t *testing.T) {
 	d, err := qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -173,6 +340,54 @@ func TestResourceInstancePoolRead(t *testing.T) {
 	assert.Equal(t, "i3.xlarge", d.Get("node_type_id"))
 }
 
+func TestResourceInstancePoolRead_KeepsDockerPassword(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/instance-pools/get?instance_pool_id=abc",
+				Response: InstancePoolAndStats{
+					InstancePoolID:                     "abc",
+					InstancePoolName:                   "Shared Pool",
+					MaxCapacity:                        1000,
+					NodeTypeID:                         "i3.xlarge",
+					IdleInstanceAutoTerminationMinutes: 15,
+					PreloadedDockerImages: []DockerImage{
+						{
+							URL: "acr.io/sample:latest",
+							BasicAuth: &DockerBasicAuth{
+								Username: "admin",
+							},
+						},
+					},
+				},
+			},
+		},
+		Resource: ResourceInstancePool(),
+		Read:     true,
+		ID:       "abc",
+		HCL: `
+		instance_pool_name = "Shared Pool"
+		max_capacity = 1000
+		node_type_id = "i3.xlarge"
+		idle_instance_autotermination_minutes = 15
+		preloaded_docker_image {
+			url = "acr.io/sample:latest"
+			basic_auth {
+				username = "admin"
+				password = "secret"
+			}
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	var pool InstancePool
+	err = common.DataToStructPointer(d, ResourceInstancePool().Schema, &pool)
+	assert.NoError(t, err, err)
+	assert.Len(t, pool.PreloadedDockerImages, 1)
+	assert.Equal(t, "secret", pool.PreloadedDockerImages[0].BasicAuth.Password)
+}
+
 func TestResourceInstancePoolRead_NotFound(t *testing.T) {
 	qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -2,6 +2,7 @@ package compute
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
@@ -28,7 +29,13 @@ type JobsAPI struct {
 
 // List all jobs
 func (a JobsAPI) List() (l JobList, err error) {
-	err = a.client.Get(a.context, "/jobs/list", nil, &l)
+	err = a.client.Paginate(a.context, "/jobs/list", common.OffsetPages, nil,
+		func(raw json.RawMessage) (int, error) {
+			var page JobList
+			err := json.Unmarshal(raw, &page)
+			l.Jobs = append(l.Jobs, page.Jobs...)
+			return len(page.Jobs), err
+		})
 	return
 }
 
@@ -211,6 +218,22 @@ var jobSchema = common.StructToSchema(JobSettings{},
 				return false
 			}
 		}
+		if v, err := common.SchemaPath(s, "new_cluster", "runtime_engine"); err == nil {
+			v.ValidateFunc = validation.StringInSlice([]string{
+				RuntimeEnginePhoton,
+				RuntimeEngineStandard,
+			}, false)
+		}
+		if v, err := common.SchemaPath(s, "new_cluster", "data_security_mode"); err == nil {
+			v.ValidateFunc = validation.StringInSlice(dataSecurityModes, false)
+		}
+		for _, client := range []string{"jobs", "notebooks"} {
+			if v, err := common.SchemaPath(s, "new_cluster", "workload_type", "clients", client); err == nil {
+				v.Required = false
+				v.Optional = true
+				v.Default = true
+			}
+		}
 		if v, err := common.SchemaPath(s, "new_cluster", "aws_attributes"); err == nil {
 			v.DiffSuppressFunc = common.MakeEmptyBlockSuppressFunc("new_cluster.0.aws_attributes.#")
 		}
@@ -250,7 +273,19 @@ func ResourceJob() *schema.Resource {
 			if alwaysRunning && maxConcurrentRuns > 1 {
 				return fmt.Errorf("`always_running` must be specified only with `max_concurrent_runs = 1`")
 			}
-			return nil
+			err := validateDataSecurityModeDiff(d, "new_cluster.0.")
+			if err != nil {
+				return err
+			}
+			if !d.HasChange("new_cluster.0.runtime_engine") && !d.HasChange("new_cluster.0.node_type_id") &&
+				!d.HasChange("new_cluster.0.driver_node_type_id") && !d.HasChange("new_cluster.0.spark_version") {
+				return nil
+			}
+			return validateRuntimeEngine(ctx, c,
+				d.Get("new_cluster.0.runtime_engine").(string),
+				d.Get("new_cluster.0.spark_version").(string),
+				d.Get("new_cluster.0.node_type_id").(string),
+				d.Get("new_cluster.0.driver_node_type_id").(string))
 		},
 		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
 			var js JobSettings
@@ -262,6 +297,9 @@ func ResourceJob() *schema.Resource {
 				if err = validateClusterDefinition(*js.NewCluster); err != nil {
 					return err
 				}
+				if err = validateInitScripts(c, js.NewCluster.InitScripts); err != nil {
+					return err
+				}
 			}
 			jobsAPI := NewJobsAPI(ctx, c)
 			job, err := jobsAPI.Create(js)
@@ -293,6 +331,10 @@ func ResourceJob() *schema.Resource {
 				if err != nil {
 					return err
 				}
+				err = validateInitScripts(c, js.NewCluster.InitScripts)
+				if err != nil {
+					return err
+				}
 			}
 			jobsAPI := NewJobsAPI(ctx, c)
 			err = jobsAPI.Update(d.Id(), js)
@@ -357,6 +357,85 @@ func TestResourceJobCreateSingleNode_Fail(t *testing.T) {
 	require.Equal(t, true, strings.Contains(err.Error(), "NumWorkers could be 0 only for SingleNode clusters"))
 }
 
+func TestResourceJobCreate_PhotonNotSupportedByNodeType(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/list-node-types",
+				Response: NodeTypeList{
+					NodeTypes: []NodeType{
+						{
+							NodeTypeID: "Standard_DS3_v2",
+						},
+					},
+				},
+			},
+		},
+		Create:   true,
+		Resource: ResourceJob(),
+		HCL: `new_cluster  {
+			num_workers    = 1
+			spark_version  = "11.3.x-scala2.12"
+			runtime_engine = "PHOTON"
+			node_type_id   = "Standard_DS3_v2"
+		}
+		name = "Featurizer"
+
+		spark_jar_task {
+			main_class_name = "com.labs.BarMain"
+		}`,
+	}.ExpectError(t, "node type Standard_DS3_v2 doesn't support Photon")
+}
+
+func TestResourceJobCreate_SingleUserWithoutUserName(t *testing.T) {
+	qa.ResourceFixture{
+		Create:   true,
+		Resource: ResourceJob(),
+		HCL: `new_cluster  {
+			num_workers        = 1
+			spark_version      = "11.3.x-scala2.12"
+			node_type_id       = "Standard_DS3_v2"
+			data_security_mode = "SINGLE_USER"
+		}
+		name = "Featurizer"
+
+		spark_jar_task {
+			main_class_name = "com.labs.BarMain"
+		}`,
+	}.ExpectError(t, "data_security_mode SINGLE_USER requires single_user_name")
+}
+
+func TestResourceJobCreate_SingleUserNameUnknownAtPlan(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/jobs/create",
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_REQUEST",
+					Message:   "reached the API",
+				},
+				Status: 400,
+			},
+		},
+		Create:   true,
+		Resource: ResourceJob(),
+		HCL: `new_cluster  {
+			num_workers        = 1
+			spark_version      = "11.3.x-scala2.12"
+			node_type_id       = "Standard_DS3_v2"
+			data_security_mode = "SINGLE_USER"
+			single_user_name   = "` + qa.UnknownValue + `"
+		}
+		name = "Featurizer"
+
+		spark_jar_task {
+			main_class_name = "com.labs.BarMain"
+		}`,
+	}.ExpectError(t, "reached the API")
+}
+
 func TestResourceJobCreate_Error(t *testing.T) {
 	d, err := qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -938,3 +1017,28 @@ func TestJobsAPIRunsList(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, l.Runs, 1)
 }
+
+func TestJobsAPIList_Paginated(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/jobs/list",
+			Response: JobList{
+				Jobs:    []Job{{JobID: 1}, {JobID: 2}},
+				HasMore: true,
+			},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/jobs/list?offset=2",
+			Response: JobList{
+				Jobs: []Job{{JobID: 3}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		l, err := NewJobsAPI(ctx, client).List()
+		require.NoError(t, err)
+		assert.Len(t, l.Jobs, 3)
+		assert.Equal(t, int64(3), l.Jobs[2].JobID)
+	})
+}
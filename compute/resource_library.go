@@ -0,0 +1,123 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func forceNewAll(s map[string]*schema.Schema) {
+	for _, v := range s {
+		if v.Optional || v.Required {
+			v.ForceNew = true
+		}
+		if r, ok := v.Elem.(*schema.Resource); ok {
+			forceNewAll(r.Schema)
+		}
+	}
+}
+
+// ResourceLibrary installs a single library on a cluster, independently of library blocks
+// of databricks_cluster, so that libraries of shared clusters can be owned by different modules
+func ResourceLibrary() *schema.Resource {
+	s := common.StructToSchema(Library{}, func(s map[string]*schema.Schema) map[string]*schema.Schema {
+		s["cluster_id"] = &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		}
+		forceNewAll(s)
+		return s
+	})
+	parseID := func(d *schema.ResourceData) (string, string, error) {
+		parts := strings.SplitN(d.Id(), "/", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("invalid ID: %s", d.Id())
+		}
+		return parts[0], parts[1], nil
+	}
+	return common.Resource{
+		Schema: s,
+		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			var library Library
+			if err := common.DataToStructPointer(d, s, &library); err != nil {
+				return err
+			}
+			libraryType, key := library.TypeAndKey()
+			if libraryType == "" {
+				return fmt.Errorf("one of jar, egg, whl, pypi, maven or cran must be specified")
+			}
+			clusterID := d.Get("cluster_id").(string)
+			clusters := NewClustersAPI(ctx, c)
+			clusterInfo, err := clusters.Get(clusterID)
+			if err != nil {
+				return err
+			}
+			if !clusterInfo.IsRunningOrResizing() {
+				// libraries are installed only on running clusters
+				if _, err = clusters.StartAndGetInfo(clusterID); err != nil {
+					return err
+				}
+			}
+			libraries := NewLibrariesAPI(ctx, c)
+			err = libraries.Install(ClusterLibraryList{
+				ClusterID: clusterID,
+				Libraries: []Library{library},
+			})
+			if err != nil {
+				return err
+			}
+			d.SetId(fmt.Sprintf("%s/%s", clusterID, key))
+			if err = libraries.waitForLibraryInstalled(clusterID, key); err != nil {
+				return err
+			}
+			if clusterInfo.State == ClusterStateTerminated {
+				log.Printf("[INFO] %s was in TERMINATED state, so terminating it again", clusterID)
+				return clusters.Terminate(clusterID)
+			}
+			return nil
+		},
+		Read: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			clusterID, key, err := parseID(d)
+			if err != nil {
+				return err
+			}
+			cls, err := NewLibrariesAPI(ctx, c).ClusterStatus(clusterID)
+			if err != nil {
+				return err
+			}
+			status, ok := cls.Find(key)
+			if !ok {
+				return common.NotFound(fmt.Sprintf("library %s is not installed on cluster %s", key, clusterID))
+			}
+			if err = common.StructToData(*status.Library, s, d); err != nil {
+				return err
+			}
+			return d.Set("cluster_id", clusterID)
+		},
+		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			clusterID, key, err := parseID(d)
+			if err != nil {
+				return err
+			}
+			libraries := NewLibrariesAPI(ctx, c)
+			cls, err := libraries.ClusterStatus(clusterID)
+			if err != nil {
+				return err
+			}
+			status, ok := cls.Find(key)
+			if !ok {
+				return nil
+			}
+			// library is removed from the cluster after its restart
+			return libraries.Uninstall(ClusterLibraryList{
+				ClusterID: clusterID,
+				Libraries: []Library{*status.Library},
+			})
+		},
+	}.ToResource()
+}
@@ -0,0 +1,378 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+var requestsLibrary = Library{
+	Pypi: &PyPi{
+		Package: "requests",
+	},
+}
+
+func libraryStatuses(status string) ClusterLibraryStatuses {
+	return ClusterLibraryStatuses{
+		ClusterID: "abc",
+		LibraryStatuses: []LibraryStatus{
+			{
+				Library: &Library{Jar: "dbfs:/FileStore/other.jar"},
+				Status:  "FAILED",
+			},
+			{
+				Library: &requestsLibrary,
+				Status:  status,
+			},
+		},
+	}
+}
+
+func TestResourceLibraryCreate(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				ReuseRequest: true,
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/libraries/install",
+				ExpectedRequest: ClusterLibraryList{
+					ClusterID: "abc",
+					Libraries: []Library{requestsLibrary},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("INSTALLING"),
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response:     libraryStatuses("INSTALLED"),
+				ReuseRequest: true,
+			},
+		},
+		Resource: ResourceLibrary(),
+		Create:   true,
+		HCL: `
+		cluster_id = "abc"
+		pypi {
+			package = "requests"
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc/requests", d.Id())
+	assert.Equal(t, "requests", d.Get("pypi.0.package"))
+}
+
+func TestResourceLibraryCreate_ClusterRestarted(t *testing.T) {
+	install := qa.HTTPFixture{
+		Method:   "POST",
+		Resource: "/api/2.0/libraries/install",
+		ExpectedRequest: ClusterLibraryList{
+			ClusterID: "abc",
+			Libraries: []Library{requestsLibrary},
+		},
+	}
+	_, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			install,
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("PENDING"),
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("PENDING"),
+			},
+			{
+				// installation is lost during restart
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRestarting,
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("PENDING"),
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/clusters/get?cluster_id=abc",
+				ReuseRequest: true,
+				Response: ClusterInfo{
+					ClusterID:         "abc",
+					State:             ClusterStateRunning,
+					LastRestartedTime: 1637002526000,
+				},
+			},
+			install,
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response:     libraryStatuses("INSTALLED"),
+				ReuseRequest: true,
+			},
+		},
+		Resource: ResourceLibrary(),
+		Create:   true,
+		HCL: `
+		cluster_id = "abc"
+		pypi {
+			package = "requests"
+		}
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+}
+
+func TestResourceLibraryCreate_TerminatedCluster(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateTerminated,
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateTerminated,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/start",
+				ExpectedRequest: ClusterID{
+					ClusterID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/libraries/install",
+				ExpectedRequest: ClusterLibraryList{
+					ClusterID: "abc",
+					Libraries: []Library{{Whl: "dbfs:/FileStore/foo.whl"}},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: ClusterLibraryStatuses{
+					ClusterID: "abc",
+					LibraryStatuses: []LibraryStatus{
+						{
+							Library: &Library{Whl: "dbfs:/FileStore/foo.whl"},
+							Status:  "INSTALLED",
+						},
+					},
+				},
+				ReuseRequest: true,
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/clusters/delete",
+				ExpectedRequest: ClusterID{
+					ClusterID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateTerminated,
+				},
+			},
+		},
+		Resource: ResourceLibrary(),
+		Create:   true,
+		HCL: `
+		cluster_id = "abc"
+		whl = "dbfs:/FileStore/foo.whl"
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc/dbfs:/FileStore/foo.whl", d.Id())
+	assert.Equal(t, "dbfs:/FileStore/foo.whl", d.Get("whl"))
+}
+
+func TestResourceLibraryCreate_Failed(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/clusters/get?cluster_id=abc",
+				Response: ClusterInfo{
+					ClusterID: "abc",
+					State:     ClusterStateRunning,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/libraries/install",
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: ClusterLibraryStatuses{
+					ClusterID: "abc",
+					LibraryStatuses: []LibraryStatus{
+						{
+							Library:  &requestsLibrary,
+							Status:   "FAILED",
+							Messages: []string{"no such package"},
+						},
+					},
+				},
+			},
+		},
+		Resource: ResourceLibrary(),
+		Create:   true,
+		HCL: `
+		cluster_id = "abc"
+		pypi {
+			package = "requests"
+		}
+		`,
+	}.ExpectError(t, "library_pypi[requests] failed: no such package. "+
+		"Check that the package exists and that the cluster can reach its repository")
+}
+
+func TestResourceLibraryCreate_NoLibrary(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceLibrary(),
+		Create:   true,
+		HCL:      `cluster_id = "abc"`,
+	}.ExpectError(t, "one of jar, egg, whl, pypi, maven or cran must be specified")
+}
+
+func TestResourceLibraryRead(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("INSTALLED"),
+			},
+		},
+		Resource: ResourceLibrary(),
+		Read:     true,
+		New:      true,
+		ID:       "abc/requests",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Get("cluster_id"))
+	assert.Equal(t, "requests", d.Get("pypi.0.package"))
+}
+
+func TestResourceLibraryRead_NotInstalled(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: ClusterLibraryStatuses{ClusterID: "abc"},
+			},
+		},
+		Resource: ResourceLibrary(),
+		Read:     true,
+		Removed:  true,
+		ID:       "abc/requests",
+	}.ApplyNoError(t)
+}
+
+func TestResourceLibraryRead_InvalidID(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceLibrary(),
+		Read:     true,
+		ID:       "abc",
+	}.ExpectError(t, "invalid ID: abc")
+}
+
+func TestResourceLibraryDelete(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Response: libraryStatuses("INSTALLED"),
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/libraries/uninstall",
+				ExpectedRequest: ClusterLibraryList{
+					ClusterID: "abc",
+					Libraries: []Library{requestsLibrary},
+				},
+			},
+		},
+		Resource: ResourceLibrary(),
+		Delete:   true,
+		ID:       "abc/requests",
+	}.ApplyNoError(t)
+}
+
+func TestResourceLibraryDelete_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/libraries/cluster-status?cluster_id=abc",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Cluster abc does not exist",
+				},
+			},
+		},
+		Resource: ResourceLibrary(),
+		Delete:   true,
+		ID:       "abc/requests",
+	}.ExpectError(t, "Cluster abc does not exist")
+}
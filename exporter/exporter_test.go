@@ -204,7 +204,7 @@ func TestImportingUsersGroupsSecretScopes(t *testing.T) {
 			meAdminFixture,
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Groups?",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100",
 				Response: identity.GroupList{
 					Resources: []identity.ScimGroup{
 						// TODO: add another user for which there is no filter resut
@@ -265,7 +265,7 @@ func TestImportingUsersGroupsSecretScopes(t *testing.T) {
 			},
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20%27test%40test.com%27",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27test%40test.com%27",
 				Response: identity.UserList{
 					Resources: []identity.ScimUser{
 						{ID: "123", DisplayName: "test@test.com", UserName: "test@test.com"},
@@ -356,7 +356,7 @@ func TestImportingNoResourcesError(t *testing.T) {
 			meAdminFixture,
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Groups?",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100",
 				Response: identity.GroupList{Resources: []identity.ScimGroup{}},
 			},
 			{
@@ -406,7 +406,7 @@ func TestImportingClusters(t *testing.T) {
 			meAdminFixture,
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Groups?",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100",
 				Response: identity.GroupList{Resources: []identity.ScimGroup{}},
 			},
 			{
@@ -770,7 +770,7 @@ func TestImportingSecrets(t *testing.T) {
 			meAdminFixture,
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Groups?",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100",
 				Response: identity.GroupList{Resources: []identity.ScimGroup{}},
 			},
 			{
@@ -885,7 +885,7 @@ func TestImportingUser(t *testing.T) {
 			{
 				Method:       "GET",
 				ReuseRequest: true,
-				Resource:     "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20%27me%27",
+				Resource:     "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27me%27",
 				Response: identity.UserList{
 					Resources: []identity.ScimUser{
 						{
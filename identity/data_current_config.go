@@ -0,0 +1,70 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DataSourceCurrentConfig returns effective configuration of the provider after authentication
+func DataSourceCurrentConfig() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"account_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"auth_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"is_azure": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"is_aws": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"is_gcp": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"user_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			client := m.(*common.DatabricksClient)
+			err := client.Authenticate()
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			me := ScimUser{}
+			if !client.IsAccountClient() {
+				// caller identity verifies, that credentials are accepted by the workspace.
+				// Accounts console has no /Me endpoint, so user_name is left empty there.
+				me, err = NewUsersAPI(ctx, m).Me()
+				if err != nil {
+					return common.DiagFromErr(err)
+				}
+			}
+			d.Set("host", client.Host)
+			d.Set("account_id", client.AccountID)
+			d.Set("auth_type", client.EffectiveAuthType())
+			d.Set("is_azure", client.IsAzure())
+			d.Set("is_aws", client.IsAws())
+			d.Set("is_gcp", client.IsGcp())
+			d.Set("user_name", me.UserName)
+			d.SetId(client.Host)
+			return nil
+		},
+	}
+}
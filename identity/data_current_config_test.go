@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceCurrentConfig(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Me",
+				Response: ScimUser{
+					ID:       "123",
+					UserName: "mr.test@example.com",
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceCurrentConfig(),
+		ID:          ".",
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, "pat", d.Get("auth_type"))
+	assert.Equal(t, true, d.Get("is_aws"))
+	assert.Equal(t, false, d.Get("is_azure"))
+	assert.Equal(t, false, d.Get("is_gcp"))
+	assert.Equal(t, "mr.test@example.com", d.Get("user_name"))
+	assert.Equal(t, d.Id(), d.Get("host"))
+}
+
+func TestDataSourceCurrentConfig_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Me",
+				Response: common.APIErrorBody{
+					ErrorCode: "PERMISSION_DENIED",
+					Message:   "Invalid access token",
+				},
+				Status: 403,
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceCurrentConfig(),
+		ID:          ".",
+	}.ExpectError(t, "Invalid access token")
+}
+
+func TestDataSourceCurrentConfig_AccountClient(t *testing.T) {
+	client := &common.DatabricksClient{
+		Host:      "https://accounts.cloud.databricks.com",
+		Token:     "x",
+		AccountID: "abc",
+	}
+	require.NoError(t, client.Configure())
+	r := DataSourceCurrentConfig()
+	d := r.TestResourceData()
+	// no requests are made, as /Me is not available in accounts console
+	diags := r.ReadContext(context.Background(), d, client)
+	require.Len(t, diags, 0)
+	assert.Equal(t, "https://accounts.cloud.databricks.com", d.Get("host"))
+	assert.Equal(t, "abc", d.Get("account_id"))
+	assert.Equal(t, "", d.Get("user_name"))
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -24,6 +25,10 @@ func DataSourceCurrentUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"repos": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"alphanumeric": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -33,10 +38,11 @@ func DataSourceCurrentUser() *schema.Resource {
 			usersAPI := NewUsersAPI(ctx, m)
 			me, err := usersAPI.Me()
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.Set("user_name", me.UserName)
 			d.Set("home", fmt.Sprintf("/Users/%s", me.UserName))
+			d.Set("repos", fmt.Sprintf("/Repos/%s", me.UserName))
 			splits := strings.Split(me.UserName, "@")
 			norm := nonAlphanumeric.ReplaceAllLiteralString(splits[0], "_")
 			norm = strings.ToLower(norm)
@@ -10,14 +10,50 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// memberDetail describes a member of the group for audit reports
+type memberDetail struct {
+	ID            string `json:"id,omitempty" tf:"computed"`
+	Type          string `json:"type,omitempty" tf:"computed"`
+	DisplayName   string `json:"display_name,omitempty" tf:"computed"`
+	UserName      string `json:"user_name,omitempty" tf:"computed"`
+	ApplicationID string `json:"application_id,omitempty" tf:"computed"`
+}
+
+// resolveMemberDetail reads user or service principal behind the member reference.
+// Members without $ref are considered to be users, just like in expandMembers.
+func resolveMemberDetail(ctx context.Context, c *common.DatabricksClient,
+	member ComplexValue) (detail memberDetail, err error) {
+	detail = memberDetail{ID: member.Value, DisplayName: member.Display}
+	switch memberKind(member.Ref) {
+	case "Groups":
+		detail.Type = "group"
+	case "ServicePrincipals":
+		detail.Type = "service_principal"
+		var sp ScimUser
+		sp, err = NewServicePrincipalsAPI(ctx, c).read(member.Value)
+		detail.DisplayName = sp.DisplayName
+		detail.ApplicationID = sp.ApplicationID
+	default:
+		detail.Type = "user"
+		var user ScimUser
+		user, err = NewUsersAPI(ctx, c).read(member.Value)
+		detail.DisplayName = user.DisplayName
+		detail.UserName = user.UserName
+	}
+	return
+}
+
 // DataSourceGroup returns information about group specified by display name
 func DataSourceGroup() *schema.Resource {
 	type entity struct {
-		DisplayName      string   `json:"display_name"`
-		Recursive        bool     `json:"recursive,omitempty"`
-		Members          []string `json:"members,omitempty" tf:"slice_set,computed"`
-		Groups           []string `json:"groups,omitempty" tf:"slice_set,computed"`
-		InstanceProfiles []string `json:"instance_profiles,omitempty" tf:"slice_set,computed"`
+		DisplayName          string         `json:"display_name"`
+		Recursive            bool           `json:"recursive,omitempty"`
+		IncludeMemberDetails bool           `json:"include_member_details,omitempty"`
+		ExternalID           string         `json:"external_id,omitempty" tf:"computed"`
+		Members              []string       `json:"members,omitempty" tf:"slice_set,computed"`
+		MemberDetails        []memberDetail `json:"member_details,omitempty" tf:"computed"`
+		Groups               []string       `json:"groups,omitempty" tf:"slice_set,computed"`
+		InstanceProfiles     []string       `json:"instance_profiles,omitempty" tf:"slice_set,computed"`
 	}
 
 	s := common.StructToSchema(entity{}, func(
@@ -35,20 +71,23 @@ func DataSourceGroup() *schema.Resource {
 			var this entity
 			err := common.DataToStructPointer(d, s, &this)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			groupsAPI := NewGroupsAPI(ctx, m)
 			group, err := groupsAPI.ReadByDisplayName(this.DisplayName)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.SetId(group.ID)
+			this.ExternalID = group.ExternalID
+			members := map[string]ComplexValue{}
 			queue := []ScimGroup{group}
 			for len(queue) > 0 {
 				current := queue[0]
 				queue = queue[1:]
 				for _, x := range current.Members {
 					this.Members = append(this.Members, x.Value)
+					members[x.Value] = x
 				}
 				for _, x := range current.Roles {
 					this.InstanceProfiles = append(this.InstanceProfiles, x.Value)
@@ -59,7 +98,7 @@ func DataSourceGroup() *schema.Resource {
 					if this.Recursive {
 						childGroup, err := groupsAPI.Read(x.Value)
 						if err != nil {
-							return diag.FromErr(err)
+							return common.DiagFromErr(err)
 						}
 						queue = append(queue, childGroup)
 					}
@@ -68,9 +107,24 @@ func DataSourceGroup() *schema.Resource {
 			sort.Strings(this.Groups)
 			sort.Strings(this.Members)
 			sort.Strings(this.InstanceProfiles)
+			this.MemberDetails = nil
+			if this.IncludeMemberDetails {
+				c := m.(*common.DatabricksClient)
+				for _, id := range this.Members {
+					if len(this.MemberDetails) > 0 && this.MemberDetails[len(this.MemberDetails)-1].ID == id {
+						// the same member of more than one group
+						continue
+					}
+					detail, err := resolveMemberDetail(ctx, c, members[id])
+					if err != nil {
+						return common.DiagFromErr(err)
+					}
+					this.MemberDetails = append(this.MemberDetails, detail)
+				}
+			}
 			err = common.StructToData(this, s, d)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			return nil
 		},
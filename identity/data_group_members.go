@@ -0,0 +1,104 @@
+package identity
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// flatMembers are users and service principals of the group and all of its nested groups
+type flatMembers struct {
+	Users             []string
+	ServicePrincipals []string
+	Groups            []string
+}
+
+// memberKind returns SCIM endpoint of member $ref, like "Users" for Users/123 or full SCIM URL
+func memberKind(ref string) string {
+	parts := strings.Split(strings.Trim(ref, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
+// expandMembers walks nested groups breadth-first. Every group is read once,
+// even if it's nested more than once or there's a cycle.
+func (a GroupsAPI) expandMembers(group ScimGroup) (flat flatMembers, err error) {
+	// SCIM IDs are unique across users, service principals and groups
+	seen := map[string]bool{group.ID: true}
+	queue := []ScimGroup{group}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, member := range current.Members {
+			if seen[member.Value] {
+				continue
+			}
+			seen[member.Value] = true
+			switch memberKind(member.Ref) {
+			case "Groups":
+				flat.Groups = append(flat.Groups, member.Value)
+				var nested ScimGroup
+				nested, err = a.Read(member.Value)
+				if err != nil {
+					return
+				}
+				queue = append(queue, nested)
+			case "ServicePrincipals":
+				flat.ServicePrincipals = append(flat.ServicePrincipals, member.Value)
+			default:
+				flat.Users = append(flat.Users, member.Value)
+			}
+		}
+	}
+	for _, ids := range [][]string{flat.Users, flat.ServicePrincipals, flat.Groups} {
+		sort.Strings(ids)
+	}
+	return
+}
+
+// DataSourceGroupMembers returns users and service principals of the group, including members
+// of nested groups, for systems that don't understand nesting
+func DataSourceGroupMembers() *schema.Resource {
+	type entity struct {
+		GroupID           string   `json:"group_id"`
+		Users             []string `json:"users,omitempty" tf:"slice_set,computed"`
+		ServicePrincipals []string `json:"service_principals,omitempty" tf:"slice_set,computed"`
+		Groups            []string `json:"groups,omitempty" tf:"slice_set,computed"`
+	}
+	s := common.StructToSchema(entity{}, func(
+		s map[string]*schema.Schema) map[string]*schema.Schema {
+		return s
+	})
+	return &schema.Resource{
+		Schema: s,
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			groupsAPI := NewGroupsAPI(ctx, m)
+			groupID := d.Get("group_id").(string)
+			group, err := groupsAPI.Read(groupID)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			flat, err := groupsAPI.expandMembers(group)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			err = common.StructToData(entity{
+				GroupID:           groupID,
+				Users:             flat.Users,
+				ServicePrincipals: flat.ServicePrincipals,
+				Groups:            flat.Groups,
+			}, s, d)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			d.SetId(groupID)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceGroupMembers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/a",
+				Response: ScimGroup{
+					ID: "a",
+					Members: []ComplexValue{
+						{Value: "1", Ref: "Users/1"},
+						{Value: "b", Ref: "Groups/b"},
+						{Value: "c", Ref: "Groups/c"},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/b",
+				Response: ScimGroup{
+					ID: "b",
+					Members: []ComplexValue{
+						{Value: "2", Ref: "Users/2"},
+						{Value: "3", Ref: "ServicePrincipals/3"},
+						{Value: "c", Ref: "Groups/c"},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/c",
+				Response: ScimGroup{
+					ID: "c",
+					Members: []ComplexValue{
+						{Value: "1", Ref: "Users/1"},
+						// cycle back to the top group
+						{Value: "a", Ref: "Groups/a"},
+					},
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceGroupMembers(),
+		ID:          ".",
+		HCL:         `group_id = "a"`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, "a", d.Id())
+	users := d.Get("users").(*schema.Set)
+	assert.Equal(t, 2, users.Len())
+	assert.True(t, users.Contains("1"))
+	assert.True(t, users.Contains("2"))
+	assert.Equal(t, []interface{}{"3"}, d.Get("service_principals").(*schema.Set).List())
+	assert.Equal(t, 2, d.Get("groups").(*schema.Set).Len())
+}
+
+func TestDataSourceGroupMembers_NestedError(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/a",
+				Response: ScimGroup{
+					ID:      "a",
+					Members: []ComplexValue{{Value: "b", Ref: "Groups/b"}},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/b",
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_REQUEST",
+					Message:   "Internal error happened",
+				},
+				Status: 400,
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceGroupMembers(),
+		ID:          ".",
+		HCL:         `group_id = "a"`,
+	}.ExpectError(t, "Internal error happened")
+}
+
+func TestMemberKind(t *testing.T) {
+	assert.Equal(t, "Users", memberKind("Users/1"))
+	assert.Equal(t, "ServicePrincipals", memberKind("https://x/api/2.0/preview/scim/v2/ServicePrincipals/1"))
+	assert.Equal(t, "", memberKind(""))
+}
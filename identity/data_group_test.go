@@ -3,6 +3,7 @@ package identity
 import (
 	"testing"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/databrickslabs/terraform-provider-databricks/qa"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +19,7 @@ func TestDataSourceGroup(t *testing.T) {
 		Fixtures: []qa.HTTPFixture{
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Groups?filter=displayName%20eq%20%27ds%27",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27ds%27",
 				Response: GroupList{
 					Resources: []ScimGroup{
 						{
@@ -90,3 +91,90 @@ func TestDataSourceGroup(t *testing.T) {
 	assert.Equal(t, true, d.Get("allow_instance_pool_create"))
 	assert.Equal(t, true, d.Get("allow_cluster_create"))
 }
+
+func TestDataSourceGroup_MemberDetails(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27ds%27",
+				Response: GroupList{
+					Resources: []ScimGroup{
+						{
+							DisplayName: "ds",
+							ID:          "eerste",
+							Members: []ComplexValue{
+								{Value: "1", Ref: "Users/1", Display: "Me"},
+								{Value: "2", Ref: "ServicePrincipals/2", Display: "Robot"},
+								{Value: "3", Ref: "Groups/3", Display: "nested"},
+							},
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users/1",
+				Response: ScimUser{ID: "1", UserName: "me@example.com", DisplayName: "Me Myself"},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/ServicePrincipals/2",
+				Response: ScimUser{ID: "2", ApplicationID: "abc-def", DisplayName: "Robot"},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceGroup(),
+		ID:          ".",
+		HCL: `
+		display_name = "ds"
+		include_member_details = true`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Get("member_details.#"))
+	assert.Equal(t, "1", d.Get("member_details.0.id"))
+	assert.Equal(t, "user", d.Get("member_details.0.type"))
+	assert.Equal(t, "me@example.com", d.Get("member_details.0.user_name"))
+	assert.Equal(t, "Me Myself", d.Get("member_details.0.display_name"))
+	assert.Equal(t, "service_principal", d.Get("member_details.1.type"))
+	assert.Equal(t, "abc-def", d.Get("member_details.1.application_id"))
+	assert.Equal(t, "group", d.Get("member_details.2.type"))
+	assert.Equal(t, "nested", d.Get("member_details.2.display_name"))
+}
+
+func TestDataSourceGroup_MemberDetailsError(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27ds%27",
+				Response: GroupList{
+					Resources: []ScimGroup{
+						{
+							DisplayName: "ds",
+							ID:          "eerste",
+							Members:     []ComplexValue{{Value: "1", Ref: "Users/1"}},
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users/1",
+				Status:   404,
+				Response: common.APIErrorBody{
+					ScimDetail: "User not found",
+					ScimStatus: "404",
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceGroup(),
+		ID:          ".",
+		HCL: `
+		display_name = "ds"
+		include_member_details = true`,
+	}.ExpectError(t, "User not found")
+}
@@ -0,0 +1,87 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// servicePrincipalsFilter builds SCIM filter from display name prefix and application ID
+func servicePrincipalsFilter(displayNamePrefix, applicationID string) string {
+	conditions := []string{}
+	if displayNamePrefix != "" {
+		conditions = append(conditions, fmt.Sprintf(`displayName sw "%s"`, displayNamePrefix))
+	}
+	if applicationID != "" {
+		conditions = append(conditions, fmt.Sprintf(`applicationId eq "%s"`, applicationID))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// DataSourceServicePrincipals returns IDs of service principals by display name prefix
+// or application ID, so that automation identities created outside of Terraform could be used
+func DataSourceServicePrincipals() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"display_name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"application_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"application_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"display_names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			filter := servicePrincipalsFilter(d.Get("display_name_prefix").(string),
+				d.Get("application_id").(string))
+			sps, err := NewServicePrincipalsAPI(ctx, m).Filter(filter)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			// display names are not unique, so application IDs break ties
+			sort.Slice(sps, func(i, j int) bool {
+				if sps[i].DisplayName != sps[j].DisplayName {
+					return sps[i].DisplayName < sps[j].DisplayName
+				}
+				return sps[i].ApplicationID < sps[j].ApplicationID
+			})
+			ids := []string{}
+			applicationIDs := []string{}
+			displayNames := []string{}
+			for _, sp := range sps {
+				ids = append(ids, sp.ID)
+				applicationIDs = append(applicationIDs, sp.ApplicationID)
+				displayNames = append(displayNames, sp.DisplayName)
+			}
+			d.Set("ids", ids)
+			d.Set("application_ids", applicationIDs)
+			d.Set("display_names", displayNames)
+			if filter == "" {
+				filter = "all"
+			}
+			d.SetId(filter)
+			return nil
+		},
+	}
+}
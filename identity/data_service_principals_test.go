@@ -0,0 +1,89 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServicePrincipalsFilter(t *testing.T) {
+	assert.Equal(t, "", servicePrincipalsFilter("", ""))
+	assert.Equal(t, `displayName sw "ci-"`, servicePrincipalsFilter("ci-", ""))
+	assert.Equal(t, `applicationId eq "abc"`, servicePrincipalsFilter("", "abc"))
+	assert.Equal(t, `displayName sw "ci-" and applicationId eq "abc"`,
+		servicePrincipalsFilter("ci-", "abc"))
+}
+
+func TestDataSourceServicePrincipals(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/ServicePrincipals?count=100&filter=displayName%20sw%20%22ci-%22",
+				Response: UserList{
+					Resources: []ScimUser{
+						{ID: "3", ApplicationID: "ccc", DisplayName: "ci-prod"},
+						{ID: "2", ApplicationID: "bbb", DisplayName: "ci-dev"},
+						{ID: "1", ApplicationID: "aaa", DisplayName: "ci-dev"},
+					},
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceServicePrincipals(),
+		ID:          ".",
+		HCL:         `display_name_prefix = "ci-"`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, `displayName sw "ci-"`, d.Id())
+	assert.Equal(t, []interface{}{"1", "2", "3"}, d.Get("ids"))
+	assert.Equal(t, []interface{}{"aaa", "bbb", "ccc"}, d.Get("application_ids"))
+	assert.Equal(t, []interface{}{"ci-dev", "ci-dev", "ci-prod"}, d.Get("display_names"))
+}
+
+func TestDataSourceServicePrincipals_ByApplicationID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/ServicePrincipals?count=100&filter=applicationId%20eq%20%22aaa%22",
+				Response: UserList{
+					Resources: []ScimUser{
+						{ID: "1", ApplicationID: "aaa", DisplayName: "ci-dev"},
+					},
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceServicePrincipals(),
+		ID:          ".",
+		HCL:         `application_id = "aaa"`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"1"}, d.Get("ids"))
+}
+
+func TestDataSourceServicePrincipals_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/ServicePrincipals?count=100",
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Invalid filter",
+				},
+				Status: 400,
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceServicePrincipals(),
+		ID:          ".",
+	}.ExpectError(t, "Invalid filter")
+}
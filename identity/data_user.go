@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -27,44 +28,69 @@ func getUser(usersAPI UsersAPI, id, name string) (user ScimUser, err error) {
 
 // DataSourceUser returns information about user specified by user name
 func DataSourceUser() *schema.Resource {
-	return &schema.Resource{
-		Schema: map[string]*schema.Schema{
-			"user_name": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: []string{"user_name", "user_id"},
-				Optional:     true,
-			},
-			"user_id": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: []string{"user_name", "user_id"},
-				Optional:     true,
-			},
-			"home": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"display_name": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"alphanumeric": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
+	s := map[string]*schema.Schema{
+		"user_name": {
+			Type:         schema.TypeString,
+			ExactlyOneOf: []string{"user_name", "user_id"},
+			Optional:     true,
+		},
+		"user_id": {
+			Type:         schema.TypeString,
+			ExactlyOneOf: []string{"user_name", "user_id"},
+			Optional:     true,
+		},
+		"home": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"display_name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"external_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"alphanumeric": {
+			Type:     schema.TypeString,
+			Computed: true,
 		},
+		"active": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"groups": {
+			Type:     schema.TypeSet,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	addComputedEntitlementsToSchema(s)
+	return &schema.Resource{
+		Schema: s,
 		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 			usersAPI := NewUsersAPI(ctx, m)
 			user, err := getUser(usersAPI, d.Get("user_id").(string), d.Get("user_name").(string))
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.Set("user_name", user.UserName)
 			d.Set("display_name", user.DisplayName)
+			d.Set("external_id", user.ExternalID)
 			d.Set("home", fmt.Sprintf("/Users/%s", user.UserName))
 			splits := strings.Split(user.UserName, "@")
 			norm := nonAlphanumeric.ReplaceAllLiteralString(splits[0], "_")
 			norm = strings.ToLower(norm)
 			d.Set("alphanumeric", norm)
+			d.Set("active", user.Active)
+			groups := []string{}
+			for _, group := range user.Groups {
+				groups = append(groups, group.Value)
+			}
+			d.Set("groups", groups)
+			if err = user.Entitlements.readIntoData(d); err != nil {
+				return common.DiagFromErr(err)
+			}
 			d.SetId(user.ID)
 			return nil
 		},
@@ -15,7 +15,7 @@ func TestDataSourceUser(t *testing.T) {
 		Fixtures: []qa.HTTPFixture{
 			{
 				Method:   "GET",
-				Resource: "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20%27ds%27",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27ds%27",
 				Response: UserList{
 					Resources: []ScimUser{
 						{
@@ -41,6 +41,45 @@ func TestDataSourceUser(t *testing.T) {
 	assert.Equal(t, d.Get("alphanumeric"), "mr_test")
 }
 
+func TestDataSourceUser_EntitlementsAndGroups(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27mr.test%40example.com%27",
+				Response: UserList{
+					Resources: []ScimUser{
+						{
+							ID:       "123",
+							UserName: "mr.test@example.com",
+							Active:   true,
+							Entitlements: entitlements{
+								{Value: "allow-cluster-create"},
+							},
+							Groups: []ComplexValue{
+								{Value: "g1", Display: "admins"},
+								{Value: "g2", Display: "data-engineers"},
+							},
+						},
+					},
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceUser(),
+		ID:          ".",
+		HCL:         `user_name = "mr.test@example.com"`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, true, d.Get("active"))
+	assert.Equal(t, true, d.Get("allow_cluster_create"))
+	assert.Equal(t, false, d.Get("allow_instance_pool_create"))
+	assert.Equal(t, 2, d.Get("groups.#"))
+	assertContains(t, d.Get("groups"), "g1")
+	assertContains(t, d.Get("groups"), "g2")
+}
+
 func TestDataSourceUserGerUser(t *testing.T) {
 	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
 		{
@@ -52,7 +91,7 @@ func TestDataSourceUserGerUser(t *testing.T) {
 		},
 		{
 			Method:   "GET",
-			Resource: "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20%27searching_error%27",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27searching_error%27",
 			Status:   404,
 			Response: common.APIError{
 				Message: "searching_error",
@@ -60,7 +99,7 @@ func TestDataSourceUserGerUser(t *testing.T) {
 		},
 		{
 			Method:   "GET",
-			Resource: "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20%27empty_search%27",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27empty_search%27",
 			Response: UserList{},
 		},
 	}, func(ctx context.Context, client *common.DatabricksClient) {
@@ -0,0 +1,56 @@
+package identity
+
+import (
+	"context"
+	"sort"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DataSourceUsers returns IDs and names of all users matching SCIM filter
+func DataSourceUsers() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"user_names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			filter := d.Get("filter").(string)
+			users, err := NewUsersAPI(ctx, m).Filter(filter)
+			if err != nil {
+				return common.DiagFromErr(err)
+			}
+			// stable order keeps plans of resources with count or for_each clean
+			sort.Slice(users, func(i, j int) bool {
+				return users[i].UserName < users[j].UserName
+			})
+			ids := []string{}
+			userNames := []string{}
+			for _, user := range users {
+				ids = append(ids, user.ID)
+				userNames = append(userNames, user.UserName)
+			}
+			d.Set("ids", ids)
+			d.Set("user_names", userNames)
+			if filter == "" {
+				filter = "all"
+			}
+			d.SetId(filter)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceUsers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20co%20%22%40contoso.com%22",
+				Response: UserList{
+					Resources: []ScimUser{
+						{ID: "2", UserName: "b@contoso.com"},
+						{ID: "1", UserName: "a@contoso.com"},
+					},
+				},
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceUsers(),
+		ID:          ".",
+		HCL:         `filter = "userName co \"@contoso.com\""`,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, `userName co "@contoso.com"`, d.Id())
+	assert.Equal(t, []interface{}{"1", "2"}, d.Get("ids"))
+	assert.Equal(t, []interface{}{"a@contoso.com", "b@contoso.com"}, d.Get("user_names"))
+}
+
+func TestDataSourceUsers_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100",
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Invalid filter",
+				},
+				Status: 400,
+			},
+		},
+		Read:        true,
+		NonWritable: true,
+		Resource:    DataSourceUsers(),
+		ID:          ".",
+	}.ExpectError(t, "Invalid filter")
+}
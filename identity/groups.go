@@ -2,8 +2,18 @@ package identity
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 )
@@ -16,22 +26,123 @@ func NewGroupsAPI(ctx context.Context, m interface{}) GroupsAPI {
 	}
 }
 
+// Policies for multiple groups with the same display name
+const (
+	DuplicateNameError  = "error"
+	DuplicateNameFirst  = "first"
+	DuplicateNameNewest = "newest"
+)
+
 // GroupsAPI exposes the scim groups API
 type GroupsAPI struct {
 	client  *common.DatabricksClient
 	context context.Context
+
+	// DuplicateNamePolicy controls ReadByDisplayName, when more than one group
+	// has the same name: "error" (default), "first" or "newest" by meta.created
+	DuplicateNamePolicy string
+
+	// CacheDisplayNames makes ReadByDisplayName reuse groups, that were already
+	// resolved by name within this process
+	CacheDisplayNames bool
+
+	// PatchBatchSize limits the number of members, that are changed by a single
+	// PATCH request. Zero means DefaultPatchBatchSize and negative disables batching.
+	PatchBatchSize int
+}
+
+// DefaultPatchBatchSize keeps PATCH requests of very large groups fast and below rate limits
+const DefaultPatchBatchSize = 1000
+
+// groupNames caches groups resolved by display name for the lifetime of process
+var groupNames = &displayNameCache{entries: map[displayNameKey]*displayNameEntry{}}
+
+type displayNameKey struct {
+	host        string
+	policy      string
+	displayName string
+	attributes  string
+}
+
+type displayNameEntry struct {
+	// held while group is resolved, so that concurrent reads wait for it
+	sync.Mutex
+	// guarded by displayNameCache
+	group *ScimGroup
+}
+
+type displayNameCache struct {
+	sync.RWMutex
+	entries map[displayNameKey]*displayNameEntry
+}
+
+func (c *displayNameCache) entry(key displayNameKey) *displayNameEntry {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &displayNameEntry{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+// resolve calls read only once for concurrent and subsequent lookups of the same key
+func (c *displayNameCache) resolve(key displayNameKey,
+	read func() (ScimGroup, error)) (ScimGroup, error) {
+	e := c.entry(key)
+	e.Lock()
+	defer e.Unlock()
+	c.RLock()
+	cached := e.group
+	c.RUnlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	group, err := read()
+	if err != nil {
+		return group, err
+	}
+	c.Lock()
+	e.group = &group
+	c.Unlock()
+	return group, nil
+}
+
+// forget removes groups of the host from cache either by id or by name
+func (c *displayNameCache) forget(host, groupID, displayName string) {
+	c.Lock()
+	defer c.Unlock()
+	for key, e := range c.entries {
+		if key.host != host {
+			continue
+		}
+		if (groupID != "" && e.group != nil && e.group.ID == groupID) ||
+			(displayName != "" && key.displayName == displayName) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (a GroupsAPI) cacheKey(displayName, attributes string) displayNameKey {
+	return displayNameKey{a.client.Host, a.DuplicateNamePolicy, displayName, attributes}
+}
+
+func (a GroupsAPI) forgetCached(groupID, displayName string) {
+	groupNames.forget(a.client.Host, groupID, displayName)
 }
 
 // Create creates a scim group in the Databricks workspace
 func (a GroupsAPI) Create(scimGroupRequest ScimGroup) (group ScimGroup, err error) {
 	scimGroupRequest.Schemas = []URN{GroupSchema}
-	err = a.client.Scim(a.context, http.MethodPost, "/preview/scim/v2/Groups", scimGroupRequest, &group)
+	err = a.client.Scim(a.context, http.MethodPost, scimPath(a.client, "Groups"), scimGroupRequest, &group)
+	a.forgetCached("", scimGroupRequest.DisplayName)
 	return
 }
 
 // Read reads and returns a Group object via SCIM api
 func (a GroupsAPI) Read(groupID string) (group ScimGroup, err error) {
-	err = a.client.Scim(a.context, http.MethodGet, fmt.Sprintf("/preview/scim/v2/Groups/%v", groupID), nil, &group)
+	err = a.client.Scim(a.context, http.MethodGet, scimPath(a.client, "Groups/"+groupID), nil, &group)
 	if err != nil {
 		return
 	}
@@ -40,17 +151,66 @@ func (a GroupsAPI) Read(groupID string) (group ScimGroup, err error) {
 
 // Filter returns groups matching the filter
 func (a GroupsAPI) Filter(filter string) (GroupList, error) {
+	return a.FilterAttributes(filter, nil, nil)
+}
+
+// FilterAttributes returns groups matching the filter with only given attributes or without
+// excluded ones, like members, which make responses slow and large for big groups
+func (a GroupsAPI) FilterAttributes(filter string, attributes, excludedAttributes []string) (GroupList, error) {
 	var groups GroupList
 	req := map[string]string{}
 	if filter != "" {
 		req["filter"] = filter
 	}
-	err := a.client.Scim(a.context, http.MethodGet, "/preview/scim/v2/Groups", req, &groups)
+	if len(attributes) > 0 {
+		req["attributes"] = strings.Join(attributes, ",")
+	}
+	if len(excludedAttributes) > 0 {
+		req["excludedAttributes"] = strings.Join(excludedAttributes, ",")
+	}
+	err := a.client.Paginate(a.context, scimPath(a.client, "Groups"), common.ScimPages, req,
+		func(raw json.RawMessage) (int, error) {
+			var page GroupList
+			err := json.Unmarshal(raw, &page)
+			groups.Resources = append(groups.Resources, page.Resources...)
+			groups.Schemas = page.Schemas
+			return len(page.Resources), err
+		})
+	groups.TotalResults = int32(len(groups.Resources))
+	groups.StartIndex = 1
+	groups.ItemsPerPage = groups.TotalResults
 	return groups, err
 }
 
 func (a GroupsAPI) ReadByDisplayName(displayName string) (group ScimGroup, err error) {
-	groupList, err := a.Filter(fmt.Sprintf("displayName eq '%s'", displayName))
+	return a.readByDisplayNameCached(displayName, nil)
+}
+
+// idAttributes are enough to pick a group by name according to DuplicateNamePolicy
+var idAttributes = []string{"id", "displayName", "meta"}
+
+// adoptAttributes are idAttributes with externalId, that is kept when the group is adopted
+var adoptAttributes = []string{"id", "displayName", "meta", "externalId"}
+
+// ReadIDByDisplayName returns ID of the group without fetching its members,
+// entitlements and roles
+func (a GroupsAPI) ReadIDByDisplayName(displayName string) (string, error) {
+	group, err := a.readByDisplayNameCached(displayName, idAttributes)
+	return group.ID, err
+}
+
+func (a GroupsAPI) readByDisplayNameCached(displayName string, attributes []string) (ScimGroup, error) {
+	if !a.CacheDisplayNames {
+		return a.readByDisplayName(displayName, attributes)
+	}
+	key := a.cacheKey(displayName, strings.Join(attributes, ","))
+	return groupNames.resolve(key, func() (ScimGroup, error) {
+		return a.readByDisplayName(displayName, attributes)
+	})
+}
+
+func (a GroupsAPI) readByDisplayName(displayName string, attributes []string) (group ScimGroup, err error) {
+	groupList, err := a.FilterAttributes(fmt.Sprintf("displayName eq '%s'", displayName), attributes, nil)
 	if err != nil {
 		return
 	}
@@ -58,34 +218,604 @@ func (a GroupsAPI) ReadByDisplayName(displayName string) (group ScimGroup, err e
 		err = fmt.Errorf("cannot find group: %s", displayName)
 		return
 	}
-	group = groupList.Resources[0]
+	if len(groupList.Resources) == 1 {
+		group = groupList.Resources[0]
+		return
+	}
+	switch a.DuplicateNamePolicy {
+	case "", DuplicateNameError:
+		err = fmt.Errorf("there are %d groups with %s name", len(groupList.Resources), displayName)
+	case DuplicateNameFirst:
+		group = groupList.Resources[0]
+	case DuplicateNameNewest:
+		group, err = newestGroup(groupList.Resources)
+	default:
+		err = fmt.Errorf("unknown duplicate name policy: %s", a.DuplicateNamePolicy)
+	}
+	return
+}
+
+func newestGroup(groups []ScimGroup) (newest ScimGroup, err error) {
+	var newestCreated time.Time
+	for _, group := range groups {
+		if group.Meta == nil {
+			err = fmt.Errorf("group %s has no creation time", group.ID)
+			return
+		}
+		created, err := time.Parse(time.RFC3339, group.Meta.Created)
+		if err != nil {
+			return newest, fmt.Errorf("group %s has invalid creation time: %w", group.ID, err)
+		}
+		if created.After(newestCreated) {
+			newest = group
+			newestCreated = created
+		}
+	}
 	return
 }
 
+func (a GroupsAPI) patchBatchSize() int {
+	if a.PatchBatchSize == 0 {
+		return DefaultPatchBatchSize
+	}
+	return a.PatchBatchSize
+}
+
+// Patch applies operations to the group in batches of PatchBatchSize members. Batches
+// are sent one by one, so the first failure leaves the earlier batches applied.
 func (a GroupsAPI) Patch(groupID string, r patchRequest) error {
-	return a.client.Scim(a.context, http.MethodPatch, fmt.Sprintf("/preview/scim/v2/Groups/%v", groupID), r, nil)
+	for _, batch := range r.batches(a.patchBatchSize()) {
+		err := a.patchBatch(groupID, batch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchBatch sends a single PATCH request, that is already split into batches
+func (a GroupsAPI) patchBatch(groupID string, batch patchRequest) error {
+	defer a.forgetCached(groupID, "")
+	return a.client.Scim(a.context, http.MethodPatch, scimPath(a.client, "Groups/"+groupID), batch, nil)
+}
+
+// memberReference recognizes $ref URLs or paths, userNames and bare IDs
+func memberReference(member string) ComplexValue {
+	member = strings.TrimSpace(member)
+	switch {
+	case strings.Contains(member, "/"):
+		return ComplexValue{Ref: member}
+	case strings.Contains(member, "@"):
+		return ComplexValue{Display: member}
+	}
+	return ComplexValue{Value: member}
 }
 
-func (a GroupsAPI) UpdateNameAndEntitlements(groupID string, name string, e entitlements) error {
-	g, err := a.Read(groupID)
+func memberReferences(members []string) (refs []ComplexValue) {
+	for _, member := range members {
+		refs = append(refs, memberReference(member))
+	}
+	return
+}
+
+// memberIDFromRef extracts member ID from $ref, like Users/123 or full SCIM URL
+func memberIDFromRef(ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid member reference %s: %w", ref, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 2 {
+		switch parts[len(parts)-2] {
+		case "Users", "Groups", "ServicePrincipals":
+			if id := parts[len(parts)-1]; id != "" {
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("invalid member reference %s: expected Users, Groups "+
+		"or ServicePrincipals followed by ID", ref)
+}
+
+// NormalizeMembers converts members given by ID, $ref or display name into the
+// form accepted by the server. Display names with @ are resolved as userNames
+// of users and all others as display names of groups.
+func (a GroupsAPI) NormalizeMembers(members []ComplexValue) (normalized []ComplexValue, err error) {
+	for _, member := range members {
+		id := member.Value
+		switch {
+		case id != "":
+		case member.Ref != "":
+			id, err = memberIDFromRef(member.Ref)
+		case strings.Contains(member.Display, "@"):
+			id, err = a.userIDByUserName(member.Display)
+		case member.Display != "":
+			id, err = a.ReadIDByDisplayName(member.Display)
+		default:
+			err = fmt.Errorf("member reference must have value, $ref or display name")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve member: %w", err)
+		}
+		normalized = append(normalized, ComplexValue{Value: id})
+	}
+	return
+}
+
+func (a GroupsAPI) userIDByUserName(userName string) (string, error) {
+	users, err := NewUsersAPI(a.context, a.client).Filter(fmt.Sprintf("userName eq '%s'", userName))
+	if err != nil {
+		return "", err
+	}
+	if len(users) != 1 {
+		return "", fmt.Errorf("cannot find user: %s", userName)
+	}
+	return users[0].ID, nil
+}
+
+// AddMembers adds members to the group without reading or rewriting the rest of it.
+// Members are given by ID, $ref or userName.
+func (a GroupsAPI) AddMembers(groupID string, memberIDs []string) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	members, err := a.NormalizeMembers(memberReferences(memberIDs))
+	if err != nil {
+		return err
+	}
+	return a.Patch(groupID, patchRequest{
+		Schemas: []URN{PatchOp},
+		Operations: []patchOperation{
+			{
+				Op:    "add",
+				Path:  "members",
+				Value: members,
+			},
+		},
+	})
+}
+
+// RemoveMembers removes members given by ID, $ref or userName from the group.
+// Members, that are not in the group, are ignored.
+func (a GroupsAPI) RemoveMembers(groupID string, memberIDs []string) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	members, err := a.NormalizeMembers(memberReferences(memberIDs))
 	if err != nil {
 		return err
 	}
+	r := patchRequest{
+		Schemas: []URN{PatchOp},
+	}
+	for _, member := range members {
+		r.Operations = append(r.Operations, patchOperation{
+			Op:   "remove",
+			Path: fmt.Sprintf(`members[value eq "%s"]`, member.Value),
+		})
+	}
+	for _, batch := range r.batches(a.patchBatchSize()) {
+		err = a.patchBatch(groupID, batch)
+		if common.IsMissing(err) && len(batch.Operations) > 1 {
+			// any missing member fails the whole batch, so members, that are still present,
+			// are removed one by one
+			err = a.removeMembersOneByOne(groupID, batch)
+		}
+		if common.IsMissing(err) {
+			// members are already absent
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a GroupsAPI) removeMembersOneByOne(groupID string, batch patchRequest) error {
+	for _, op := range batch.Operations {
+		err := a.patchBatch(groupID, patchRequest{
+			Schemas:    batch.Schemas,
+			Operations: []patchOperation{op},
+		})
+		if common.IsMissing(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rename changes only display name of the group, so that its ID and every permission,
+// that refers to it, are kept without rewriting members, entitlements and roles. Both
+// old and new names are removed from cache.
+func (a GroupsAPI) Rename(groupID, oldName, name string) error {
+	defer a.forgetCached(groupID, oldName)
+	defer a.forgetCached(groupID, name)
+	return a.Patch(groupID, patchRequest{
+		Schemas: []URN{PatchOp},
+		Operations: []patchOperation{
+			{
+				Op:    "replace",
+				Path:  "displayName",
+				Value: name,
+			},
+		},
+	})
+}
+
+// versionConflictRetries limits how many times the group is re-read and updated again,
+// when it is changed concurrently by another apply or SCIM sync
+const versionConflictRetries = 5
+
+// UpdateNameAndEntitlements replaces display name, external ID and entitlements of the group,
+// keeping its members, parents and roles. The update is conditional on meta.version of the
+// group, so that members added concurrently are not lost: on conflict the group is read again.
+func (a GroupsAPI) UpdateNameAndEntitlements(groupID, name, externalID string, e entitlements) (err error) {
+	defer a.forgetCached(groupID, name)
+	for attempt := 1; attempt <= versionConflictRetries; attempt++ {
+		var g ScimGroup
+		g, err = a.Read(groupID)
+		if err != nil {
+			return err
+		}
+		a.forgetCached(groupID, g.DisplayName)
+		version := ""
+		if g.Meta != nil {
+			version = g.Meta.Version
+		}
+		err = a.client.ScimIfMatch(a.context, http.MethodPut,
+			scimPath(a.client, "Groups/"+groupID), version,
+			ScimGroup{
+				DisplayName:  name,
+				ExternalID:   externalID,
+				Entitlements: e,
+				Groups:       g.Groups,
+				Roles:        g.Roles,
+				Members:      g.Members,
+				Schemas:      []URN{GroupSchema},
+			}, nil)
+		if version == "" || !common.IsVersionConflict(err) {
+			return err
+		}
+		log.Printf("[INFO] Group %s was changed concurrently, retrying update (%d/%d)",
+			groupID, attempt, versionConflictRetries)
+	}
+	return fmt.Errorf("cannot update group %s: it keeps changing concurrently: %w", groupID, err)
+}
+
+// Strategies to resolve entitlement and role differences in Merge
+const (
+	EntitlementStrategyUnion      = "union"
+	EntitlementStrategyTargetWins = "target-wins"
+	EntitlementStrategySourceWins = "source-wins"
+)
+
+// MergeOptions control how Merge resolves differences between groups
+type MergeOptions struct {
+	// EntitlementStrategy applies to both entitlements and roles and
+	// is one of "union" (default), "target-wins" or "source-wins"
+	EntitlementStrategy string
+}
+
+func (o MergeOptions) resolve(target, source complexValues) (complexValues, error) {
+	switch o.EntitlementStrategy {
+	case "", EntitlementStrategyUnion:
+		return target.union(source), nil
+	case EntitlementStrategyTargetWins:
+		return target, nil
+	case EntitlementStrategySourceWins:
+		return source, nil
+	}
+	return nil, fmt.Errorf("unknown entitlement strategy: %s", o.EntitlementStrategy)
+}
+
+// Merge adds members of the source group to the target group and resolves
+// entitlements and roles of both groups according to MergeOptions
+func (a GroupsAPI) Merge(targetID, sourceID string, opts MergeOptions) error {
+	defer a.forgetCached(targetID, "")
+	if _, err := opts.resolve(nil, nil); err != nil {
+		return err
+	}
+	target, err := a.Read(targetID)
+	if err != nil {
+		return err
+	}
+	source, err := a.Read(sourceID)
+	if err != nil {
+		return err
+	}
+	e, err := opts.resolve(complexValues(target.Entitlements), complexValues(source.Entitlements))
+	if err != nil {
+		return err
+	}
+	roles, err := opts.resolve(target.Roles, source.Roles)
+	if err != nil {
+		return err
+	}
+	// PUT replaces the whole group, so fields, that are not merged, like externalId, are kept
+	merged := target
+	merged.ID = ""
+	merged.Meta = nil
+	merged.Schemas = []URN{GroupSchema}
+	merged.Entitlements = entitlements(e)
+	merged.Roles = roles
+	merged.Members = complexValues(target.Members).union(source.Members)
 	return a.client.Scim(a.context, http.MethodPut,
-		fmt.Sprintf("/preview/scim/v2/Groups/%v", groupID),
-		ScimGroup{
-			DisplayName:  name,
-			Entitlements: e,
-			Groups:       g.Groups,
-			Roles:        g.Roles,
-			Members:      g.Members,
-			Schemas:      []URN{GroupSchema},
-		}, nil)
+		scimPath(a.client, "Groups/"+targetID), merged, nil)
 }
 
 // Delete deletes a group given a group id
 func (a GroupsAPI) Delete(groupID string) error {
+	defer a.forgetCached(groupID, "")
 	return a.client.Scim(a.context, http.MethodDelete,
-		fmt.Sprintf("/preview/scim/v2/Groups/%v", groupID),
+		scimPath(a.client, "Groups/"+groupID),
 		nil, nil)
 }
+
+// GroupSession caches group reads and buffers changes until Commit
+type GroupSession struct {
+	api     GroupsAPI
+	cache   map[string]ScimGroup
+	creates []ScimGroup
+	updates map[string]ScimGroup
+	deletes map[string]bool
+}
+
+// BeginSession starts buffering group changes
+func (a GroupsAPI) BeginSession() *GroupSession {
+	return &GroupSession{
+		api:     a,
+		cache:   map[string]ScimGroup{},
+		updates: map[string]ScimGroup{},
+		deletes: map[string]bool{},
+	}
+}
+
+// Read returns group with buffered changes applied. Groups are read only once per session.
+func (s *GroupSession) Read(groupID string) (ScimGroup, error) {
+	if s.deletes[groupID] {
+		return ScimGroup{}, common.NotFound(fmt.Sprintf("Group %s is deleted in this session", groupID))
+	}
+	if group, ok := s.updates[groupID]; ok {
+		return group, nil
+	}
+	if group, ok := s.cache[groupID]; ok {
+		return group, nil
+	}
+	group, err := s.api.Read(groupID)
+	if err != nil {
+		return group, err
+	}
+	s.cache[groupID] = group
+	return group, nil
+}
+
+// Create buffers creation of a group. Groups with the same display name are created only once.
+func (s *GroupSession) Create(group ScimGroup) {
+	for i, existing := range s.creates {
+		if existing.DisplayName == group.DisplayName {
+			s.creates[i] = group
+			return
+		}
+	}
+	s.creates = append(s.creates, group)
+}
+
+// Update buffers complete replacement of a group. Only the last update of a group is applied.
+func (s *GroupSession) Update(group ScimGroup) error {
+	if group.ID == "" {
+		return fmt.Errorf("group id is required for update")
+	}
+	if s.deletes[group.ID] {
+		return fmt.Errorf("group %s is already deleted in this session", group.ID)
+	}
+	s.updates[group.ID] = group
+	return nil
+}
+
+// Delete buffers removal of a group and discards its pending updates
+func (s *GroupSession) Delete(groupID string) {
+	delete(s.updates, groupID)
+	s.deletes[groupID] = true
+}
+
+// bulkOperation is a single operation of SCIM bulk request and response
+// Details at https://datatracker.ietf.org/doc/html/rfc7644#section-3.7
+type bulkOperation struct {
+	Method string      `json:"method"`
+	BulkID string      `json:"bulkId,omitempty"`
+	Path   string      `json:"path,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+
+	Location string               `json:"location,omitempty"`
+	Status   string               `json:"status,omitempty"`
+	Response *common.APIErrorBody `json:"response,omitempty"`
+}
+
+type bulkMessage struct {
+	Schemas      []URN           `json:"schemas"`
+	FailOnErrors int             `json:"failOnErrors,omitempty"`
+	Operations   []bulkOperation `json:"Operations"`
+}
+
+// Commit applies buffered creates, updates and deletes and returns created groups. All changes
+// are sent in a single SCIM bulk request, unless the server doesn't support it. Then they are
+// applied one by one. Changes, that were applied before the first failure, aren't retried by
+// the next Commit.
+func (s *GroupSession) Commit() (created []ScimGroup, err error) {
+	if len(s.creates)+len(s.updates)+len(s.deletes) == 0 {
+		return nil, nil
+	}
+	created, err = s.commitBulk()
+	var apiErr common.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound ||
+		apiErr.StatusCode == http.StatusNotImplemented) {
+		log.Printf("[INFO] SCIM bulk requests are not supported, applying group changes one by one")
+		return s.commitOneByOne()
+	}
+	return created, err
+}
+
+func (s *GroupSession) commitBulk() (created []ScimGroup, err error) {
+	request := bulkMessage{
+		Schemas:      []URN{BulkRequest},
+		FailOnErrors: 1,
+	}
+	for i, group := range s.creates {
+		group.Schemas = []URN{GroupSchema}
+		request.Operations = append(request.Operations, bulkOperation{
+			Method: http.MethodPost,
+			BulkID: fmt.Sprintf("create-%d", i),
+			Path:   "/Groups",
+			Data:   group,
+		})
+	}
+	for _, groupID := range s.sortedUpdates() {
+		group := s.updates[groupID]
+		group.Schemas = []URN{GroupSchema}
+		// metadata is read-only
+		group.Meta = nil
+		request.Operations = append(request.Operations, bulkOperation{
+			Method: http.MethodPut,
+			BulkID: "update-" + groupID,
+			Path:   "/Groups/" + groupID,
+			Data:   group,
+		})
+	}
+	for _, groupID := range s.sortedDeletes() {
+		request.Operations = append(request.Operations, bulkOperation{
+			Method: http.MethodDelete,
+			BulkID: "delete-" + groupID,
+			Path:   "/Groups/" + groupID,
+		})
+	}
+	var response bulkMessage
+	err = s.api.client.Scim(s.api.context, http.MethodPost,
+		scimPath(s.api.client, "Bulk"), request, &response)
+	if err != nil {
+		return nil, err
+	}
+	results := map[string]bulkOperation{}
+	for _, op := range response.Operations {
+		results[op.BulkID] = op
+	}
+	// requested operations are examined in order, so that the changes before the failed
+	// one are no longer buffered
+	creates := s.creates
+	for i, op := range request.Operations {
+		result, ok := results[op.BulkID]
+		if !ok {
+			return created, fmt.Errorf("bulk response has no result of %s %s", op.Method, op.Path)
+		}
+		if err = result.err(); err != nil && !(op.Method == http.MethodDelete && common.IsMissing(err)) {
+			return created, err
+		}
+		switch op.Method {
+		case http.MethodPost:
+			if result.Location == "" {
+				return created, fmt.Errorf("bulk response has no location of created group %s",
+					creates[i].DisplayName)
+			}
+			// creates are the first operations of the request
+			group := creates[i]
+			group.ID = path.Base(result.Location)
+			s.api.forgetCached("", group.DisplayName)
+			created = append(created, group)
+			s.cache[group.ID] = group
+			s.creates = s.creates[1:]
+		case http.MethodPut:
+			groupID := path.Base(op.Path)
+			s.api.forgetCached(groupID, s.updates[groupID].DisplayName)
+			s.cache[groupID] = s.updates[groupID]
+			delete(s.updates, groupID)
+		case http.MethodDelete:
+			groupID := path.Base(op.Path)
+			s.api.forgetCached(groupID, "")
+			delete(s.cache, groupID)
+			delete(s.deletes, groupID)
+		}
+	}
+	return created, nil
+}
+
+// err returns failure of the operation from bulk response or nil
+func (op bulkOperation) err() error {
+	status, err := strconv.Atoi(op.Status)
+	if err != nil {
+		return fmt.Errorf("invalid status of %s %s in bulk response: %s", op.Method, op.Path, op.Status)
+	}
+	if status < 400 {
+		return nil
+	}
+	message := http.StatusText(status)
+	if op.Response != nil && op.Response.ScimDetail != "" {
+		message = op.Response.ScimDetail
+	}
+	return common.APIError{
+		Message:    message,
+		Resource:   op.Path,
+		StatusCode: status,
+	}
+}
+
+func (s *GroupSession) sortedUpdates() (updated []string) {
+	for groupID := range s.updates {
+		updated = append(updated, groupID)
+	}
+	sort.Strings(updated)
+	return
+}
+
+func (s *GroupSession) sortedDeletes() (deleted []string) {
+	for groupID := range s.deletes {
+		deleted = append(deleted, groupID)
+	}
+	sort.Strings(deleted)
+	return
+}
+
+func (s *GroupSession) commitOneByOne() (created []ScimGroup, err error) {
+	for len(s.creates) > 0 {
+		var group ScimGroup
+		group, err = s.api.Create(s.creates[0])
+		if err != nil {
+			return created, err
+		}
+		created = append(created, group)
+		s.cache[group.ID] = group
+		s.creates = s.creates[1:]
+	}
+	for _, groupID := range s.sortedUpdates() {
+		group := s.updates[groupID]
+		group.Schemas = []URN{GroupSchema}
+		// metadata is read-only
+		group.Meta = nil
+		err = s.api.client.Scim(s.api.context, http.MethodPut,
+			scimPath(s.api.client, "Groups/"+groupID), group, nil)
+		s.api.forgetCached(groupID, group.DisplayName)
+		if err != nil {
+			return created, err
+		}
+		s.cache[groupID] = s.updates[groupID]
+		delete(s.updates, groupID)
+	}
+	for _, groupID := range s.sortedDeletes() {
+		err = s.api.Delete(groupID)
+		if err != nil && !common.IsMissing(err) {
+			return created, err
+		}
+		delete(s.cache, groupID)
+		delete(s.deletes, groupID)
+	}
+	return created, nil
+}
+
+// Rollback discards buffered changes, that were not committed yet
+func (s *GroupSession) Rollback() {
+	s.creates = nil
+	s.updates = map[string]ScimGroup{}
+	s.deletes = map[string]bool{}
+}
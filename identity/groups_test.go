@@ -2,7 +2,10 @@ package identity
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
@@ -73,3 +76,1053 @@ func TestAccFilterGroup(t *testing.T) {
 	assert.NotNil(t, groupList)
 	assert.Len(t, groupList.Resources, 1)
 }
+
+func TestGroupsMerge(t *testing.T) {
+	target := ScimGroup{
+		ID:          "abc",
+		DisplayName: "Target",
+		ExternalID:  "okta-123",
+		Groups:      []ComplexValue{{Value: "parent"}},
+		Meta:        &ScimMeta{Version: "W/\"1\""},
+		Members:     []ComplexValue{{Value: "a"}, {Value: "b"}},
+		Roles:       []ComplexValue{{Value: "role-x"}},
+		Entitlements: entitlements{
+			{Value: "allow-cluster-create"},
+			{Value: "workspace-access"},
+		},
+	}
+	source := ScimGroup{
+		ID:          "def",
+		DisplayName: "Source",
+		Members:     []ComplexValue{{Value: "b"}, {Value: "c"}},
+		Roles:       []ComplexValue{{Value: "role-y"}},
+		Entitlements: entitlements{
+			{Value: "workspace-access"},
+			{Value: "databricks-sql-access"},
+		},
+	}
+	mergedMembers := []ComplexValue{{Value: "a"}, {Value: "b"}, {Value: "c"}}
+	for strategy, expected := range map[string]ScimGroup{
+		"": {
+			Roles: []ComplexValue{{Value: "role-x"}, {Value: "role-y"}},
+			Entitlements: entitlements{
+				{Value: "allow-cluster-create"},
+				{Value: "workspace-access"},
+				{Value: "databricks-sql-access"},
+			},
+		},
+		EntitlementStrategyUnion: {
+			Roles: []ComplexValue{{Value: "role-x"}, {Value: "role-y"}},
+			Entitlements: entitlements{
+				{Value: "allow-cluster-create"},
+				{Value: "workspace-access"},
+				{Value: "databricks-sql-access"},
+			},
+		},
+		EntitlementStrategyTargetWins: {
+			Roles:        target.Roles,
+			Entitlements: target.Entitlements,
+		},
+		EntitlementStrategySourceWins: {
+			Roles:        source.Roles,
+			Entitlements: source.Entitlements,
+		},
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+				{
+					Method:   "GET",
+					Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+					Response: target,
+				},
+				{
+					Method:   "GET",
+					Resource: "/api/2.0/preview/scim/v2/Groups/def",
+					Response: source,
+				},
+				{
+					Method:   "PUT",
+					Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+					ExpectedRequest: ScimGroup{
+						Schemas:      []URN{GroupSchema},
+						DisplayName:  "Target",
+						ExternalID:   "okta-123",
+						Groups:       target.Groups,
+						Members:      mergedMembers,
+						Roles:        expected.Roles,
+						Entitlements: expected.Entitlements,
+					},
+				},
+			}, func(ctx context.Context, client *common.DatabricksClient) {
+				err := NewGroupsAPI(ctx, client).Merge("abc", "def", MergeOptions{
+					EntitlementStrategy: strategy,
+				})
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestGroupsMerge_UnknownStrategy(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{}, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewGroupsAPI(ctx, client).Merge("abc", "def", MergeOptions{
+			EntitlementStrategy: "whatever",
+		})
+		qa.AssertErrorStartsWith(t, err, "unknown entitlement strategy: whatever")
+	})
+}
+
+func TestGroupsAddMembers(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{
+						Op:   "add",
+						Path: "members",
+						Value: []ComplexValue{
+							{Value: "a"},
+							{Value: "b"},
+						},
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		err := groupsAPI.AddMembers("abc", []string{"a", "b"})
+		require.NoError(t, err)
+
+		// no HTTP calls for empty lists
+		err = groupsAPI.AddMembers("abc", []string{})
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsAddMembers_Batches(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{
+						Op:    "add",
+						Path:  "members",
+						Value: []ComplexValue{{Value: "a"}, {Value: "b"}},
+					},
+				},
+			},
+		},
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{
+						Op:    "add",
+						Path:  "members",
+						Value: []ComplexValue{{Value: "c"}},
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.PatchBatchSize = 2
+		err := groupsAPI.AddMembers("abc", []string{"a", "b", "c"})
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsPatch_BatchError(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{Op: "remove", Path: `members[value eq "a"]`},
+				},
+			},
+			Status: 400,
+			Response: common.APIErrorBody{
+				ScimDetail: "Invalid path",
+				ScimStatus: "400",
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.PatchBatchSize = 1
+		// the second batch isn't sent
+		err := groupsAPI.Patch("abc", patchRequest{
+			Schemas: []URN{PatchOp},
+			Operations: []patchOperation{
+				{Op: "remove", Path: `members[value eq "a"]`},
+				{Op: "remove", Path: `members[value eq "b"]`},
+			},
+		})
+		assert.EqualError(t, err, "Invalid path")
+	})
+}
+
+func TestGroupsRemoveMembers_Batches(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{Op: "remove", Path: `members[value eq "a"]`},
+				},
+			},
+			Status: 404,
+			Response: common.APIErrorBody{
+				ScimDetail: "Member not found",
+				ScimStatus: "404",
+			},
+		},
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{Op: "remove", Path: `members[value eq "b"]`},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.PatchBatchSize = 1
+		// members, that are already absent, don't stop the rest of batches
+		err := groupsAPI.RemoveMembers("abc", []string{"a", "b"})
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsRemoveMembers_BatchWithMissingMember(t *testing.T) {
+	remove := func(members ...string) patchRequest {
+		r := patchRequest{Schemas: []URN{PatchOp}}
+		for _, member := range members {
+			r.Operations = append(r.Operations, patchOperation{
+				Op:   "remove",
+				Path: fmt.Sprintf(`members[value eq "%s"]`, member),
+			})
+		}
+		return r
+	}
+	missing := common.APIErrorBody{
+		ScimDetail: "Member not found",
+		ScimStatus: "404",
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:          "PATCH",
+			Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: remove("a", "b"),
+			Status:          404,
+			Response:        missing,
+		},
+		{
+			Method:          "PATCH",
+			Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: remove("a"),
+			Status:          404,
+			Response:        missing,
+		},
+		{
+			Method:          "PATCH",
+			Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: remove("b"),
+		},
+		{
+			Method:          "PATCH",
+			Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: remove("c"),
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.PatchBatchSize = 2
+		// "a" is already absent, but "b" is still in the group
+		err := groupsAPI.RemoveMembers("abc", []string{"a", "b", "c"})
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsRemoveMembers(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{
+						Op:   "remove",
+						Path: `members[value eq "a"]`,
+					},
+					{
+						Op:   "remove",
+						Path: `members[value eq "b"]`,
+					},
+				},
+			},
+		},
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Status:   404,
+			Response: common.APIErrorBody{
+				ScimDetail: "Member not found",
+				ScimStatus: "404",
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		err := groupsAPI.RemoveMembers("abc", []string{"a", "b"})
+		require.NoError(t, err)
+
+		err = groupsAPI.RemoveMembers("abc", []string{"c"})
+		require.NoError(t, err)
+
+		err = groupsAPI.RemoveMembers("abc", nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupSessionCommit(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Response: ScimGroup{
+				ID:          "abc",
+				DisplayName: "Old",
+			},
+		},
+		{
+			// server without bulk support
+			Method:   "POST",
+			Resource: "/api/2.0/preview/scim/v2/Bulk",
+			Status:   404,
+			Response: common.APIErrorBody{
+				ScimDetail: "Not found",
+				ScimStatus: "404",
+			},
+		},
+		{
+			Method:   "POST",
+			Resource: "/api/2.0/preview/scim/v2/Groups",
+			ExpectedRequest: ScimGroup{
+				Schemas:     []URN{GroupSchema},
+				DisplayName: "New",
+				Members:     []ComplexValue{{Value: "b"}},
+			},
+			Response: ScimGroup{
+				ID:          "new",
+				DisplayName: "New",
+			},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: ScimGroup{
+				Schemas:     []URN{GroupSchema},
+				ID:          "abc",
+				DisplayName: "Renamed again",
+			},
+		},
+		{
+			Method:   "DELETE",
+			Resource: "/api/2.0/preview/scim/v2/Groups/def",
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		session := NewGroupsAPI(ctx, client).BeginSession()
+		group, err := session.Read("abc")
+		require.NoError(t, err)
+		assert.Equal(t, "Old", group.DisplayName)
+
+		// second read comes from cache
+		_, err = session.Read("abc")
+		require.NoError(t, err)
+
+		session.Create(ScimGroup{DisplayName: "New"})
+		session.Create(ScimGroup{DisplayName: "New", Members: []ComplexValue{{Value: "b"}}})
+
+		group.DisplayName = "Renamed"
+		require.NoError(t, session.Update(group))
+		group.DisplayName = "Renamed again"
+		require.NoError(t, session.Update(group))
+
+		group, err = session.Read("abc")
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed again", group.DisplayName)
+
+		require.NoError(t, session.Update(ScimGroup{ID: "def", DisplayName: "Gone"}))
+		session.Delete("def")
+		_, err = session.Read("def")
+		assert.True(t, common.IsMissing(err))
+		err = session.Update(ScimGroup{ID: "def"})
+		qa.AssertErrorStartsWith(t, err, "group def is already deleted in this session")
+
+		created, err := session.Commit()
+		require.NoError(t, err)
+		require.Len(t, created, 1)
+		assert.Equal(t, "new", created[0].ID)
+
+		// nothing is left to commit
+		created, err = session.Commit()
+		require.NoError(t, err)
+		assert.Len(t, created, 0)
+	})
+}
+
+func TestGroupSessionCommit_Bulk(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "POST",
+			Resource: "/api/2.0/preview/scim/v2/Bulk",
+			ExpectedRequest: bulkMessage{
+				Schemas:      []URN{BulkRequest},
+				FailOnErrors: 1,
+				Operations: []bulkOperation{
+					{
+						Method: "POST",
+						BulkID: "create-0",
+						Path:   "/Groups",
+						Data: map[string]interface{}{
+							"schemas":     []interface{}{string(GroupSchema)},
+							"displayName": "New",
+						},
+					},
+					{
+						Method: "PUT",
+						BulkID: "update-abc",
+						Path:   "/Groups/abc",
+						Data: map[string]interface{}{
+							"schemas":     []interface{}{string(GroupSchema)},
+							"id":          "abc",
+							"displayName": "Renamed",
+						},
+					},
+					{
+						Method: "DELETE",
+						BulkID: "delete-def",
+						Path:   "/Groups/def",
+					},
+					{
+						Method: "DELETE",
+						BulkID: "delete-ghi",
+						Path:   "/Groups/ghi",
+					},
+				},
+			},
+			Response: bulkMessage{
+				Operations: []bulkOperation{
+					{
+						Method:   "POST",
+						BulkID:   "create-0",
+						Location: "https://example.com/api/2.0/preview/scim/v2/Groups/new",
+						Status:   "201",
+					},
+					{
+						Method: "PUT",
+						BulkID: "update-abc",
+						Status: "200",
+					},
+					{
+						// already deleted
+						Method: "DELETE",
+						BulkID: "delete-def",
+						Status: "404",
+					},
+					{
+						Method: "DELETE",
+						BulkID: "delete-ghi",
+						Status: "409",
+						Response: &common.APIErrorBody{
+							ScimDetail: "Group is in use",
+						},
+					},
+				},
+			},
+		},
+		{
+			Method:   "POST",
+			Resource: "/api/2.0/preview/scim/v2/Bulk",
+			ExpectedRequest: bulkMessage{
+				Schemas:      []URN{BulkRequest},
+				FailOnErrors: 1,
+				Operations: []bulkOperation{
+					{
+						Method: "DELETE",
+						BulkID: "delete-ghi",
+						Path:   "/Groups/ghi",
+					},
+				},
+			},
+			Response: bulkMessage{
+				Operations: []bulkOperation{
+					{
+						Method: "DELETE",
+						BulkID: "delete-ghi",
+						Status: "204",
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		session := NewGroupsAPI(ctx, client).BeginSession()
+		session.Create(ScimGroup{DisplayName: "New"})
+		require.NoError(t, session.Update(ScimGroup{ID: "abc", DisplayName: "Renamed"}))
+		session.Delete("def")
+		session.Delete("ghi")
+
+		created, err := session.Commit()
+		assert.EqualError(t, err, "Group is in use")
+		require.Len(t, created, 1)
+		assert.Equal(t, "new", created[0].ID)
+		assert.Equal(t, "New", created[0].DisplayName)
+
+		group, err := session.Read("new")
+		require.NoError(t, err)
+		assert.Equal(t, "New", group.DisplayName)
+
+		// only the failed delete is sent again
+		created, err = session.Commit()
+		require.NoError(t, err)
+		assert.Len(t, created, 0)
+	})
+}
+
+func TestGroupSessionRollback(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{}, func(ctx context.Context, client *common.DatabricksClient) {
+		session := NewGroupsAPI(ctx, client).BeginSession()
+		session.Create(ScimGroup{DisplayName: "New"})
+		require.NoError(t, session.Update(ScimGroup{ID: "abc", DisplayName: "Renamed"}))
+		session.Delete("def")
+		err := session.Update(ScimGroup{})
+		qa.AssertErrorStartsWith(t, err, "group id is required for update")
+
+		session.Rollback()
+		created, err := session.Commit()
+		require.NoError(t, err)
+		assert.Len(t, created, 0)
+	})
+}
+
+func TestGroupsReadByDisplayName_Duplicates(t *testing.T) {
+	duplicates := GroupList{
+		Resources: []ScimGroup{
+			{
+				ID:          "older",
+				DisplayName: "Same",
+				Meta:        &ScimMeta{Created: "2021-01-01T10:00:00Z"},
+			},
+			{
+				ID:          "newer",
+				DisplayName: "Same",
+				Meta:        &ScimMeta{Created: "2021-06-01T10:00:00Z"},
+			},
+		},
+	}
+	for policy, expected := range map[string]string{
+		"":                  "error: there are 2 groups with Same name",
+		DuplicateNameError:  "error: there are 2 groups with Same name",
+		DuplicateNameFirst:  "older",
+		DuplicateNameNewest: "newer",
+		"random":            "error: unknown duplicate name policy: random",
+	} {
+		t.Run(policy, func(t *testing.T) {
+			qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+				{
+					Method:   "GET",
+					Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27Same%27",
+					Response: duplicates,
+				},
+			}, func(ctx context.Context, client *common.DatabricksClient) {
+				groupsAPI := NewGroupsAPI(ctx, client)
+				groupsAPI.DuplicateNamePolicy = policy
+				group, err := groupsAPI.ReadByDisplayName("Same")
+				if strings.HasPrefix(expected, "error: ") {
+					qa.AssertErrorStartsWith(t, err, strings.TrimPrefix(expected, "error: "))
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, expected, group.ID)
+			})
+		})
+	}
+}
+
+func TestGroupsReadByDisplayName_NewestWithoutMeta(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27Same%27",
+			Response: GroupList{
+				Resources: []ScimGroup{
+					{ID: "a", Meta: &ScimMeta{Created: "yesterday"}},
+					{ID: "b"},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.DuplicateNamePolicy = DuplicateNameNewest
+		_, err := groupsAPI.ReadByDisplayName("Same")
+		qa.AssertErrorStartsWith(t, err, "group a has invalid creation time")
+	})
+}
+
+func TestGroupsFilter_Paginated(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20sw%20%27data%27",
+			Response: GroupList{
+				TotalResults: 3,
+				ItemsPerPage: 2,
+				Resources: []ScimGroup{
+					{ID: "a", DisplayName: "data-engineers"},
+					{ID: "b", DisplayName: "data-scientists"},
+				},
+			},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20sw%20%27data%27&startIndex=3",
+			Response: GroupList{
+				TotalResults: 3,
+				ItemsPerPage: 1,
+				StartIndex:   3,
+				Resources: []ScimGroup{
+					{ID: "c", DisplayName: "data-analysts"},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groups, err := NewGroupsAPI(ctx, client).Filter("displayName sw 'data'")
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), groups.TotalResults)
+		assert.Len(t, groups.Resources, 3)
+		assert.Equal(t, "c", groups.Resources[2].ID)
+	})
+}
+
+func TestGroupsReadByDisplayName_Cached(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27data-engineers%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "abc", DisplayName: "data-engineers"}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.CacheDisplayNames = true
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				group, err := groupsAPI.ReadByDisplayName("data-engineers")
+				assert.NoError(t, err)
+				assert.Equal(t, "abc", group.ID)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestGroupsReadByDisplayName_CacheInvalidated(t *testing.T) {
+	byName := func(id string) qa.HTTPFixture {
+		return qa.HTTPFixture{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27ds%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: id, DisplayName: "ds"}},
+			},
+		}
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		byName("a"),
+		{
+			Method:   "DELETE",
+			Resource: "/api/2.0/preview/scim/v2/Groups/a",
+		},
+		{
+			Method:   "POST",
+			Resource: "/api/2.0/preview/scim/v2/Groups",
+			Response: ScimGroup{ID: "b", DisplayName: "ds"},
+		},
+		byName("b"),
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/b",
+			Response: ScimGroup{ID: "b", DisplayName: "ds"},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/b",
+		},
+		byName("c"),
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.CacheDisplayNames = true
+		read := func(expected string) {
+			group, err := groupsAPI.ReadByDisplayName("ds")
+			require.NoError(t, err)
+			assert.Equal(t, expected, group.ID)
+		}
+		read("a")
+		read("a")
+		require.NoError(t, groupsAPI.Delete("a"))
+		_, err := groupsAPI.Create(ScimGroup{DisplayName: "ds"})
+		require.NoError(t, err)
+		read("b")
+		read("b")
+		require.NoError(t, groupsAPI.UpdateNameAndEntitlements("b", "renamed", "", nil))
+		read("c")
+	})
+}
+
+func TestGroupsReadByDisplayName_CacheInvalidatedByMembership(t *testing.T) {
+	byName := func(members ...ComplexValue) qa.HTTPFixture {
+		return qa.HTTPFixture{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27ds%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "a", DisplayName: "ds", Members: members}},
+			},
+		}
+	}
+	patch := qa.HTTPFixture{
+		Method:   "PATCH",
+		Resource: "/api/2.0/preview/scim/v2/Groups/a",
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		byName(),
+		patch,
+		byName(ComplexValue{Value: "1"}),
+		patch,
+		byName(),
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/a",
+			Response: ScimGroup{ID: "a", DisplayName: "ds"},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/b",
+			Response: ScimGroup{ID: "b", DisplayName: "other"},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/a",
+		},
+		byName(ComplexValue{Value: "2"}),
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.CacheDisplayNames = true
+		read := func(expected ...string) {
+			group, err := groupsAPI.ReadByDisplayName("ds")
+			require.NoError(t, err)
+			var members []string
+			for _, m := range group.Members {
+				members = append(members, m.Value)
+			}
+			assert.Equal(t, expected, members)
+		}
+		read()
+		require.NoError(t, groupsAPI.AddMembers("a", []string{"1"}))
+		read("1")
+		require.NoError(t, groupsAPI.RemoveMembers("a", []string{"1"}))
+		read()
+		require.NoError(t, groupsAPI.Merge("a", "b", MergeOptions{}))
+		read("2")
+	})
+}
+
+func TestGroupsRename_ForgetsOldName(t *testing.T) {
+	byName := func(id string) qa.HTTPFixture {
+		return qa.HTTPFixture{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?attributes=id%2CdisplayName%2Cmeta&count=100&filter=displayName%20eq%20%27ds%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: id, DisplayName: "ds"}},
+			},
+		}
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		byName("a"),
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/a",
+		},
+		// another group takes the old name
+		byName("b"),
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.CacheDisplayNames = true
+		id, err := groupsAPI.ReadIDByDisplayName("ds")
+		require.NoError(t, err)
+		assert.Equal(t, "a", id)
+
+		require.NoError(t, groupsAPI.Rename("a", "ds", "renamed"))
+
+		id, err = groupsAPI.ReadIDByDisplayName("ds")
+		require.NoError(t, err)
+		assert.Equal(t, "b", id)
+	})
+}
+
+func TestGroupsUpdateNameAndEntitlements_VersionConflict(t *testing.T) {
+	conflict := common.APIErrorBody{
+		ScimDetail: "version mismatch",
+		ScimStatus: "412",
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Response: ScimGroup{
+				ID:          "abc",
+				DisplayName: "ds",
+				Members:     []ComplexValue{{Value: "1"}},
+				Meta:        &ScimMeta{Version: `W/"1"`},
+			},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Status:   412,
+			Response: conflict,
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Response: ScimGroup{
+				ID:          "abc",
+				DisplayName: "ds",
+				Members:     []ComplexValue{{Value: "1"}, {Value: "2"}},
+				Meta:        &ScimMeta{Version: `W/"2"`},
+			},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: ScimGroup{
+				Schemas:     []URN{GroupSchema},
+				DisplayName: "renamed",
+				Members:     []ComplexValue{{Value: "1"}, {Value: "2"}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewGroupsAPI(ctx, client).UpdateNameAndEntitlements("abc", "renamed", "", nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsUpdateNameAndEntitlements_KeepsChanging(t *testing.T) {
+	fixtures := []qa.HTTPFixture{}
+	for i := 0; i < versionConflictRetries; i++ {
+		fixtures = append(fixtures, qa.HTTPFixture{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Response: ScimGroup{
+				ID:          "abc",
+				DisplayName: "ds",
+				Meta:        &ScimMeta{Version: fmt.Sprintf(`W/"%d"`, i)},
+			},
+		}, qa.HTTPFixture{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Status:   412,
+			Response: common.APIErrorBody{
+				ScimDetail: "version mismatch",
+				ScimStatus: "412",
+			},
+		})
+	}
+	qa.HTTPFixturesApply(t, fixtures, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewGroupsAPI(ctx, client).UpdateNameAndEntitlements("abc", "renamed", "", nil)
+		assert.EqualError(t, err, "cannot update group abc: it keeps changing concurrently: version mismatch")
+	})
+}
+
+func TestGroupsUpdateNameAndEntitlements_NameCollision(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Response: ScimGroup{
+				ID:          "abc",
+				DisplayName: "ds",
+				Meta:        &ScimMeta{Version: `W/"1"`},
+			},
+		},
+		{
+			Method:   "PUT",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			Status:   409,
+			Response: common.APIErrorBody{
+				ScimDetail: "Group with name renamed already exists.",
+				ScimStatus: "409",
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewGroupsAPI(ctx, client).UpdateNameAndEntitlements("abc", "renamed", "", nil)
+		assert.EqualError(t, err, "Group with name renamed already exists.")
+	})
+}
+
+func TestGroupsNormalizeMembers(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27me%40example.com%27",
+			Response: UserList{
+				Resources: []ScimUser{{ID: "123", UserName: "me@example.com"}},
+			},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?attributes=id%2CdisplayName%2Cmeta&count=100&filter=displayName%20eq%20%27ds%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "456", DisplayName: "ds"}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		members, err := NewGroupsAPI(ctx, client).NormalizeMembers([]ComplexValue{
+			{Value: "1", Display: "whatever", Ref: "Users/1"},
+			{Ref: "Groups/2"},
+			{Ref: "https://abc.cloud.databricks.com/api/2.0/preview/scim/v2/ServicePrincipals/3"},
+			{Display: "me@example.com"},
+			{Display: "ds"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []ComplexValue{
+			{Value: "1"},
+			{Value: "2"},
+			{Value: "3"},
+			{Value: "123"},
+			{Value: "456"},
+		}, members)
+	})
+}
+
+func TestGroupsNormalizeMembers_Errors(t *testing.T) {
+	for ref, expected := range map[string]string{
+		"Jobs/1":         "cannot resolve member: invalid member reference Jobs/1",
+		"Users/":         "cannot resolve member: invalid member reference Users/",
+		"nobody@abc.com": "cannot resolve member: cannot find user: nobody@abc.com",
+	} {
+		t.Run(ref, func(t *testing.T) {
+			qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+				{
+					Method:       "GET",
+					Resource:     "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27nobody%40abc.com%27",
+					Response:     UserList{},
+					ReuseRequest: true,
+				},
+			}, func(ctx context.Context, client *common.DatabricksClient) {
+				err := NewGroupsAPI(ctx, client).AddMembers("abc", []string{ref})
+				qa.AssertErrorStartsWith(t, err, expected)
+			})
+		})
+	}
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{}, func(ctx context.Context, client *common.DatabricksClient) {
+		_, err := NewGroupsAPI(ctx, client).NormalizeMembers([]ComplexValue{{}})
+		qa.AssertErrorStartsWith(t, err, "cannot resolve member: member reference must have value")
+	})
+}
+
+func TestGroupsAddMembers_Normalized(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27me%40example.com%27",
+			Response: UserList{
+				Resources: []ScimUser{{ID: "123", UserName: "me@example.com"}},
+			},
+		},
+		{
+			Method:   "PATCH",
+			Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+			ExpectedRequest: patchRequest{
+				Schemas: []URN{PatchOp},
+				Operations: []patchOperation{
+					{
+						Op:   "add",
+						Path: "members",
+						Value: []ComplexValue{
+							{Value: "1"},
+							{Value: "2"},
+							{Value: "123"},
+						},
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		err := NewGroupsAPI(ctx, client).AddMembers("abc", []string{
+			" 1 ", "Users/2", "me@example.com"})
+		require.NoError(t, err)
+	})
+}
+
+func TestGroupsReadIDByDisplayName_Cached(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?attributes=id%2CdisplayName%2Cmeta&count=100&filter=displayName%20eq%20%27huge%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "abc", DisplayName: "huge"}},
+			},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&filter=displayName%20eq%20%27huge%27",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "abc", DisplayName: "huge", Members: []ComplexValue{{Value: "1"}}}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groupsAPI := NewGroupsAPI(ctx, client)
+		groupsAPI.CacheDisplayNames = true
+		id, err := groupsAPI.ReadIDByDisplayName("huge")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", id)
+
+		// group without members is not served from cache to those who need them
+		group, err := groupsAPI.ReadByDisplayName("huge")
+		require.NoError(t, err)
+		assert.Len(t, group.Members, 1)
+	})
+}
+
+func TestGroupsFilterAttributes(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Groups?count=100&excludedAttributes=members%2Croles",
+			Response: GroupList{
+				Resources: []ScimGroup{{ID: "abc", DisplayName: "huge"}},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		groups, err := NewGroupsAPI(ctx, client).FilterAttributes("", nil, []string{"members", "roles"})
+		require.NoError(t, err)
+		assert.Len(t, groups.Resources, 1)
+	})
+}
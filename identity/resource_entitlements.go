@@ -0,0 +1,198 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// entitlementPrincipals map ID prefixes of databricks_entitlements to SCIM endpoints
+var entitlementPrincipals = map[string]string{
+	"group_id":             "Groups",
+	"user_id":              "Users",
+	"service_principal_id": "ServicePrincipals",
+}
+
+func entitlementsPath(c *common.DatabricksClient, id string) (string, error) {
+	split := strings.SplitN(id, "/", 2)
+	if len(split) != 2 || split[1] == "" {
+		return "", fmt.Errorf("invalid ID: %s", id)
+	}
+	for field, endpoint := range entitlementPrincipals {
+		if split[0] == strings.TrimSuffix(field, "_id") {
+			return scimPath(c, endpoint+"/"+split[1]), nil
+		}
+	}
+	return "", fmt.Errorf("invalid ID: %s", id)
+}
+
+// entitlementsPatch enables and disables the given entitlements, without touching
+// other entitlements and the rest of the principal
+func entitlementsPatch(enable, disable []string) patchRequest {
+	r := patchRequest{
+		Schemas: []URN{PatchOp},
+	}
+	if len(enable) > 0 {
+		enabled := entitlements{}
+		for _, entitlement := range enable {
+			enabled = append(enabled, ComplexValue{Value: entitlement})
+		}
+		r.Operations = append(r.Operations, patchOperation{
+			Op:    "add",
+			Path:  "entitlements",
+			Value: enabled,
+		})
+	}
+	for _, entitlement := range disable {
+		r.Operations = append(r.Operations, patchOperation{
+			Op:   "remove",
+			Path: fmt.Sprintf(`entitlements[value eq "%s"]`, entitlement),
+		})
+	}
+	return r
+}
+
+// ResourceEntitlements manages entitlements of a group, user or service principal,
+// which is otherwise managed elsewhere
+func ResourceEntitlements() *schema.Resource {
+	entitlementsSchema := map[string]*schema.Schema{}
+	principals := []string{}
+	for field := range entitlementPrincipals {
+		principals = append(principals, field)
+	}
+	sort.Strings(principals)
+	for field := range entitlementPrincipals {
+		entitlementsSchema[field] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ExactlyOneOf: principals,
+		}
+	}
+	addEntitlementsToSchema(&entitlementsSchema)
+	for _, entitlement := range possibleEntitlements {
+		// entitlements, that are not configured, are kept as they are
+		field := entitlementsSchema[entitlementMapping[entitlement]]
+		field.Default = nil
+		field.Computed = true
+	}
+	// entitlements, that were enabled by this resource, so that destroying it doesn't
+	// revoke entitlements, that were only read from the principal
+	entitlementsSchema["managed_entitlements"] = &schema.Schema{
+		Type:     schema.TypeSet,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+	patch := func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient,
+		enable, disable []string) error {
+		if len(enable) == 0 && len(disable) == 0 {
+			return nil
+		}
+		path, err := entitlementsPath(c, d.Id())
+		if err != nil {
+			return err
+		}
+		return c.Scim(ctx, http.MethodPatch, path, entitlementsPatch(enable, disable), nil)
+	}
+	return common.Resource{
+		Schema: entitlementsSchema,
+		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			for field := range entitlementPrincipals {
+				if v := d.Get(field).(string); v != "" {
+					d.SetId(fmt.Sprintf("%s/%s", strings.TrimSuffix(field, "_id"), v))
+				}
+			}
+			enable, disable := []string{}, []string{}
+			for _, entitlement := range possibleEntitlements {
+				// entitlements, that are not configured, are computed and don't exist yet
+				v, configured := d.GetOkExists(entitlementMapping[entitlement])
+				if !configured {
+					continue
+				}
+				if v.(bool) {
+					enable = append(enable, entitlement)
+				} else {
+					disable = append(disable, entitlement)
+				}
+			}
+			err := patch(ctx, d, c, enable, disable)
+			if err != nil {
+				return err
+			}
+			return d.Set("managed_entitlements", enable)
+		},
+		Read: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			path, err := entitlementsPath(c, d.Id())
+			if err != nil {
+				return err
+			}
+			var principal struct {
+				Entitlements entitlements `json:"entitlements,omitempty"`
+			}
+			err = c.Scim(ctx, http.MethodGet, path, nil, &principal)
+			if err != nil {
+				return err
+			}
+			split := strings.SplitN(d.Id(), "/", 2)
+			d.Set(split[0]+"_id", split[1])
+			for _, entitlement := range possibleEntitlements {
+				d.Set(entitlementMapping[entitlement], false)
+			}
+			return principal.Entitlements.readIntoData(d)
+		},
+		Update: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			enable, disable := []string{}, []string{}
+			for _, entitlement := range possibleEntitlements {
+				field := entitlementMapping[entitlement]
+				if !d.HasChange(field) {
+					continue
+				}
+				if d.Get(field).(bool) {
+					enable = append(enable, entitlement)
+				} else {
+					disable = append(disable, entitlement)
+				}
+			}
+			err := patch(ctx, d, c, enable, disable)
+			if err != nil {
+				return err
+			}
+			managed := d.Get("managed_entitlements").(*schema.Set)
+			for _, entitlement := range enable {
+				managed.Add(entitlement)
+			}
+			for _, entitlement := range disable {
+				managed.Remove(entitlement)
+			}
+			return d.Set("managed_entitlements", managed.List())
+		},
+		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			disable := []string{}
+			managed := d.Get("managed_entitlements").(*schema.Set)
+			for _, entitlement := range possibleEntitlements {
+				// removing workspace access of the users group locks everyone out of the workspace
+				if entitlement == "workspace-access" {
+					continue
+				}
+				// entitlements, that are not configured, may be granted by someone else
+				if !managed.Contains(entitlement) {
+					continue
+				}
+				if d.Get(entitlementMapping[entitlement]).(bool) {
+					disable = append(disable, entitlement)
+				}
+			}
+			err := patch(ctx, d, c, nil, disable)
+			if common.IsMissing(err) {
+				// principal is already deleted
+				return nil
+			}
+			return err
+		},
+	}.ToResource()
+}
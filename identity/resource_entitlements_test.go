@@ -0,0 +1,263 @@
+package identity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceEntitlementsCreate(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:   "add",
+							Path: "entitlements",
+							Value: []ComplexValue{
+								{Value: "allow-cluster-create"},
+								{Value: "databricks-sql-access"},
+							},
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					Entitlements: entitlements{
+						{Value: "allow-cluster-create"},
+						{Value: "databricks-sql-access"},
+					},
+				},
+			},
+		},
+		Resource: ResourceEntitlements(),
+		HCL: `
+		group_id = "abc"
+		allow_cluster_create = true
+		allow_sql_analytics_access = true
+		`,
+		Create: true,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, "group/abc", d.Id())
+	assert.Equal(t, "abc", d.Get("group_id"))
+	assert.Equal(t, true, d.Get("allow_cluster_create"))
+	assert.Equal(t, false, d.Get("allow_instance_pool_create"))
+	assert.Equal(t, true, d.Get("allow_sql_analytics_access"))
+	assert.Equal(t, 2, d.Get("managed_entitlements.#"))
+}
+
+func TestResourceEntitlementsCreate_DisablesConfiguredFalse(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:   "add",
+							Path: "entitlements",
+							Value: []ComplexValue{
+								{Value: "databricks-sql-access"},
+							},
+						},
+						{
+							Op:   "remove",
+							Path: `entitlements[value eq "allow-cluster-create"]`,
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				Response: ScimUser{
+					ID: "abc",
+					Entitlements: entitlements{
+						{Value: "allow-instance-pool-create"},
+						{Value: "databricks-sql-access"},
+					},
+				},
+			},
+		},
+		Resource: ResourceEntitlements(),
+		HCL: `
+		user_id = "abc"
+		allow_cluster_create = false
+		allow_sql_analytics_access = true
+		`,
+		Create: true,
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, "user/abc", d.Id())
+	assert.Equal(t, false, d.Get("allow_cluster_create"))
+	assert.Equal(t, true, d.Get("allow_instance_pool_create"))
+	assert.Equal(t, true, d.Get("allow_sql_analytics_access"))
+	assert.Equal(t, 1, d.Get("managed_entitlements.#"))
+}
+
+func TestResourceEntitlementsRead_ServicePrincipal(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/ServicePrincipals/abc",
+				Response: ScimUser{
+					ID:            "abc",
+					ApplicationID: "bcd",
+					Entitlements: entitlements{
+						{Value: "workspace-access"},
+					},
+				},
+			},
+		},
+		Resource: ResourceEntitlements(),
+		Read:     true,
+		New:      true,
+		ID:       "service_principal/abc",
+	}.Apply(t)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", d.Get("service_principal_id"))
+	assert.Equal(t, true, d.Get("workspace_access"))
+	assert.Equal(t, false, d.Get("allow_cluster_create"))
+}
+
+func TestResourceEntitlementsRead_NotFound(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				Response: common.APIErrorBody{
+					ErrorCode: "NOT_FOUND",
+					Message:   "User not found",
+				},
+				Status: 404,
+			},
+		},
+		Resource: ResourceEntitlements(),
+		Read:     true,
+		Removed:  true,
+		ID:       "user/abc",
+	}.ApplyNoError(t)
+}
+
+func TestResourceEntitlementsRead_InvalidID(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceEntitlements(),
+		Read:     true,
+		New:      true,
+		ID:       "cluster/abc",
+	}.ExpectError(t, "invalid ID: cluster/abc")
+}
+
+func TestResourceEntitlementsUpdate(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: entitlementsPatch([]string{"databricks-sql-access"},
+					[]string{"allow-cluster-create"}),
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID: "abc",
+					Entitlements: entitlements{
+						{Value: "databricks-sql-access"},
+						{Value: "workspace-access"},
+					},
+				},
+			},
+		},
+		Resource: ResourceEntitlements(),
+		Update:   true,
+		ID:       "group/abc",
+		InstanceState: map[string]string{
+			"group_id":                   "abc",
+			"allow_cluster_create":       "true",
+			"allow_instance_pool_create": "false",
+			"allow_sql_analytics_access": "false",
+			"workspace_access":           "true",
+		},
+		HCL: `
+		group_id = "abc"
+		allow_cluster_create = false
+		allow_sql_analytics_access = true
+		`,
+	}.Apply(t)
+	require.NoError(t, err)
+	managed := d.Get("managed_entitlements").(*schema.Set)
+	assert.Equal(t, 1, managed.Len())
+	assert.True(t, managed.Contains("databricks-sql-access"))
+}
+
+func TestResourceEntitlementsDelete(t *testing.T) {
+	hash := schema.HashSchema(&schema.Schema{Type: schema.TypeString})
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:          "PATCH",
+				Resource:        "/api/2.0/preview/scim/v2/Groups/users",
+				ExpectedRequest: entitlementsPatch(nil, []string{"allow-cluster-create"}),
+			},
+		},
+		Resource: ResourceEntitlements(),
+		Delete:   true,
+		ID:       "group/users",
+		InstanceState: map[string]string{
+			"group_id":               "users",
+			"allow_cluster_create":   "true",
+			"workspace_access":       "true",
+			"managed_entitlements.#": "2",
+			fmt.Sprintf("managed_entitlements.%d", hash("allow-cluster-create")): "allow-cluster-create",
+			fmt.Sprintf("managed_entitlements.%d", hash("workspace-access")):     "workspace-access",
+		},
+	}.ApplyNoError(t)
+}
+
+func TestResourceEntitlementsDelete_KeepsNotConfigured(t *testing.T) {
+	hash := schema.HashSchema(&schema.Schema{Type: schema.TypeString})
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:          "PATCH",
+				Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: entitlementsPatch(nil, []string{"databricks-sql-access"}),
+			},
+		},
+		Resource: ResourceEntitlements(),
+		Delete:   true,
+		ID:       "group/abc",
+		// allow_cluster_create is granted by databricks_group and only read from the server
+		InstanceState: map[string]string{
+			"group_id":                   "abc",
+			"allow_cluster_create":       "true",
+			"allow_sql_analytics_access": "true",
+			"managed_entitlements.#":     "1",
+			fmt.Sprintf("managed_entitlements.%d", hash("databricks-sql-access")): "databricks-sql-access",
+		},
+		HCL: `
+		group_id = "abc"
+		allow_sql_analytics_access = true
+		`,
+	}.ApplyNoError(t)
+}
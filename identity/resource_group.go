@@ -2,11 +2,51 @@ package identity
 
 import (
 	"context"
+	"log"
+	"sort"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// membersDiff returns members, that are only in the first set
+func membersDiff(a, b *schema.Set) []string {
+	ids := []string{}
+	for _, v := range a.Difference(b).List() {
+		ids = append(ids, v.(string))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// readMembers sets all members of authoritative groups, including imported ones, so that members,
+// that were added outside of Terraform, are shown as drift. Other groups only keep members, that
+// are managed by Terraform, and ignore the rest.
+func readMembers(d *schema.ResourceData, group ScimGroup) error {
+	managed := d.Get("members").(*schema.Set)
+	authoritative := d.Get("authoritative").(bool)
+	if !authoritative && managed.Len() == 0 {
+		return nil
+	}
+	members := []string{}
+	for _, member := range group.Members {
+		if authoritative || managed.Contains(member.Value) {
+			members = append(members, member.Value)
+		}
+	}
+	return d.Set("members", members)
+}
+
+// updateMembers adds and removes members, that were changed in the configuration
+func updateMembers(groupsAPI GroupsAPI, d *schema.ResourceData) error {
+	before, after := d.GetChange("members")
+	err := groupsAPI.AddMembers(d.Id(), membersDiff(after.(*schema.Set), before.(*schema.Set)))
+	if err != nil {
+		return err
+	}
+	return groupsAPI.RemoveMembers(d.Id(), membersDiff(before.(*schema.Set), after.(*schema.Set)))
+}
+
 // ResourceGroup manages user groups
 func ResourceGroup() *schema.Resource {
 	groupSchema := map[string]*schema.Schema{
@@ -14,6 +54,27 @@ func ResourceGroup() *schema.Resource {
 			Type:     schema.TypeString,
 			Required: true,
 		},
+		"external_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"force": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"members": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			// members are read without configuration, but are changed only when it's set
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"authoritative": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
 		"url": {
 			Type:     schema.TypeString,
 			Computed: true,
@@ -23,15 +84,38 @@ func ResourceGroup() *schema.Resource {
 	return common.Resource{
 		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
 			groupName := d.Get("display_name").(string)
-			group, err := NewGroupsAPI(ctx, c).Create(ScimGroup{
+			externalID := d.Get("external_id").(string)
+			groupsAPI := NewGroupsAPI(ctx, c)
+			group, err := groupsAPI.Create(ScimGroup{
 				DisplayName:  groupName,
+				ExternalID:   externalID,
 				Entitlements: readEntitlementsFromData(d),
 			})
+			if common.IsAlreadyExists(err) && d.Get("force").(bool) {
+				// adopt existing group, like one created by SCIM sync
+				existing, err := groupsAPI.readByDisplayNameCached(groupName, adoptAttributes)
+				if err != nil {
+					return err
+				}
+				groupID := existing.ID
+				if externalID == "" {
+					// keep the link to identity provider, that created the group
+					externalID = existing.ExternalID
+				}
+				log.Printf("[INFO] Adopting existing group %s (%s)", groupName, groupID)
+				err = groupsAPI.UpdateNameAndEntitlements(groupID, groupName,
+					externalID, readEntitlementsFromData(d))
+				if err != nil {
+					return err
+				}
+				d.SetId(groupID)
+				return updateMembers(groupsAPI, d)
+			}
 			if err != nil {
 				return err
 			}
 			d.SetId(group.ID)
-			return nil
+			return updateMembers(groupsAPI, d)
 		},
 		Read: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
 			group, err := NewGroupsAPI(ctx, c).Read(d.Id())
@@ -39,12 +123,31 @@ func ResourceGroup() *schema.Resource {
 				return err
 			}
 			d.Set("display_name", group.DisplayName)
+			d.Set("external_id", group.ExternalID)
 			d.Set("url", c.FormatURL("#setting/accounts/groups/", d.Id()))
+			if err = readMembers(d, group); err != nil {
+				return err
+			}
 			return group.Entitlements.readIntoData(d)
 		},
 		Update: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			groupsAPI := NewGroupsAPI(ctx, c)
 			groupName := d.Get("display_name").(string)
-			return NewGroupsAPI(ctx, c).UpdateNameAndEntitlements(d.Id(), groupName, readEntitlementsFromData(d))
+			var err error
+			switch {
+			case !d.HasChangesExcept("members", "authoritative"):
+				// only membership has changed
+			case d.HasChange("display_name") && !d.HasChangesExcept("display_name", "members", "authoritative"):
+				oldName, _ := d.GetChange("display_name")
+				err = groupsAPI.Rename(d.Id(), oldName.(string), groupName)
+			default:
+				err = groupsAPI.UpdateNameAndEntitlements(d.Id(), groupName,
+					d.Get("external_id").(string), readEntitlementsFromData(d))
+			}
+			if err != nil {
+				return err
+			}
+			return updateMembers(groupsAPI, d)
 		},
 		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
 			return NewGroupsAPI(ctx, c).Delete(d.Id())
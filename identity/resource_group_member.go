@@ -2,7 +2,6 @@ package identity
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 
@@ -24,8 +23,8 @@ func ResourceGroupMember() *schema.Resource {
 			return err
 		},
 		DeleteContext: func(ctx context.Context, groupID, memberID string, c *common.DatabricksClient) error {
-			return NewGroupsAPI(ctx, c).Patch(groupID, scimPatchRequest(
-				"remove", fmt.Sprintf(`members[value eq "%s"]`, memberID), ""))
+			// member may be already removed by SCIM sync or another module
+			return NewGroupsAPI(ctx, c).RemoveMembers(groupID, []string{memberID})
 		},
 	})
 }
@@ -197,3 +197,45 @@ func TestResourceGroupMemberDelete_Error(t *testing.T) {
 	qa.AssertErrorStartsWith(t, err, "Internal error happened")
 	assert.Equal(t, "abc|bcd", d.Id())
 }
+
+func TestResourceGroupMemberDelete_AlreadyRemoved(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: common.APIErrorBody{
+					ErrorCode: "NOT_FOUND",
+					Message:   "Member not found",
+				},
+				Status: 404,
+			},
+		},
+		Resource: ResourceGroupMember(),
+		Delete:   true,
+		ID:       "abc|bcd",
+	}.ApplyNoError(t)
+}
+
+func TestResourceGroupMemberRead_NestedGroup(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID: "abc",
+					Members: []ComplexValue{
+						{Value: "bcd", Ref: "Groups/bcd"},
+					},
+				},
+			},
+		},
+		Resource: ResourceGroupMember(),
+		Read:     true,
+		New:      true,
+		ID:       "abc|bcd",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "bcd", d.Get("member_id"))
+}
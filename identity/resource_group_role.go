@@ -0,0 +1,40 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// groupRoleBinding adds and removes a SCIM role of the group without touching the rest of it
+func groupRoleBinding(missing string) common.BindResource {
+	return common.BindResource{
+		ReadContext: func(ctx context.Context, groupID, role string, c *common.DatabricksClient) error {
+			group, err := NewGroupsAPI(ctx, c).Read(groupID)
+			hasRole := complexValues(group.Roles).HasValue(role)
+			if err == nil && !hasRole {
+				return common.NotFound(missing)
+			}
+			return err
+		},
+		CreateContext: func(ctx context.Context, groupID, role string, c *common.DatabricksClient) error {
+			return NewGroupsAPI(ctx, c).Patch(groupID, scimPatchRequest("add", "roles", role))
+		},
+		DeleteContext: func(ctx context.Context, groupID, role string, c *common.DatabricksClient) error {
+			err := NewGroupsAPI(ctx, c).Patch(groupID, scimPatchRequest(
+				"remove", fmt.Sprintf(`roles[value eq "%s"]`, role), ""))
+			if common.IsMissing(err) {
+				// role is already detached outside of Terraform
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// ResourceGroupRole binds group with any SCIM role, like instance profile ARN or account role
+func ResourceGroupRole() *schema.Resource {
+	return common.NewPairID("group_id", "role").BindResource(groupRoleBinding("Group has no role"))
+}
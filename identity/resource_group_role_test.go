@@ -0,0 +1,68 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceGroupRoleCreate(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:          "PATCH",
+				Resource:        "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: scimPatchRequest("add", "roles", "account_admin"),
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:    "abc",
+					Roles: []ComplexValue{{Value: "account_admin"}},
+				},
+			},
+		},
+		Resource: ResourceGroupRole(),
+		HCL: `
+		group_id = "abc"
+		role = "account_admin"
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc|account_admin", d.Id())
+}
+
+func TestResourceGroupRoleRead_NoRole(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{ID: "abc"},
+			},
+		},
+		Resource: ResourceGroupRole(),
+		Read:     true,
+		Removed:  true,
+		ID:       "abc|account_admin",
+	}.ApplyNoError(t)
+}
+
+func TestResourceGroupRoleDelete(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: scimPatchRequest(
+					"remove", `roles[value eq "account_admin"]`, ""),
+			},
+		},
+		Resource: ResourceGroupRole(),
+		Delete:   true,
+		ID:       "abc|account_admin",
+	}.ApplyNoError(t)
+}
@@ -1,11 +1,13 @@
 package identity
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 
 	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -344,3 +346,405 @@ func TestResourceGroupDelete_Error(t *testing.T) {
 		ID:       "abc",
 	}.ExpectError(t, "Internal error happened")
 }
+
+func TestResourceGroupUpdate_ExternalID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					Members:     []ComplexValue{{Value: "1"}},
+				},
+			},
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: ScimGroup{
+					Schemas:     []URN{GroupSchema},
+					DisplayName: "Data Scientists",
+					ExternalID:  "okta-123",
+					Members:     []ComplexValue{{Value: "1"}},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					ExternalID:  "okta-123",
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		InstanceState: map[string]string{
+			"display_name": "Data Scientists",
+		},
+		HCL: `
+		display_name = "Data Scientists"
+		external_id  = "okta-123"
+		`,
+		Update: true,
+		ID:     "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "okta-123", d.Get("external_id"))
+}
+
+func TestResourceGroupUpdate_Rename(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:    "replace",
+							Path:  "displayName",
+							Value: "Data Ninjas",
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Ninjas",
+					Members:     []ComplexValue{{Value: "1"}},
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		InstanceState: map[string]string{
+			"display_name":               "Data Scientists",
+			"allow_cluster_create":       "false",
+			"allow_instance_pool_create": "false",
+			"allow_sql_analytics_access": "false",
+			"workspace_access":           "false",
+		},
+		HCL: `
+		display_name = "Data Ninjas"
+		`,
+		Update: true,
+		ID:     "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "Data Ninjas", d.Get("display_name"))
+}
+
+func TestResourceGroupCreate_Force(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Groups",
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_CONFLICT",
+					Message:   "Group with name Data Scientists already exists.",
+				},
+				Status: 409,
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups?attributes=id%2CdisplayName%2Cmeta%2CexternalId&count=100&filter=displayName%20eq%20%27Data%20Scientists%27",
+				Response: GroupList{
+					Resources: []ScimGroup{{ID: "abc", DisplayName: "Data Scientists"}},
+				},
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/preview/scim/v2/Groups/abc",
+				ReuseRequest: true,
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					Members:     []ComplexValue{{Value: "1"}},
+					Entitlements: entitlements{
+						{Value: "allow-cluster-create"},
+					},
+				},
+			},
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: ScimGroup{
+					Schemas:     []URN{GroupSchema},
+					DisplayName: "Data Scientists",
+					Members:     []ComplexValue{{Value: "1"}},
+					Entitlements: entitlements{
+						{Value: "allow-cluster-create"},
+					},
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		allow_cluster_create = true
+		force = true
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, true, d.Get("allow_cluster_create"))
+}
+func TestResourceGroupCreate_ForceKeepsExternalID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Groups",
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_CONFLICT",
+					Message:   "Group with name Data Scientists already exists.",
+				},
+				Status: 409,
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups?attributes=id%2CdisplayName%2Cmeta%2CexternalId&count=100&filter=displayName%20eq%20%27Data%20Scientists%27",
+				Response: GroupList{
+					Resources: []ScimGroup{{ID: "abc", DisplayName: "Data Scientists", ExternalID: "okta-123"}},
+				},
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/preview/scim/v2/Groups/abc",
+				ReuseRequest: true,
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					ExternalID:  "okta-123",
+					Members:     []ComplexValue{{Value: "1"}},
+					Entitlements: entitlements{
+						{Value: "allow-cluster-create"},
+					},
+				},
+			},
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: ScimGroup{
+					Schemas:     []URN{GroupSchema},
+					DisplayName: "Data Scientists",
+					ExternalID:  "okta-123",
+					Members:     []ComplexValue{{Value: "1"}},
+					Entitlements: entitlements{
+						{Value: "allow-cluster-create"},
+					},
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		allow_cluster_create = true
+		force = true
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "okta-123", d.Get("external_id"))
+}
+
+func TestResourceGroupCreate_Members(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Groups",
+				ExpectedRequest: ScimGroup{
+					Schemas:     []URN{GroupSchema},
+					DisplayName: "Data Scientists",
+				},
+				Response: ScimGroup{
+					ID: "abc",
+				},
+			},
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:    "add",
+							Path:  "members",
+							Value: []ComplexValue{{Value: "1"}, {Value: "2"}},
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					Members:     []ComplexValue{{Value: "1"}, {Value: "2"}},
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		members = ["1", "2"]
+		`,
+		Create: true,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, 2, d.Get("members.#"))
+}
+
+func groupWithExternalMember() qa.HTTPFixture {
+	return qa.HTTPFixture{
+		Method:   "GET",
+		Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+		Response: ScimGroup{
+			ID:          "abc",
+			DisplayName: "Data Scientists",
+			Members:     []ComplexValue{{Value: "1"}, {Value: "scim-added"}},
+		},
+	}
+}
+
+func TestResourceGroupRead_AuthoritativeMembers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{groupWithExternalMember()},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		members = ["1"]
+		`,
+		Read: true,
+		New:  true,
+		ID:   "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, 2, d.Get("members.#"))
+	assertContains(t, d.Get("members"), "scim-added")
+}
+
+func TestResourceGroupRead_NonAuthoritativeMembers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{groupWithExternalMember()},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		members = ["1", "removed-outside"]
+		authoritative = false
+		`,
+		Read: true,
+		New:  true,
+		ID:   "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, 1, d.Get("members.#"))
+	assertContains(t, d.Get("members"), "1")
+}
+
+func TestResourceGroupRead_ImportedAuthoritativeMembers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{groupWithExternalMember()},
+		Resource: ResourceGroup(),
+		Read:     true,
+		New:      true,
+		ID:       "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	// imported group shows all members, so that the first apply shows, which ones are removed
+	assert.Equal(t, 2, d.Get("members.#"))
+	assertContains(t, d.Get("members"), "scim-added")
+}
+
+func TestResourceGroupRead_UnmanagedMembers(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{groupWithExternalMember()},
+		Resource: ResourceGroup(),
+		HCL: `
+		display_name = "Data Scientists"
+		authoritative = false
+		`,
+		Read: true,
+		New:  true,
+		ID:   "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	// members of groups, that are managed by databricks_group_member, are not in the state
+	assert.Equal(t, 0, d.Get("members.#"))
+}
+
+func TestResourceGroupUpdate_Members(t *testing.T) {
+	hash := schema.HashSchema(&schema.Schema{Type: schema.TypeString})
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:    "add",
+							Path:  "members",
+							Value: []ComplexValue{{Value: "3"}},
+						},
+					},
+				},
+			},
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:   "remove",
+							Path: `members[value eq "1"]`,
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Groups/abc",
+				Response: ScimGroup{
+					ID:          "abc",
+					DisplayName: "Data Scientists",
+					Members:     []ComplexValue{{Value: "2"}, {Value: "3"}},
+				},
+			},
+		},
+		Resource: ResourceGroup(),
+		InstanceState: map[string]string{
+			"display_name":                       "Data Scientists",
+			"authoritative":                      "true",
+			"allow_cluster_create":               "false",
+			"allow_instance_pool_create":         "false",
+			"allow_sql_analytics_access":         "false",
+			"workspace_access":                   "false",
+			"members.#":                          "2",
+			fmt.Sprintf("members.%d", hash("1")): "1",
+			fmt.Sprintf("members.%d", hash("2")): "2",
+		},
+		HCL: `
+		display_name = "Data Scientists"
+		members = ["2", "3"]
+		`,
+		Update: true,
+		ID:     "abc",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, 2, d.Get("members.#"))
+	assertContains(t, d.Get("members"), "3")
+}
@@ -3,6 +3,7 @@ package identity
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -67,6 +68,14 @@ func ResourceOboToken() *schema.Resource {
 				return err
 			}
 			// this method is just a shim to check if token does still exist
+			if ot.TokenInfo == nil {
+				return common.NotFound("Token has no info")
+			}
+			expiry := time.Unix(0, ot.TokenInfo.ExpiryTime*int64(time.Millisecond))
+			if ot.TokenInfo.ExpiryTime > 0 && !expiry.After(time.Now()) {
+				// expired token is re-created on the next apply
+				return common.NotFound("Token has expired")
+			}
 			return d.Set("comment", ot.TokenInfo.Comment)
 		},
 		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/databrickslabs/terraform-provider-databricks/qa"
@@ -84,6 +85,27 @@ func TestResourceOboTokenRead(t *testing.T) {
 	assert.Equal(t, "Hello, world!", d.Get("comment"))
 }
 
+func TestResourceOboTokenRead_Expired(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/token-management/tokens/abc",
+				Response: TokenResponse{
+					TokenInfo: &TokenInfo{
+						Comment:    "Hello, world!",
+						ExpiryTime: time.Now().Add(-time.Minute).UnixNano() / int64(time.Millisecond),
+					},
+				},
+			},
+		},
+		Resource: ResourceOboToken(),
+		Read:     true,
+		Removed:  true,
+		ID:       "abc",
+	}.ApplyNoError(t)
+}
+
 func TestResourceOboTokenRead_Error(t *testing.T) {
 	qa.ResourceFixture{
 		Fixtures: []qa.HTTPFixture{
@@ -2,6 +2,7 @@ package identity
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
@@ -20,17 +21,36 @@ type ServicePrincipalsAPI struct {
 	context context.Context
 }
 
-// CreateR ..
+// Create adds new service principal to the workspace
 func (a ServicePrincipalsAPI) Create(rsp ScimUser) (sp ScimUser, err error) {
 	if rsp.Schemas == nil {
 		rsp.Schemas = []URN{ServicePrincipalSchema}
 	}
-	err = a.client.Scim(a.context, "POST", "/preview/scim/v2/ServicePrincipals", rsp, &sp)
+	err = a.client.Scim(a.context, "POST", scimPath(a.client, "ServicePrincipals"), rsp, &sp)
 	return sp, err
 }
 
+// Filter retrieves all pages of service principals matching the filter
+func (a ServicePrincipalsAPI) Filter(filter string) (sps []ScimUser, err error) {
+	req := map[string]string{}
+	if filter != "" {
+		req["filter"] = filter
+	}
+	err = a.client.Paginate(a.context, scimPath(a.client, "ServicePrincipals"), common.ScimPages, req,
+		func(raw json.RawMessage) (int, error) {
+			var page UserList
+			err := json.Unmarshal(raw, &page)
+			sps = append(sps, page.Resources...)
+			return len(page.Resources), err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
 func (a ServicePrincipalsAPI) read(servicePrincipalID string) (sp ScimUser, err error) {
-	servicePrincipalPath := fmt.Sprintf("/preview/scim/v2/ServicePrincipals/%v", servicePrincipalID)
+	servicePrincipalPath := scimPath(a.client, "ServicePrincipals/"+servicePrincipalID)
 	err = a.client.Scim(a.context, "GET", servicePrincipalPath, nil, &sp)
 	return
 }
@@ -46,13 +66,13 @@ func (a ServicePrincipalsAPI) Update(servicePrincipalID string, updateRequest Sc
 	}
 	updateRequest.Groups = servicePrincipal.Groups
 	return a.client.Scim(a.context, "PUT",
-		fmt.Sprintf("/preview/scim/v2/ServicePrincipals/%v", servicePrincipalID),
+		scimPath(a.client, "ServicePrincipals/"+servicePrincipalID),
 		updateRequest, nil)
 }
 
 // Delete will delete the servicePrincipal given the servicePrincipal id
 func (a ServicePrincipalsAPI) Delete(servicePrincipalID string) error {
-	servicePrincipalPath := fmt.Sprintf("/preview/scim/v2/ServicePrincipals/%v", servicePrincipalID)
+	servicePrincipalPath := scimPath(a.client, "ServicePrincipals/"+servicePrincipalID)
 	return a.client.Scim(a.context, "DELETE", servicePrincipalPath, nil, nil)
 }
 
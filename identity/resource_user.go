@@ -2,24 +2,141 @@ package identity
 
 import (
 	"context"
+	"fmt"
+	"log"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/workspace"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// adoptUser brings existing user into the state and updates it with the configuration
+func adoptUser(usersAPI UsersAPI, d *schema.ResourceData, u ScimUser) error {
+	existing, err := usersAPI.Filter(fmt.Sprintf("userName eq '%s'", u.UserName))
+	if err != nil {
+		return err
+	}
+	if len(existing) != 1 {
+		return fmt.Errorf("cannot find user %s to adopt", u.UserName)
+	}
+	current := existing[0]
+	// PUT replaces the whole user, so keep attributes, that are not in the configuration,
+	// like externalId, which links the user to identity provider
+	if u.DisplayName == "" {
+		u.DisplayName = current.DisplayName
+	}
+	if u.ExternalID == "" {
+		u.ExternalID = current.ExternalID
+	}
+	if u.Emails == nil {
+		u.Emails = current.Emails
+	}
+	if u.Name == nil {
+		u.Name = current.Name
+	}
+	log.Printf("[INFO] Adopting existing user %s (%s)", u.UserName, current.ID)
+	err = usersAPI.Update(current.ID, u)
+	if err != nil {
+		return err
+	}
+	d.SetId(current.ID)
+	return nil
+}
+
+// userArtifacts are workspace folders of the user, that are left behind once the user is deleted
+type userArtifacts struct {
+	ctx           context.Context
+	client        *common.DatabricksClient
+	userName      string
+	deleteHomeDir bool
+	deleteRepos   bool
+	repurposeTo   string
+}
+
+type userFolder struct {
+	path   string
+	delete bool
+}
+
+func (a userArtifacts) folders() []userFolder {
+	return []userFolder{
+		{fmt.Sprintf("/Users/%s", a.userName), a.deleteHomeDir},
+		{fmt.Sprintf("/Repos/%s", a.userName), a.deleteRepos},
+	}
+}
+
+// repurpose grants CAN_MANAGE on folders, that are kept, to another user
+func (a userArtifacts) repurpose() error {
+	if a.repurposeTo == "" {
+		return nil
+	}
+	notebooksAPI := workspace.NewNotebooksAPI(a.ctx, a.client)
+	for _, folder := range a.folders() {
+		if folder.delete {
+			continue
+		}
+		status, err := notebooksAPI.Read(folder.path)
+		if common.IsMissing(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] Granting CAN_MANAGE on %s to %s", folder.path, a.repurposeTo)
+		err = a.client.Patch(a.ctx, fmt.Sprintf("/permissions/directories/%d", status.ObjectID),
+			map[string]interface{}{
+				"access_control_list": []map[string]string{
+					{
+						"user_name":        a.repurposeTo,
+						"permission_level": "CAN_MANAGE",
+					},
+				},
+			})
+		if err != nil {
+			return fmt.Errorf("cannot repurpose %s to %s: %w", folder.path, a.repurposeTo, err)
+		}
+	}
+	return nil
+}
+
+// purge deletes home directory and repos of the user, if requested
+func (a userArtifacts) purge() error {
+	notebooksAPI := workspace.NewNotebooksAPI(a.ctx, a.client)
+	for _, folder := range a.folders() {
+		if !folder.delete {
+			continue
+		}
+		err := notebooksAPI.Delete(folder.path, true)
+		if err != nil && !common.IsMissing(err) {
+			return fmt.Errorf("cannot delete %s: %w", folder.path, err)
+		}
+	}
+	return nil
+}
+
 // ResourceUser manages users within workspace
 func ResourceUser() *schema.Resource {
 	type entity struct {
 		UserName    string `json:"user_name"`
 		DisplayName string `json:"display_name,omitempty" tf:"computed"`
+		ExternalID  string `json:"external_id,omitempty" tf:"computed"`
 		Active      bool   `json:"active,omitempty"`
+		// Force adopts existing user with the same user name, like one created by SCIM sync
+		Force bool `json:"force,omitempty"`
+		// DisableAsUserDeletion keeps ownership of notebooks, clusters and jobs for handover
+		DisableAsUserDeletion bool `json:"disable_as_user_deletion,omitempty"`
+		// DeleteHomeDir, DeleteRepos and RepurposeObjectsTo clean up workspace folders on destroy
+		DeleteHomeDir      bool   `json:"delete_home_dir,omitempty"`
+		DeleteRepos        bool   `json:"delete_repos,omitempty"`
+		RepurposeObjectsTo string `json:"repurpose_objects_to,omitempty"`
 	}
 	userSchema := common.StructToSchema(entity{},
 		func(m map[string]*schema.Schema) map[string]*schema.Schema {
 			addEntitlementsToSchema(&m)
 			m["user_name"].ForceNew = true
 			m["active"].Default = true
+			m["repurpose_objects_to"].ConflictsWith = []string{"delete_home_dir"}
 			return m
 		})
 	scimUserFromData := func(d *schema.ResourceData) (user ScimUser, err error) {
@@ -30,6 +147,7 @@ func ResourceUser() *schema.Resource {
 		return ScimUser{
 			UserName:     u.UserName,
 			DisplayName:  u.DisplayName,
+			ExternalID:   u.ExternalID,
 			Active:       u.Active,
 			Entitlements: readEntitlementsFromData(d),
 		}, nil
@@ -41,7 +159,11 @@ func ResourceUser() *schema.Resource {
 			if err != nil {
 				return err
 			}
-			user, err := NewUsersAPI(ctx, c).Create(u)
+			usersAPI := NewUsersAPI(ctx, c)
+			user, err := usersAPI.Create(u)
+			if common.IsAlreadyExists(err) && d.Get("force").(bool) {
+				return adoptUser(usersAPI, d, u)
+			}
 			if err != nil {
 				return err
 			}
@@ -55,6 +177,7 @@ func ResourceUser() *schema.Resource {
 			}
 			d.Set("user_name", user.UserName)
 			d.Set("display_name", user.DisplayName)
+			d.Set("external_id", user.ExternalID)
 			d.Set("active", user.Active)
 			return user.Entitlements.readIntoData(d)
 		},
@@ -66,7 +189,28 @@ func ResourceUser() *schema.Resource {
 			return NewUsersAPI(ctx, c).Update(d.Id(), u)
 		},
 		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
-			return NewUsersAPI(ctx, c).Delete(d.Id())
+			artifacts := userArtifacts{
+				ctx:           ctx,
+				client:        c,
+				userName:      d.Get("user_name").(string),
+				deleteHomeDir: d.Get("delete_home_dir").(bool),
+				deleteRepos:   d.Get("delete_repos").(bool),
+				repurposeTo:   d.Get("repurpose_objects_to").(string),
+			}
+			err := artifacts.repurpose()
+			if err != nil {
+				return err
+			}
+			usersAPI := NewUsersAPI(ctx, c)
+			if d.Get("disable_as_user_deletion").(bool) {
+				err = usersAPI.SetActive(d.Id(), false)
+			} else {
+				err = usersAPI.Delete(d.Id())
+			}
+			if err != nil {
+				return err
+			}
+			return artifacts.purge()
 		},
 	}.ToResource()
 }
@@ -28,8 +28,13 @@ func ResourceUserInstanceProfile() *schema.Resource {
 			return err
 		},
 		DeleteContext: func(ctx context.Context, userID, roleARN string, c *common.DatabricksClient) error {
-			return NewUsersAPI(ctx, c).Patch(userID, scimPatchRequest(
+			err := NewUsersAPI(ctx, c).Patch(userID, scimPatchRequest(
 				"remove", fmt.Sprintf(`roles[value eq "%s"]`, roleARN), ""))
+			if common.IsMissing(err) {
+				// role is already detached outside of Terraform
+				return nil
+			}
+			return err
 		},
 	})
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 
 	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/databrickslabs/terraform-provider-databricks/workspace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -370,3 +371,364 @@ func TestResourceUserDelete_Error(t *testing.T) {
 	}.Apply(t)
 	require.Error(t, err, err)
 }
+
+func TestResourceUserCreate_ExternalID(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Users",
+				ExpectedRequest: ScimUser{
+					Active:     true,
+					UserName:   "me@example.com",
+					ExternalID: "aad-123",
+					Schemas:    []URN{UserSchema},
+				},
+				Response: ScimUser{
+					ID: "abc",
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				Response: ScimUser{
+					Active:     true,
+					UserName:   "me@example.com",
+					ExternalID: "aad-123",
+					ID:         "abc",
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Create:   true,
+		HCL: `
+		user_name   = "me@example.com"
+		external_id = "aad-123"
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	assert.Equal(t, "aad-123", d.Get("external_id"))
+}
+
+func TestResourceUserDelete_Deactivate(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				ExpectedRequest: patchRequest{
+					Schemas: []URN{PatchOp},
+					Operations: []patchOperation{
+						{
+							Op:    "replace",
+							Path:  "active",
+							Value: false,
+						},
+					},
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		disable_as_user_deletion = true
+		`,
+	}.ApplyNoError(t)
+}
+
+func TestResourceUserCreate_Force(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Users",
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_CONFLICT",
+					Message:   "User with username me@example.com already exists.",
+				},
+				Status: 409,
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27me%40example.com%27",
+				Response: UserList{
+					Resources: []ScimUser{{ID: "abc", UserName: "me@example.com"}},
+				},
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/preview/scim/v2/Users/abc",
+				ReuseRequest: true,
+				Response: ScimUser{
+					ID:          "abc",
+					UserName:    "me@example.com",
+					DisplayName: "Example user",
+					Active:      true,
+				},
+			},
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				ExpectedRequest: ScimUser{
+					UserName:    "me@example.com",
+					DisplayName: "Example user",
+					Active:      true,
+					Schemas:     []URN{UserSchema},
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Create:   true,
+		HCL: `
+		user_name    = "me@example.com"
+		display_name = "Example user"
+		force        = true
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+}
+func TestResourceUserCreate_ForceKeepsExistingAttributes(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Users",
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_CONFLICT",
+					Message:   "User with username me@example.com already exists.",
+				},
+				Status: 409,
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20%27me%40example.com%27",
+				Response: UserList{
+					Resources: []ScimUser{{
+						ID:          "abc",
+						UserName:    "me@example.com",
+						DisplayName: "Synced user",
+						ExternalID:  "okta-123",
+						Emails:      []email{{Value: "me@example.com"}},
+					}},
+				},
+			},
+			{
+				Method:       "GET",
+				Resource:     "/api/2.0/preview/scim/v2/Users/abc",
+				ReuseRequest: true,
+				Response: ScimUser{
+					ID:          "abc",
+					UserName:    "me@example.com",
+					DisplayName: "Synced user",
+					ExternalID:  "okta-123",
+					Emails:      []email{{Value: "me@example.com"}},
+					Active:      true,
+				},
+			},
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+				ExpectedRequest: ScimUser{
+					UserName:    "me@example.com",
+					DisplayName: "Synced user",
+					ExternalID:  "okta-123",
+					Emails:      []email{{Value: "me@example.com"}},
+					Active:      true,
+					Schemas:     []URN{UserSchema},
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Create:   true,
+		HCL: `
+		user_name = "me@example.com"
+		force     = true
+		`,
+	}.Apply(t)
+	require.NoError(t, err, err)
+	assert.Equal(t, "abc", d.Id())
+	assert.Equal(t, "okta-123", d.Get("external_id"))
+	assert.Equal(t, "Synced user", d.Get("display_name"))
+}
+
+func TestResourceUserCreate_ConflictWithoutForce(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/preview/scim/v2/Users",
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_CONFLICT",
+					Message:   "User with username me@example.com already exists.",
+				},
+				Status: 409,
+			},
+		},
+		Resource: ResourceUser(),
+		Create:   true,
+		HCL:      `user_name = "me@example.com"`,
+	}.ExpectError(t, "User with username me@example.com already exists.")
+}
+
+func TestResourceUserDelete_HomeDirAndRepos(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "DELETE",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/workspace/delete",
+				ExpectedRequest: workspace.NotebookDeleteRequest{
+					Path:      "/Users/me@example.com",
+					Recursive: true,
+				},
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/workspace/delete",
+				ExpectedRequest: workspace.NotebookDeleteRequest{
+					Path:      "/Repos/me@example.com",
+					Recursive: true,
+				},
+				Status: 404,
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_DOES_NOT_EXIST",
+					Message:   "Path (/Repos/me@example.com) doesn't exist.",
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		delete_home_dir = true
+		delete_repos = true
+		`,
+	}.ApplyNoError(t)
+}
+
+func TestResourceUserDelete_HomeDirError(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "DELETE",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+			},
+			{
+				Method:   "POST",
+				Resource: "/api/2.0/workspace/delete",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "DIRECTORY_PROTECTED",
+					Message:   "Folder is protected",
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		delete_home_dir = true
+		`,
+	}.ExpectError(t, "cannot delete /Users/me@example.com: Folder is protected")
+}
+
+func TestResourceUserDelete_RepurposeConflictsWithHomeDir(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		delete_home_dir = true
+		repurpose_objects_to = "successor@example.com"
+		`,
+	}.ExpectError(t, "invalid config supplied. [repurpose_objects_to] Conflicting configuration arguments")
+}
+
+func TestResourceUserDelete_RepurposeHomeDir(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/workspace/get-status?path=%2FUsers%2Fme%40example.com",
+				Response: workspace.ObjectStatus{
+					ObjectID:   123,
+					ObjectType: workspace.Directory,
+					Path:       "/Users/me@example.com",
+				},
+			},
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/permissions/directories/123",
+				ExpectedRequest: map[string]interface{}{
+					"access_control_list": []map[string]string{
+						{
+							"user_name":        "successor@example.com",
+							"permission_level": "CAN_MANAGE",
+						},
+					},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/workspace/get-status?path=%2FRepos%2Fme%40example.com",
+				Status:   404,
+				Response: common.APIErrorBody{
+					ErrorCode: "RESOURCE_DOES_NOT_EXIST",
+					Message:   "Path (/Repos/me@example.com) doesn't exist.",
+				},
+			},
+			{
+				Method:   "DELETE",
+				Resource: "/api/2.0/preview/scim/v2/Users/abc",
+			},
+		},
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		repurpose_objects_to = "successor@example.com"
+		`,
+	}.ApplyNoError(t)
+}
+
+func TestResourceUserDelete_RepurposeError(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/workspace/get-status?path=%2FUsers%2Fme%40example.com",
+				Response: workspace.ObjectStatus{
+					ObjectID: 123,
+					Path:     "/Users/me@example.com",
+				},
+			},
+			{
+				Method:   "PATCH",
+				Resource: "/api/2.0/permissions/directories/123",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "User successor@example.com does not exist",
+				},
+			},
+		},
+		Resource: ResourceUser(),
+		Delete:   true,
+		ID:       "abc",
+		HCL: `
+		user_name = "me@example.com"
+		repurpose_objects_to = "successor@example.com"
+		`,
+	}.ExpectError(t, "cannot repurpose /Users/me@example.com to successor@example.com: "+
+		"User successor@example.com does not exist")
+}
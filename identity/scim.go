@@ -1,6 +1,9 @@
 package identity
 
 import (
+	"fmt"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -14,6 +17,7 @@ const (
 	WorkspaceUserSchema    URN = "urn:ietf:params:scim:schemas:extension:workspace:2.0:User"
 	PatchOp                URN = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
 	GroupSchema            URN = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	BulkRequest            URN = "urn:ietf:params:scim:api:messages:2.0:BulkRequest"
 )
 
 // Generalisation of most common complex values from SCIM protocol
@@ -38,6 +42,17 @@ func (cv complexValues) HasValue(value string) bool {
 	return false
 }
 
+// union keeps the order of current values and appends missing values from other
+func (cv complexValues) union(other complexValues) complexValues {
+	result := append(complexValues{}, cv...)
+	for _, v := range other {
+		if !result.HasValue(v.Value) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 var entitlementMapping = map[string]string{
 	"allow-cluster-create":       "allow_cluster_create",
 	"allow-instance-pool-create": "allow_instance_pool_create",
@@ -89,15 +104,46 @@ func addEntitlementsToSchema(s *map[string]*schema.Schema) {
 	}
 }
 
-// ScimGroup contains information about the SCIM group
+// addComputedEntitlementsToSchema exposes entitlements in data sources
+func addComputedEntitlementsToSchema(s map[string]*schema.Schema) {
+	for _, entitlement := range possibleEntitlements {
+		s[entitlementMapping[entitlement]] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		}
+	}
+}
+
+// scimPath returns path of SCIM resource, like "Users/123", within the workspace or,
+// if the provider is configured for accounts console, within the account
+func scimPath(client *common.DatabricksClient, resource string) string {
+	if client.IsAccountClient() {
+		return fmt.Sprintf("/accounts/%s/scim/v2/%s", client.AccountID, resource)
+	}
+	return "/preview/scim/v2/" + resource
+}
+
+// ScimMeta is common resource metadata
+// Details at https://datatracker.ietf.org/doc/html/rfc7643#section-3.1
+type ScimMeta struct {
+	ResourceType string `json:"resourceType,omitempty"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// ScimGroup contains information about the SCIM group. Groups are parents of the group
+// and are read-only: groups are nested by adding them to Members of another group.
 type ScimGroup struct {
 	ID           string         `json:"id,omitempty"`
 	Schemas      []URN          `json:"schemas,omitempty"`
 	DisplayName  string         `json:"displayName,omitempty"`
+	ExternalID   string         `json:"externalId,omitempty"`
 	Members      []ComplexValue `json:"members,omitempty"`
 	Groups       []ComplexValue `json:"groups,omitempty"`
 	Roles        []ComplexValue `json:"roles,omitempty"`
 	Entitlements entitlements   `json:"entitlements,omitempty"`
+	Meta         *ScimMeta      `json:"meta,omitempty"`
 }
 
 // GroupList contains a list of groups fetched from a list api call from SCIM api
@@ -124,6 +170,7 @@ type ScimUser struct {
 	Schemas       []URN             `json:"schemas,omitempty"`
 	UserName      string            `json:"userName,omitempty" tf:"alias:user_name"`
 	ApplicationID string            `json:"applicationId,omitempty" tf:"alias:application_id"`
+	ExternalID    string            `json:"externalId,omitempty" tf:"alias:external_id"`
 	Groups        []ComplexValue    `json:"groups,omitempty"`
 	Name          map[string]string `json:"name,omitempty"`
 	Roles         []ComplexValue    `json:"roles,omitempty"`
@@ -150,6 +197,51 @@ type patchRequest struct {
 	Operations []patchOperation `json:"Operations,omitempty"`
 }
 
+// weight is the number of values changed by the operation
+func (o patchOperation) weight() int {
+	if values, ok := o.Value.([]ComplexValue); ok && len(values) > 0 {
+		return len(values)
+	}
+	return 1
+}
+
+// batches splits the request into requests with at most size values each. Operations
+// with more values, like adding thousands of members, are split as well.
+func (r patchRequest) batches(size int) []patchRequest {
+	if size <= 0 || len(r.Operations) == 0 {
+		return []patchRequest{r}
+	}
+	ops := []patchOperation{}
+	for _, op := range r.Operations {
+		values, ok := op.Value.([]ComplexValue)
+		if !ok || len(values) <= size {
+			ops = append(ops, op)
+			continue
+		}
+		for len(values) > 0 {
+			n := size
+			if n > len(values) {
+				n = len(values)
+			}
+			ops = append(ops, patchOperation{Op: op.Op, Path: op.Path, Value: values[:n]})
+			values = values[n:]
+		}
+	}
+	batches := []patchRequest{}
+	current := patchRequest{Schemas: r.Schemas}
+	weight := 0
+	for _, op := range ops {
+		if weight > 0 && weight+op.weight() > size {
+			batches = append(batches, current)
+			current = patchRequest{Schemas: r.Schemas}
+			weight = 0
+		}
+		current.Operations = append(current.Operations, op)
+		weight += op.weight()
+	}
+	return append(batches, current)
+}
+
 func scimPatchRequest(op, path, value string) patchRequest {
 	o := patchOperation{
 		Op:   op,
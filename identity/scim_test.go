@@ -0,0 +1,64 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScimPath(t *testing.T) {
+	workspace := &common.DatabricksClient{Host: "https://abc.cloud.databricks.com", AccountID: "x"}
+	assert.Equal(t, "/preview/scim/v2/Users/123", scimPath(workspace, "Users/123"))
+
+	account := &common.DatabricksClient{Host: "https://accounts.cloud.databricks.com", AccountID: "x"}
+	assert.Equal(t, "/accounts/x/scim/v2/Groups", scimPath(account, "Groups"))
+	assert.Equal(t, "/accounts/x/scim/v2/ServicePrincipals/123", scimPath(account, "ServicePrincipals/123"))
+
+	noAccountID := &common.DatabricksClient{Host: "https://accounts.cloud.databricks.com"}
+	assert.Equal(t, "/preview/scim/v2/Me", scimPath(noAccountID, "Me"))
+}
+
+func TestPatchRequestBatches(t *testing.T) {
+	members := func(ids ...string) (values []ComplexValue) {
+		for _, id := range ids {
+			values = append(values, ComplexValue{Value: id})
+		}
+		return
+	}
+	r := patchRequest{
+		Schemas: []URN{PatchOp},
+		Operations: []patchOperation{
+			{Op: "add", Path: "members", Value: members("1", "2", "3", "4", "5")},
+			{Op: "remove", Path: `members[value eq "6"]`},
+			{Op: "replace", Path: "displayName", Value: "x"},
+		},
+	}
+	batches := r.batches(2)
+	assert.Equal(t, []patchRequest{
+		{
+			Schemas:    []URN{PatchOp},
+			Operations: []patchOperation{{Op: "add", Path: "members", Value: members("1", "2")}},
+		},
+		{
+			Schemas:    []URN{PatchOp},
+			Operations: []patchOperation{{Op: "add", Path: "members", Value: members("3", "4")}},
+		},
+		{
+			Schemas: []URN{PatchOp},
+			Operations: []patchOperation{
+				{Op: "add", Path: "members", Value: members("5")},
+				{Op: "remove", Path: `members[value eq "6"]`},
+			},
+		},
+		{
+			Schemas:    []URN{PatchOp},
+			Operations: []patchOperation{{Op: "replace", Path: "displayName", Value: "x"}},
+		},
+	}, batches)
+
+	assert.Equal(t, []patchRequest{r}, r.batches(-1))
+	assert.Equal(t, []patchRequest{r}, r.batches(100))
+	empty := patchRequest{Schemas: []URN{PatchOp}}
+	assert.Equal(t, []patchRequest{empty}, empty.batches(2))
+}
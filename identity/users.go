@@ -2,7 +2,7 @@ package identity
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"net/http"
 
 	"github.com/databrickslabs/terraform-provider-databricks/common"
@@ -27,33 +27,37 @@ func (a UsersAPI) Create(ru ScimUser) (user ScimUser, err error) {
 	if ru.Schemas == nil {
 		ru.Schemas = []URN{UserSchema}
 	}
-	err = a.client.Scim(a.context, http.MethodPost, "/preview/scim/v2/Users", ru, &user)
+	err = a.client.Scim(a.context, http.MethodPost, scimPath(a.client, "Users"), ru, &user)
 	return user, err
 }
 
-// Filter retrieves users by filter
+// Filter retrieves all pages of users matching the filter
 func (a UsersAPI) Filter(filter string) (u []ScimUser, err error) {
-	var users UserList
 	req := map[string]string{}
 	if filter != "" {
 		req["filter"] = filter
 	}
-	err = a.client.Scim(a.context, http.MethodGet, "/preview/scim/v2/Users", req, &users)
+	err = a.client.Paginate(a.context, scimPath(a.client, "Users"), common.ScimPages, req,
+		func(raw json.RawMessage) (int, error) {
+			var page UserList
+			err := json.Unmarshal(raw, &page)
+			u = append(u, page.Resources...)
+			return len(page.Resources), err
+		})
 	if err != nil {
-		return
+		return nil, err
 	}
-	u = users.Resources
 	return
 }
 
 func (a UsersAPI) read(userID string) (ScimUser, error) {
-	userPath := fmt.Sprintf("/preview/scim/v2/Users/%v", userID)
+	userPath := scimPath(a.client, "Users/"+userID)
 	return a.readByPath(userPath)
 }
 
 // Me gets user information about caller
 func (a UsersAPI) Me() (ScimUser, error) {
-	return a.readByPath("/preview/scim/v2/Me")
+	return a.readByPath(scimPath(a.client, "Me"))
 }
 
 func (a UsersAPI) readByPath(userPath string) (user ScimUser, err error) {
@@ -73,17 +77,31 @@ func (a UsersAPI) Update(userID string, updateRequest ScimUser) error {
 		updateRequest.Schemas = []URN{UserSchema}
 	}
 	return a.client.Scim(a.context, http.MethodPut,
-		fmt.Sprintf("/preview/scim/v2/Users/%v", userID),
+		scimPath(a.client, "Users/"+userID),
 		updateRequest, nil)
 }
 
 // Patch updates resource-friendly entity
 func (a UsersAPI) Patch(userID string, r patchRequest) error {
-	return a.client.Scim(a.context, http.MethodPatch, fmt.Sprintf("/preview/scim/v2/Users/%v", userID), r, nil)
+	return a.client.Scim(a.context, http.MethodPatch, scimPath(a.client, "Users/"+userID), r, nil)
+}
+
+// SetActive activates or deactivates the user, who keeps workspace assets while inactive
+func (a UsersAPI) SetActive(userID string, active bool) error {
+	return a.Patch(userID, patchRequest{
+		Schemas: []URN{PatchOp},
+		Operations: []patchOperation{
+			{
+				Op:    "replace",
+				Path:  "active",
+				Value: active,
+			},
+		},
+	})
 }
 
 // Delete will delete the user given the user id
 func (a UsersAPI) Delete(userID string) error {
-	userPath := fmt.Sprintf("/preview/scim/v2/Users/%v", userID)
+	userPath := scimPath(a.client, "Users/"+userID)
 	return a.client.Scim(a.context, http.MethodDelete, userPath, nil, nil)
 }
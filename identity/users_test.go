@@ -69,7 +69,7 @@ func TestUsersFilter(t *testing.T) {
 	client, server, err := qa.HttpFixtureClient(t, []qa.HTTPFixture{
 		{
 			Method:   "GET",
-			Resource: "/api/2.0/preview/scim/v2/Users?",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100",
 
 			Response: UserList{
 				Resources: []ScimUser{
@@ -79,7 +79,7 @@ func TestUsersFilter(t *testing.T) {
 		},
 		{
 			Method:   "GET",
-			Resource: "/api/2.0/preview/scim/v2/Users?filter=userName%20eq%20somebody",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=userName%20eq%20somebody",
 			Response: UserList{},
 		},
 	})
@@ -95,3 +95,37 @@ func TestUsersFilter(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, users, 0)
 }
+
+func TestUsersFilter_Paginated(t *testing.T) {
+	qa.HTTPFixturesApply(t, []qa.HTTPFixture{
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=active%20eq%20true",
+			Response: UserList{
+				TotalResults: 3,
+				ItemsPerPage: 2,
+				Resources: []ScimUser{
+					{ID: "a", UserName: "a@example.com"},
+					{ID: "b", UserName: "b@example.com"},
+				},
+			},
+		},
+		{
+			Method:   "GET",
+			Resource: "/api/2.0/preview/scim/v2/Users?count=100&filter=active%20eq%20true&startIndex=3",
+			Response: UserList{
+				TotalResults: 3,
+				ItemsPerPage: 1,
+				StartIndex:   3,
+				Resources: []ScimUser{
+					{ID: "c", UserName: "c@example.com"},
+				},
+			},
+		},
+	}, func(ctx context.Context, client *common.DatabricksClient) {
+		users, err := NewUsersAPI(ctx, client).Filter("active eq true")
+		require.NoError(t, err)
+		assert.Len(t, users, 3)
+		assert.Equal(t, "c", users[2].ID)
+	})
+}
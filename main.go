@@ -18,7 +18,10 @@ func main() {
 		return
 	}
 	if len(os.Args) > 1 && os.Args[1] == "exporter" {
-		if err := exporter.Run(os.Args...); err != nil {
+		err := exporter.Run(os.Args...)
+		common.LogAPIMetrics()
+		common.FlushAPITraces()
+		if err != nil {
 			log.Printf("[ERROR] %s", err.Error())
 			os.Exit(1)
 		}
@@ -32,4 +35,7 @@ https://registry.terraform.io/providers/databrickslabs/databricks/latest/docs
 
 `, common.Version())
 	plugin.Serve(&plugin.ServeOpts{ProviderFunc: provider.DatabricksProvider})
+	// Terraform stops the provider process at the end of plan or apply
+	common.LogAPIMetrics()
+	common.FlushAPITraces()
 }
@@ -0,0 +1,175 @@
+package mws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// PermissionAssignmentPrincipal is user, group or service principal of the account
+type PermissionAssignmentPrincipal struct {
+	PrincipalID          int64  `json:"principal_id"`
+	DisplayName          string `json:"display_name,omitempty"`
+	UserName             string `json:"user_name,omitempty"`
+	GroupName            string `json:"group_name,omitempty"`
+	ServicePrincipalName string `json:"service_principal_name,omitempty"`
+}
+
+// PermissionAssignment gives USER or ADMIN permissions on a workspace to an account principal
+type PermissionAssignment struct {
+	Principal   PermissionAssignmentPrincipal `json:"principal"`
+	Permissions []string                      `json:"permissions"`
+	Error       string                        `json:"error,omitempty"`
+}
+
+// PermissionAssignmentList is the list of assignments of a workspace
+type PermissionAssignmentList struct {
+	PermissionAssignments []PermissionAssignment `json:"permission_assignments"`
+}
+
+// NewPermissionAssignmentAPI creates PermissionAssignmentAPI instance from provider meta
+func NewPermissionAssignmentAPI(ctx context.Context, m interface{}) PermissionAssignmentAPI {
+	return PermissionAssignmentAPI{m.(*common.DatabricksClient), ctx}
+}
+
+// PermissionAssignmentAPI exposes the workspace assignment API of identity-federated accounts
+type PermissionAssignmentAPI struct {
+	client  *common.DatabricksClient
+	context context.Context
+}
+
+func (a PermissionAssignmentAPI) principalPath(workspaceID, principalID int64) (string, error) {
+	if a.client.AccountID == "" {
+		return "", fmt.Errorf("provider must be configured with account_id")
+	}
+	return fmt.Sprintf("/accounts/%s/workspaces/%d/permissionassignments/principals/%d",
+		a.client.AccountID, workspaceID, principalID), nil
+}
+
+// Assign creates or replaces permissions of the principal on the workspace
+func (a PermissionAssignmentAPI) Assign(workspaceID, principalID int64, permissions []string) error {
+	path, err := a.principalPath(workspaceID, principalID)
+	if err != nil {
+		return err
+	}
+	return a.client.Put(a.context, path, map[string][]string{
+		"permissions": permissions,
+	})
+}
+
+// List returns all permission assignments of the workspace
+func (a PermissionAssignmentAPI) List(workspaceID int64) (list PermissionAssignmentList, err error) {
+	if a.client.AccountID == "" {
+		err = fmt.Errorf("provider must be configured with account_id")
+		return
+	}
+	err = a.client.Get(a.context, fmt.Sprintf("/accounts/%s/workspaces/%d/permissionassignments",
+		a.client.AccountID, workspaceID), nil, &list)
+	return
+}
+
+// Read returns assignment of the principal or NotFound, if principal is not assigned
+func (a PermissionAssignmentAPI) Read(workspaceID, principalID int64) (PermissionAssignment, error) {
+	list, err := a.List(workspaceID)
+	if err != nil {
+		return PermissionAssignment{}, err
+	}
+	for _, assignment := range list.PermissionAssignments {
+		if assignment.Principal.PrincipalID == principalID {
+			return assignment, nil
+		}
+	}
+	return PermissionAssignment{}, common.NotFound(
+		fmt.Sprintf("Principal %d is not assigned to workspace %d", principalID, workspaceID))
+}
+
+// Remove deletes permissions of the principal on the workspace
+func (a PermissionAssignmentAPI) Remove(workspaceID, principalID int64) error {
+	path, err := a.principalPath(workspaceID, principalID)
+	if err != nil {
+		return err
+	}
+	return a.client.Delete(a.context, path, nil)
+}
+
+// ResourcePermissionAssignment assigns account-level users, groups and service principals
+// to a workspace with identity federation
+func ResourcePermissionAssignment() *schema.Resource {
+	type entity struct {
+		WorkspaceID int64    `json:"workspace_id"`
+		PrincipalID int64    `json:"principal_id"`
+		Permissions []string `json:"permissions" tf:"slice_set"`
+	}
+	s := common.StructToSchema(entity{},
+		func(s map[string]*schema.Schema) map[string]*schema.Schema {
+			s["workspace_id"].ForceNew = true
+			s["principal_id"].ForceNew = true
+			s["permissions"].Elem = &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"USER", "ADMIN"}, false),
+			}
+			return s
+		})
+	p := common.NewPairID("workspace_id", "principal_id")
+	unpack := func(d *schema.ResourceData) (workspaceID, principalID int64, err error) {
+		left, right, err := p.Unpack(d)
+		if err != nil {
+			return 0, 0, err
+		}
+		workspaceID, err = strconv.ParseInt(left, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid workspace_id: %w", err)
+		}
+		principalID, err = strconv.ParseInt(right, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid principal_id: %w", err)
+		}
+		return
+	}
+	assign := func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+		var assignment entity
+		if err := common.DataToStructPointer(d, s, &assignment); err != nil {
+			return err
+		}
+		return NewPermissionAssignmentAPI(ctx, c).Assign(
+			assignment.WorkspaceID, assignment.PrincipalID, assignment.Permissions)
+	}
+	return common.Resource{
+		Schema: s,
+		Create: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			if err := assign(ctx, d, c); err != nil {
+				return err
+			}
+			p.Pack(d)
+			return nil
+		},
+		Read: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			workspaceID, principalID, err := unpack(d)
+			if err != nil {
+				return err
+			}
+			assignment, err := NewPermissionAssignmentAPI(ctx, c).Read(workspaceID, principalID)
+			if err != nil {
+				return err
+			}
+			return common.StructToData(entity{
+				WorkspaceID: workspaceID,
+				PrincipalID: principalID,
+				Permissions: assignment.Permissions,
+			}, s, d)
+		},
+		Update: assign,
+		Delete: func(ctx context.Context, d *schema.ResourceData, c *common.DatabricksClient) error {
+			workspaceID, principalID, err := unpack(d)
+			if err != nil {
+				return err
+			}
+			return NewPermissionAssignmentAPI(ctx, c).Remove(workspaceID, principalID)
+		},
+	}.ToResource()
+}
@@ -0,0 +1,192 @@
+package mws
+
+import (
+	"testing"
+
+	"github.com/databrickslabs/terraform-provider-databricks/common"
+	"github.com/databrickslabs/terraform-provider-databricks/qa"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var permissionAssignments = PermissionAssignmentList{
+	PermissionAssignments: []PermissionAssignment{
+		{
+			Principal:   PermissionAssignmentPrincipal{PrincipalID: 345, UserName: "me@example.com"},
+			Permissions: []string{"ADMIN"},
+		},
+		{
+			Principal:   PermissionAssignmentPrincipal{PrincipalID: 678, GroupName: "data-engineers"},
+			Permissions: []string{"USER"},
+		},
+	},
+}
+
+func TestResourcePermissionAssignmentCreate(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments/principals/345",
+				ExpectedRequest: map[string][]string{
+					"permissions": {"ADMIN"},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments",
+				Response: permissionAssignments,
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Create:    true,
+		HCL: `
+		workspace_id = 123
+		principal_id = 345
+		permissions  = ["ADMIN"]
+		`,
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, "123|345", d.Id())
+	assert.Equal(t, 1, d.Get("permissions.#"))
+}
+
+func TestResourcePermissionAssignmentRead(t *testing.T) {
+	d, err := qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments",
+				Response: permissionAssignments,
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Read:      true,
+		New:       true,
+		ID:        "123|678",
+	}.Apply(t)
+	assert.NoError(t, err, err)
+	assert.Equal(t, 123, d.Get("workspace_id"))
+	assert.Equal(t, 678, d.Get("principal_id"))
+	assert.True(t, d.Get("permissions").(*schema.Set).Contains("USER"))
+}
+
+func TestResourcePermissionAssignmentRead_NotAssigned(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments",
+				Response: permissionAssignments,
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Read:      true,
+		Removed:   true,
+		ID:        "123|999",
+	}.ApplyNoError(t)
+}
+
+func TestResourcePermissionAssignmentUpdate(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments/principals/678",
+				ExpectedRequest: map[string][]string{
+					"permissions": {"ADMIN"},
+				},
+			},
+			{
+				Method:   "GET",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments",
+				Response: permissionAssignments,
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Update:    true,
+		ID:        "123|678",
+		InstanceState: map[string]string{
+			"workspace_id":  "123",
+			"principal_id":  "678",
+			"permissions.#": "1",
+		},
+		HCL: `
+		workspace_id = 123
+		principal_id = 678
+		permissions  = ["ADMIN"]
+		`,
+	}.ApplyNoError(t)
+}
+
+func TestResourcePermissionAssignmentDelete(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "DELETE",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments/principals/345",
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Delete:    true,
+		ID:        "123|345",
+	}.ApplyNoError(t)
+}
+
+func TestResourcePermissionAssignmentRead_InvalidID(t *testing.T) {
+	for id, message := range map[string]string{
+		"123":     "invalid ID: 123",
+		"|345":    "workspace_id cannot be empty",
+		"abc|345": `invalid workspace_id: strconv.ParseInt: parsing "abc": invalid syntax`,
+		"123|xyz": `invalid principal_id: strconv.ParseInt: parsing "xyz": invalid syntax`,
+	} {
+		qa.ResourceFixture{
+			Resource:  ResourcePermissionAssignment(),
+			AccountID: "abc",
+			Read:      true,
+			New:       true,
+			ID:        id,
+		}.ExpectError(t, message)
+	}
+}
+
+func TestResourcePermissionAssignmentCreate_NoAccountID(t *testing.T) {
+	qa.ResourceFixture{
+		Resource: ResourcePermissionAssignment(),
+		Create:   true,
+		HCL: `
+		workspace_id = 123
+		principal_id = 345
+		permissions  = ["USER"]
+		`,
+	}.ExpectError(t, "provider must be configured with account_id")
+}
+
+func TestResourcePermissionAssignmentCreate_Error(t *testing.T) {
+	qa.ResourceFixture{
+		Fixtures: []qa.HTTPFixture{
+			{
+				Method:   "PUT",
+				Resource: "/api/2.0/accounts/abc/workspaces/123/permissionassignments/principals/345",
+				Status:   400,
+				Response: common.APIErrorBody{
+					ErrorCode: "INVALID_PARAMETER_VALUE",
+					Message:   "Workspace is not enabled for identity federation",
+				},
+			},
+		},
+		Resource:  ResourcePermissionAssignment(),
+		AccountID: "abc",
+		Create:    true,
+		HCL: `
+		workspace_id = 123
+		principal_id = 345
+		permissions  = ["USER"]
+		`,
+	}.ExpectError(t, "Workspace is not enabled for identity federation")
+}
@@ -31,7 +31,7 @@ func (a VPCEndpointAPI) Create(vpcEndpoint *VPCEndpoint) error {
 	if err != nil {
 		return err
 	}
-	return resource.RetryContext(a.context, 15*time.Minute, func() *resource.RetryError {
+	return resource.RetryContext(a.context, common.OperationTimeout(a.context, 15*time.Minute), func() *resource.RetryError {
 		ve, err := a.Read(vpcEndpoint.AccountID, vpcEndpoint.VPCEndpointID)
 		if err != nil {
 			return resource.NonRetryableError(err)
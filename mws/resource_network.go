@@ -44,7 +44,7 @@ func (a NetworksAPI) Delete(mwsAcctID, networksID string) error {
 	if err := a.client.Delete(a.context, networksAPIPath, nil); err != nil {
 		return err
 	}
-	return resource.RetryContext(a.context, 60*time.Second, func() *resource.RetryError {
+	return resource.RetryContext(a.context, common.OperationTimeout(a.context, 60*time.Second), func() *resource.RetryError {
 		network, err := a.Read(mwsAcctID, networksID)
 		if common.IsMissing(err) {
 			log.Printf("[INFO] Network %s/%s is removed.", mwsAcctID, networksID)
@@ -173,7 +173,7 @@ func (a WorkspacesAPI) Delete(mwsAcctID, workspaceID string) error {
 	if err != nil {
 		return err
 	}
-	return resource.RetryContext(a.context, 15*time.Minute, func() *resource.RetryError {
+	return resource.RetryContext(a.context, common.OperationTimeout(a.context, 15*time.Minute), func() *resource.RetryError {
 		workspace, err := a.Read(mwsAcctID, workspaceID)
 		if common.IsMissing(err) {
 			log.Printf("[INFO] Workspace %s/%s is removed.", mwsAcctID, workspaceID)
@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"log"
+	"os"
 	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/databrickslabs/terraform-provider-databricks/access"
 	"github.com/databrickslabs/terraform-provider-databricks/common"
@@ -26,15 +28,24 @@ func DatabricksProvider() *schema.Provider {
 			"databricks_aws_crossaccount_policy": access.DataAwsCrossAccountPolicy(),
 			"databricks_aws_assume_role_policy":  access.DataAwsAssumeRolePolicy(),
 			"databricks_aws_bucket_policy":       access.DataAwsBucketPolicy(),
+			"databricks_cluster":                 compute.DataSourceCluster(),
+			"databricks_cluster_events":          compute.DataSourceClusterEvents(),
+			"databricks_clusters":                compute.DataSourceClusters(),
+			"databricks_current_config":          identity.DataSourceCurrentConfig(),
 			"databricks_current_user":            identity.DataSourceCurrentUser(),
 			"databricks_dbfs_file":               storage.DataSourceDBFSFile(),
 			"databricks_dbfs_file_paths":         storage.DataSourceDBFSFilePaths(),
 			"databricks_group":                   identity.DataSourceGroup(),
+			"databricks_group_members":           identity.DataSourceGroupMembers(),
+			"databricks_instance_pool":           compute.DataSourceInstancePool(),
 			"databricks_node_type":               compute.DataSourceNodeType(),
 			"databricks_notebook":                workspace.DataSourceNotebook(),
 			"databricks_notebook_paths":          workspace.DataSourceNotebookPaths(),
+			"databricks_policy_family":           compute.DataSourcePolicyFamily(),
+			"databricks_service_principals":      identity.DataSourceServicePrincipals(),
 			"databricks_spark_version":           compute.DataSourceSparkVersion(),
 			"databricks_user":                    identity.DataSourceUser(),
+			"databricks_users":                   identity.DataSourceUsers(),
 			"databricks_zones":                   compute.DataSourceClusterZones(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
@@ -49,10 +60,12 @@ func DatabricksProvider() *schema.Provider {
 			"databricks_cluster_policy": compute.ResourceClusterPolicy(),
 			"databricks_instance_pool":  compute.ResourceInstancePool(),
 			"databricks_job":            compute.ResourceJob(),
+			"databricks_library":        compute.ResourceLibrary(),
 			"databricks_pipeline":       compute.ResourcePipeline(),
 
 			"databricks_group":                  identity.ResourceGroup(),
 			"databricks_group_instance_profile": identity.ResourceGroupInstanceProfile(),
+			"databricks_group_role":             identity.ResourceGroupRole(),
 			"databricks_user_instance_profile":  identity.ResourceUserInstanceProfile(),
 			"databricks_instance_profile":       identity.ResourceInstanceProfile(),
 			"databricks_group_member":           identity.ResourceGroupMember(),
@@ -60,11 +73,13 @@ func DatabricksProvider() *schema.Provider {
 			"databricks_token":                  identity.ResourceToken(),
 			"databricks_user":                   identity.ResourceUser(),
 			"databricks_service_principal":      identity.ResourceServicePrincipal(),
+			"databricks_entitlements":           identity.ResourceEntitlements(),
 
 			"databricks_mws_customer_managed_keys":   mws.ResourceCustomerManagedKey(),
 			"databricks_mws_credentials":             mws.ResourceCredentials(),
 			"databricks_mws_log_delivery":            mws.ResourceLogDelivery(),
 			"databricks_mws_networks":                mws.ResourceNetwork(),
+			"databricks_mws_permission_assignment":   mws.ResourcePermissionAssignment(),
 			"databricks_mws_private_access_settings": mws.ResourcePrivateAccessSettings(),
 			"databricks_mws_storage_configurations":  mws.ResourceStorageConfiguration(),
 			"databricks_mws_vpc_endpoint":            mws.ResourceVPCEndpoint(),
@@ -125,6 +140,56 @@ func DatabricksProvider() *schema.Provider {
 					"token",
 				},
 			},
+			"account_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_ACCOUNT_ID", nil),
+			},
+			"account_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_ACCOUNT_HOST", nil),
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_CLIENT_ID", nil),
+				ConflictsWith: []string{
+					"token",
+					"username",
+				},
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_CLIENT_SECRET", nil),
+				ConflictsWith: []string{
+					"token",
+					"password",
+				},
+			},
+			"auth_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Forces the only authentication method, like oauth-u2m for browser-based login.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_AUTH_TYPE", nil),
+				ValidateFunc: validation.StringInSlice([]string{common.AuthTypeOAuthU2M}, false),
+			},
+			"credential_helper": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Command, that prints token for the host, like one reading it from OS keychain.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_CREDENTIAL_HELPER", nil),
+				ConflictsWith: []string{
+					"token",
+				},
+			},
+			"oidc_audience": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_OIDC_AUDIENCE", nil),
+			},
 			"config_file": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -204,12 +269,12 @@ func DatabricksProvider() *schema.Provider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "Currently secret scopes are not accessible via AAD tokens so we will need to create a PAT token",
-				Default:     "3600",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_AZURE_PAT_TOKEN_DURATION_SECONDS", "3600"),
 			},
 			"azure_use_pat_for_cli": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     false,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_AZURE_USE_PAT_FOR_CLI", false),
 				Description: "Create ephemeral PAT tokens also for AZ CLI authenticated requests",
 			},
 			"azure_use_pat_for_spn": {
@@ -219,21 +284,109 @@ func DatabricksProvider() *schema.Provider {
 				Description: "Create ephemeral PAT tokens instead of AAD tokens for SPN",
 				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_AZURE_USE_PAT_FOR_SPN", false),
 			},
+			"azure_use_msi": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Authenticate with managed identity of Azure VM or AKS pod",
+				DefaultFunc: schema.EnvDefaultFunc("ARM_USE_MSI", false),
+			},
+			"azure_msi_client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Client ID of user-assigned managed identity",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_AZURE_MSI_CLIENT_ID", nil),
+			},
+			"azure_token_refresh_minutes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Refresh AAD tokens, when they expire within this number of minutes. Default is 6.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_AZURE_TOKEN_REFRESH_MINUTES", nil),
+				ValidateFunc: validation.IntBetween(1, 59),
+			},
 			"azure_environment": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ARM_ENVIRONMENT", "public"),
+				Description: "Azure cloud, like usgovernment or china. Detected from host by default",
+				DefaultFunc: schema.EnvDefaultFunc("ARM_ENVIRONMENT", nil),
 			},
 			"google_service_account": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_GOOGLE_SERVICE_ACCOUNT", nil),
 			},
+			"google_impersonation_delegates": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Service accounts, through which google_service_account is impersonated",
+			},
+			"google_credentials": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Path to or contents of service account key or workload identity federation config",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_GOOGLE_CREDENTIALS", nil),
+			},
+			"proxy_url": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Proxy for all requests, except no_proxy hosts, optionally with basic auth credentials",
+				DefaultFunc:   schema.EnvDefaultFunc("DATABRICKS_PROXY_URL", nil),
+				ConflictsWith: []string{"http_proxy", "https_proxy"},
+			},
+			"http_proxy": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Proxy for HTTP requests, optionally with basic auth credentials",
+				DefaultFunc:   schema.EnvDefaultFunc("DATABRICKS_HTTP_PROXY", nil),
+				ConflictsWith: []string{"proxy_url"},
+			},
+			"https_proxy": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Proxy for HTTPS requests, optionally with basic auth credentials",
+				DefaultFunc:   schema.EnvDefaultFunc("DATABRICKS_HTTPS_PROXY", nil),
+				ConflictsWith: []string{"proxy_url"},
+			},
+			"socks_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "SOCKS5 proxy for all connections, optionally with username and password",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_SOCKS_PROXY", nil),
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Comma-separated hosts, that are requested without proxy",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_NO_PROXY", nil),
+			},
+			"tls_ca_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM file with certificates of private certificate authorities, trusted in addition to system ones",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_TLS_CA_FILE", nil),
+			},
+			"tls_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM file with client certificate for mutual TLS",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_TLS_CERT_FILE", nil),
+			},
+			"tls_key_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM file with private key of client certificate for mutual TLS",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_TLS_KEY_FILE", nil),
+			},
 			"skip_verify": {
 				Type:        schema.TypeBool,
 				Description: "Skip SSL certificate verification for HTTP calls. Use at your own risk.",
 				Optional:    true,
-				Default:     false,
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_SKIP_VERIFY", false),
 			},
 			"development_mode": {
 				Type:        schema.TypeBool,
@@ -254,12 +407,110 @@ func DatabricksProvider() *schema.Provider {
 				Description: "Debug HTTP headers of requests made by the provider. Default is false. Visible only when TF_LOG=DEBUG is set",
 				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_DEBUG_HEADERS", false),
 			},
+			"debug_log_file": {
+				Optional:    true,
+				Type:        schema.TypeString,
+				Description: "Appends JSON lines with redacted requests and responses to this file, regardless of TF_LOG",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_DEBUG_LOG_FILE", nil),
+			},
+			"cache_responses": {
+				Optional:    true,
+				Type:        schema.TypeBool,
+				Description: "Serve identical requests of node type, spark version and zones data sources from memory for the rest of the run",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_CACHE_RESPONSES", false),
+			},
+			"user_agent_extra": {
+				Optional:    true,
+				Type:        schema.TypeString,
+				Description: "Appended to User-Agent of every request, like partner/acme, to attribute API traffic in audit logs",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_USER_AGENT_EXTRA", nil),
+			},
+			"http_timeout_seconds": {
+				Optional:    true,
+				Type:        schema.TypeInt,
+				Description: "Timeout of a single HTTP request. Default is 60.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_HTTP_TIMEOUT_SECONDS", nil),
+			},
 			"rate_limit": {
 				Optional:    true,
 				Type:        schema.TypeInt,
 				Description: "Maximum number of requests per second made to Databricks REST API by Terraform.",
 				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_RATE_LIMIT", common.DefaultRateLimitPerSecond),
 			},
+			"max_concurrent_requests": {
+				Optional:     true,
+				Type:         schema.TypeInt,
+				Description:  "Maximum number of in-flight requests to Databricks REST API, regardless of Terraform parallelism. Unlimited by default.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_MAX_CONCURRENT_REQUESTS", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"gzip_requests": {
+				Optional:    true,
+				Type:        schema.TypeBool,
+				Description: "Compress large request bodies, like notebook imports, with gzip.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_GZIP_REQUESTS", false),
+			},
+			"rate_limits": {
+				Optional:    true,
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Maximum number of requests per second for API families, like clusters, scim or dbfs. They don't count towards rate_limit.",
+			},
+			"failed_call_cooldown_seconds": {
+				Optional:    true,
+				Type:        schema.TypeInt,
+				Description: "Time identical calls fail fast after they have failed with I/O or server error. Disabled by default.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_FAILED_CALL_COOLDOWN_SECONDS", nil),
+			},
+			"retry_strategy": {
+				Optional:    true,
+				Type:        schema.TypeString,
+				Description: "Backoff between retries of failed requests: linear or exponential. Default is linear.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_RETRY_STRATEGY", nil),
+				ValidateFunc: validation.StringInSlice([]string{
+					common.RetryStrategyLinear,
+					common.RetryStrategyExponential,
+				}, false),
+			},
+			"retry_wait_min_seconds": {
+				Optional:    true,
+				Type:        schema.TypeInt,
+				Description: "Minimum wait between retries of failed requests. Default is 10.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_RETRY_WAIT_MIN_SECONDS", nil),
+			},
+			"retry_wait_max_seconds": {
+				Optional:    true,
+				Type:        schema.TypeInt,
+				Description: "Maximum wait between retries of failed requests. Default is 10.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_RETRY_WAIT_MAX_SECONDS", nil),
+			},
+			"retry_max_attempts": {
+				Optional:    true,
+				Type:        schema.TypeInt,
+				Description: "Maximum number of attempts of failed requests, including the first one. Default is 31.",
+				DefaultFunc: schema.EnvDefaultFunc("DATABRICKS_RETRY_MAX_ATTEMPTS", nil),
+			},
+			"circuit_breaker_threshold": {
+				Optional:     true,
+				Type:         schema.TypeInt,
+				Description:  "Number of consecutive server errors, after which requests fail fast. Disabled by default.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_CIRCUIT_BREAKER_THRESHOLD", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"circuit_cooldown_seconds": {
+				Optional:     true,
+				Type:         schema.TypeInt,
+				Description:  "Time requests fail fast after the circuit breaker has tripped. Default is 60.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_CIRCUIT_COOLDOWN_SECONDS", nil),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"expiring_pats_warning_days": {
+				Optional:     true,
+				Type:         schema.TypeInt,
+				Description:  "Warn, when the configured personal access token expires within the given number of days. Disabled by default.",
+				DefaultFunc:  schema.EnvDefaultFunc("DATABRICKS_EXPIRING_PATS_WARNING_DAYS", nil),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 		},
 	}
 	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
@@ -312,6 +563,30 @@ func configureDatabricksClient(ctx context.Context, d *schema.ResourceData) (int
 		authsUsed["password"] = true
 		pc.Password = v.(string)
 	}
+	if v, ok := d.GetOk("account_id"); ok {
+		pc.AccountID = v.(string)
+	}
+	if v, ok := d.GetOk("account_host"); ok {
+		pc.AccountHost = v.(string)
+	}
+	if v, ok := d.GetOk("client_id"); ok {
+		authsUsed["oauth"] = true
+		pc.ClientID = v.(string)
+	}
+	if v, ok := d.GetOk("client_secret"); ok {
+		authsUsed["oauth"] = true
+		pc.ClientSecret = v.(string)
+	}
+	if v, ok := d.GetOk("auth_type"); ok {
+		pc.AuthType = v.(string)
+	}
+	if v, ok := d.GetOk("credential_helper"); ok {
+		authsUsed["credential helper"] = true
+		pc.CredentialHelper = v.(string)
+	}
+	if v, ok := d.GetOk("oidc_audience"); ok {
+		pc.OIDCAudience = v.(string)
+	}
 	if v, ok := d.GetOk("profile"); ok {
 		authsUsed["config profile"] = true
 		pc.Profile = v.(string)
@@ -348,9 +623,44 @@ func configureDatabricksClient(ctx context.Context, d *schema.ResourceData) (int
 		authsUsed["azure"] = true
 		pc.AzureAuth.TenantID = v.(string)
 	}
+	if v, ok := d.GetOk("azure_use_msi"); ok && v.(bool) {
+		authsUsed["azure"] = true
+		pc.AzureAuth.UseMSI = true
+	}
+	if v, ok := d.GetOk("azure_msi_client_id"); ok {
+		authsUsed["azure"] = true
+		pc.AzureAuth.MSIClientID = v.(string)
+	}
+	if v, ok := d.GetOk("azure_token_refresh_minutes"); ok {
+		pc.AzureAuth.TokenRefreshMinutes = v.(int)
+	}
 	if v, ok := d.GetOk("azure_pat_token_duration_seconds"); ok {
 		pc.AzureAuth.PATTokenDurationSeconds = v.(string)
 	}
+	if v, ok := d.GetOk("proxy_url"); ok {
+		pc.ProxyURL = v.(string)
+	}
+	if v, ok := d.GetOk("http_proxy"); ok {
+		pc.HTTPProxy = v.(string)
+	}
+	if v, ok := d.GetOk("https_proxy"); ok {
+		pc.HTTPSProxy = v.(string)
+	}
+	if v, ok := d.GetOk("socks_proxy"); ok {
+		pc.SOCKSProxy = v.(string)
+	}
+	if v, ok := d.GetOk("no_proxy"); ok {
+		pc.NoProxy = v.(string)
+	}
+	if v, ok := d.GetOk("tls_ca_file"); ok {
+		pc.TLSCAFile = v.(string)
+	}
+	if v, ok := d.GetOk("tls_cert_file"); ok {
+		pc.TLSCertFile = v.(string)
+	}
+	if v, ok := d.GetOk("tls_key_file"); ok {
+		pc.TLSKeyFile = v.(string)
+	}
 	if v, ok := d.GetOk("skip_verify"); ok {
 		pc.InsecureSkipVerify = v.(bool)
 	}
@@ -360,12 +670,67 @@ func configureDatabricksClient(ctx context.Context, d *schema.ResourceData) (int
 	if v, ok := d.GetOk("debug_truncate_bytes"); ok {
 		pc.DebugTruncateBytes = v.(int)
 	}
+	if v, ok := d.GetOk("cache_responses"); ok {
+		pc.CacheResponses = v.(bool)
+	}
+	if v, ok := d.GetOk("user_agent_extra"); ok {
+		pc.UserAgentExtra = v.(string)
+	}
 	if v, ok := d.GetOk("rate_limit"); ok {
 		pc.RateLimitPerSecond = v.(int)
 	}
+	if v, ok := d.GetOk("max_concurrent_requests"); ok {
+		pc.MaxConcurrentRequests = v.(int)
+	}
+	if v, ok := d.GetOk("gzip_requests"); ok {
+		pc.GzipRequests = v.(bool)
+	}
+	if v, ok := d.GetOk("rate_limits"); ok {
+		pc.RateLimits = map[string]int{}
+		for family, limit := range v.(map[string]interface{}) {
+			pc.RateLimits[family] = limit.(int)
+		}
+	} else if v := os.Getenv("DATABRICKS_RATE_LIMITS"); v != "" {
+		// maps cannot have default values in the schema
+		rateLimits, err := common.ParseRateLimits(v)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		pc.RateLimits = rateLimits
+	}
+	if v, ok := d.GetOk("http_timeout_seconds"); ok {
+		pc.HTTPTimeoutSeconds = v.(int)
+	}
+	if v, ok := d.GetOk("failed_call_cooldown_seconds"); ok {
+		pc.FailedCallCooldownSeconds = v.(int)
+	}
+	if v, ok := d.GetOk("retry_strategy"); ok {
+		pc.RetryStrategy = v.(string)
+	}
+	if v, ok := d.GetOk("retry_wait_min_seconds"); ok {
+		pc.RetryWaitMinSeconds = v.(int)
+	}
+	if v, ok := d.GetOk("retry_wait_max_seconds"); ok {
+		pc.RetryWaitMaxSeconds = v.(int)
+	}
+	if v, ok := d.GetOk("retry_max_attempts"); ok {
+		pc.RetryMaxAttempts = v.(int)
+	}
+	if v, ok := d.GetOk("circuit_breaker_threshold"); ok {
+		pc.CircuitBreakerThreshold = v.(int)
+	}
+	if v, ok := d.GetOk("circuit_cooldown_seconds"); ok {
+		pc.CircuitCooldownSeconds = v.(int)
+	}
+	if v, ok := d.GetOk("expiring_pats_warning_days"); ok {
+		pc.ExpiringPATsWarningDays = v.(int)
+	}
 	if v, ok := d.GetOk("debug_headers"); ok {
 		pc.DebugHeaders = v.(bool)
 	}
+	if v, ok := d.GetOk("debug_log_file"); ok {
+		pc.DebugLogFile = v.(string)
+	}
 	if v, ok := d.GetOk("azure_use_pat_for_cli"); ok {
 		pc.AzureAuth.UsePATForCLI = v.(bool)
 	}
@@ -379,6 +744,19 @@ func configureDatabricksClient(ctx context.Context, d *schema.ResourceData) (int
 		authsUsed["google"] = true
 		pc.GoogleServiceAccount = v.(string)
 	}
+	if v, ok := d.GetOk("google_impersonation_delegates"); ok {
+		for _, delegate := range v.([]interface{}) {
+			pc.GoogleImpersonationDelegates = append(pc.GoogleImpersonationDelegates, delegate.(string))
+		}
+	} else if v := os.Getenv("DATABRICKS_GOOGLE_IMPERSONATION_DELEGATES"); v != "" {
+		// lists cannot have default values in the schema
+		pc.GoogleImpersonationDelegates = common.ParseList(v)
+	}
+	if v, ok := d.GetOk("google_credentials"); ok {
+		// credentials are only used to obtain tokens for google_service_account, that is
+		// already counted as an authorization method
+		pc.GoogleCredentials = v.(string)
+	}
 	authorizationMethodsUsed := []string{}
 	for name, used := range authsUsed {
 		if used {
@@ -396,5 +774,5 @@ func configureDatabricksClient(ctx context.Context, d *schema.ResourceData) (int
 	pc.WithCommandExecutor(func(ctx context.Context, client *common.DatabricksClient) common.CommandExecutor {
 		return compute.NewCommandsAPI(ctx, client)
 	})
-	return &pc, nil
+	return &pc, pc.CheckExpiringPATs(ctx)
 }
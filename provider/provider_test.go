@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
@@ -116,6 +117,21 @@ func TestProviderConfigurationOptions(t *testing.T) {
 			assertToken: "x",
 			assertHost:  "https://x",
 		},
+		{
+			env: map[string]string{
+				"DATABRICKS_CLIENT_ID":     "x",
+				"DATABRICKS_CLIENT_SECRET": "y",
+			},
+			assertError: "host is empty, but is required by oauth-m2m",
+		},
+		{
+			env: map[string]string{
+				"DATABRICKS_CLIENT_ID":     "x",
+				"DATABRICKS_CLIENT_SECRET": "y",
+				"DATABRICKS_TOKEN":         "z",
+			},
+			assertError: "More than one authorization method configured: oauth and token",
+		},
 		{
 			env: map[string]string{
 				"DATABRICKS_USERNAME": "x",
@@ -397,3 +413,136 @@ func TestAllResourcesMustHaveImport(t *testing.T) {
 		}
 	}
 }
+
+func TestProvider_RetryPolicy(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	os.Setenv("DATABRICKS_RETRY_WAIT_MAX_SECONDS", "60")
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":                   "https://x",
+		"token":                  "x",
+		"retry_strategy":         "exponential",
+		"retry_wait_min_seconds": 2,
+		"retry_max_attempts":     5,
+	}))
+	require.Len(t, diags, 0)
+	client := p.Meta().(*common.DatabricksClient)
+	assert.Equal(t, common.RetryStrategyExponential, client.RetryStrategy)
+	assert.Equal(t, 2, client.RetryWaitMinSeconds)
+	assert.Equal(t, 60, client.RetryWaitMaxSeconds)
+	assert.Equal(t, 5, client.RetryMaxAttempts)
+}
+
+func TestProvider_InvalidRetryPolicyGivesError(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":                   "https://x",
+		"token":                  "x",
+		"retry_wait_min_seconds": 30,
+		"retry_wait_max_seconds": 20,
+	}))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "retry wait min (30s) cannot be greater than retry wait max (20s)", diags[0].Summary)
+}
+
+func TestProvider_AllArgumentsFromEnvironment(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	os.Setenv("DATABRICKS_HOST", "https://x")
+	os.Setenv("DATABRICKS_TOKEN", "x")
+	os.Setenv("DATABRICKS_RATE_LIMITS", "scim=5,dbfs=30")
+	os.Setenv("DATABRICKS_HTTP_TIMEOUT_SECONDS", "120")
+	os.Setenv("DATABRICKS_FAILED_CALL_COOLDOWN_SECONDS", "30")
+	os.Setenv("DATABRICKS_SKIP_VERIFY", "true")
+	os.Setenv("DATABRICKS_PROXY_URL", "http://proxy.corp:3128")
+	os.Setenv("DATABRICKS_GOOGLE_IMPERSONATION_DELEGATES", "a@x.iam.gserviceaccount.com,b@x.iam.gserviceaccount.com")
+	os.Setenv("DATABRICKS_AZURE_USE_PAT_FOR_CLI", "true")
+	os.Setenv("DATABRICKS_AZURE_PAT_TOKEN_DURATION_SECONDS", "600")
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"http_timeout_seconds": 90,
+	}))
+	require.Len(t, diags, 0)
+	client := p.Meta().(*common.DatabricksClient)
+	assert.Equal(t, map[string]int{"scim": 5, "dbfs": 30}, client.RateLimits)
+	// provider block takes precedence over environment
+	assert.Equal(t, 90, client.HTTPTimeoutSeconds)
+	assert.Equal(t, 30, client.FailedCallCooldownSeconds)
+	assert.True(t, client.InsecureSkipVerify)
+	assert.Equal(t, "http://proxy.corp:3128", client.ProxyURL)
+	assert.Equal(t, []string{"a@x.iam.gserviceaccount.com", "b@x.iam.gserviceaccount.com"},
+		client.GoogleImpersonationDelegates)
+	assert.True(t, client.AzureAuth.UsePATForCLI)
+	assert.Equal(t, "600", client.AzureAuth.PATTokenDurationSeconds)
+}
+
+func TestProvider_InvalidRateLimitsFromEnvironment(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	os.Setenv("DATABRICKS_RATE_LIMITS", "scim")
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":  "https://x",
+		"token": "x",
+	}))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "invalid rate limit: scim", diags[0].Summary)
+}
+
+func TestProvider_TokenWithGoogleCredentialsOfGoogleProvider(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	// exported for the Google provider in the same configuration
+	os.Setenv("GOOGLE_CREDENTIALS", `{"type": "service_account"}`)
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":  "https://x",
+		"token": "x",
+	}))
+	require.Len(t, diags, 0)
+	client := p.Meta().(*common.DatabricksClient)
+	assert.Equal(t, "x", client.Token)
+	assert.Equal(t, "", client.GoogleCredentials)
+}
+
+func TestProvider_TokenWithGoogleCredentials(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	os.Setenv("DATABRICKS_GOOGLE_CREDENTIALS", `{"type": "service_account"}`)
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":  "https://x",
+		"token": "x",
+	}))
+	require.Len(t, diags, 0)
+	client := p.Meta().(*common.DatabricksClient)
+	assert.Equal(t, `{"type": "service_account"}`, client.GoogleCredentials)
+}
+
+func TestProvider_AzureEnvironmentFromHost(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	p := DatabricksProvider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":                "https://adb-123.4.databricks.azure.us",
+		"azure_client_id":     "a",
+		"azure_client_secret": "b",
+		"azure_tenant_id":     "c",
+	}))
+	require.Len(t, diags, 0)
+	client := p.Meta().(*common.DatabricksClient)
+	assert.Equal(t, "", client.AzureAuth.Environment)
+	require.NoError(t, client.Authenticate())
+	require.NotNil(t, client.AzureAuth.AzureEnvironment)
+	assert.Equal(t, azure.USGovernmentCloud.ActiveDirectoryEndpoint,
+		client.AzureAuth.AzureEnvironment.ActiveDirectoryEndpoint)
+}
+
+func TestProvider_ProxyURLConflictsWithHTTPSProxy(t *testing.T) {
+	defer common.CleanupEnvironment()()
+	p := DatabricksProvider()
+	diags := p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":        "https://x",
+		"token":       "x",
+		"proxy_url":   "http://proxy.corp:3128",
+		"https_proxy": "http://other.corp:3128",
+	}))
+	require.True(t, diags.HasError())
+	assert.Equal(t, "Conflicting configuration arguments", diags[0].Summary)
+}
@@ -57,6 +57,10 @@ func RandomName(prefix ...string) string {
 	return string(b)
 }
 
+// UnknownValue makes an attribute in ResourceFixture.HCL unknown at plan time, like the one,
+// that refers to a resource, which is not created yet
+const UnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
 // HTTPFixture defines request structure for test
 type HTTPFixture struct {
 	Method          string
@@ -89,6 +93,8 @@ type ResourceFixture struct {
 	// new resource
 	New       bool
 	AzureAuth *common.AzureAuth
+	// AccountID of the provider configuration
+	AccountID string
 }
 
 // Apply runs tests from fixture
@@ -107,6 +113,9 @@ func (f ResourceFixture) Apply(t *testing.T) (*schema.ResourceData, error) {
 	if f.AzureAuth != nil {
 		client.AzureAuth = *f.AzureAuth
 	}
+	if f.AccountID != "" {
+		client.AccountID = f.AccountID
+	}
 	if len(f.HCL) > 0 {
 		var out interface{}
 		// TODO: update to HCLv2 somehow, so that importer and this use the same stuff
@@ -341,6 +350,27 @@ func UnionFixturesLists(fixturesLists ...[]HTTPFixture) (fixtureList []HTTPFixtu
 	return
 }
 
+// withoutIdempotencyToken removes random token, that client adds to create requests,
+// unless the fixture expects it explicitly
+func withoutIdempotencyToken(expected, received []byte) string {
+	if bytes.Contains(expected, []byte(`"idempotency_token"`)) {
+		return string(received)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(received, &body); err != nil {
+		return string(received)
+	}
+	if _, ok := body["idempotency_token"]; !ok {
+		return string(received)
+	}
+	delete(body, "idempotency_token")
+	stripped, err := json.Marshal(body)
+	if err != nil {
+		return string(received)
+	}
+	return string(stripped)
+}
+
 // HttpFixtureClient creates client for emulated HTTP server
 func HttpFixtureClient(t *testing.T, fixtures []HTTPFixture) (client *common.DatabricksClient, server *httptest.Server, err error) {
 	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -358,7 +388,8 @@ func HttpFixtureClient(t *testing.T, fixtures []HTTPFixture) (client *common.Dat
 					assert.NoError(t, err, err)
 					jsonStr, err := json.Marshal(fixture.ExpectedRequest)
 					assert.NoError(t, err, err)
-					assert.JSONEq(t, string(jsonStr), buf.String(), "json strings do not match")
+					assert.JSONEq(t, string(jsonStr), withoutIdempotencyToken(jsonStr, buf.Bytes()),
+						"json strings do not match")
 				}
 				if fixture.Response != nil {
 					if alreadyJSON, ok := fixture.Response.(string); ok {
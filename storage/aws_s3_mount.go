@@ -66,19 +66,19 @@ func ResourceAWSS3Mount() *schema.Resource {
 	}
 	r.CreateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		if err := preprocessS3Mount(ctx, d, m); err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return mountCreate(tpl, r)(ctx, d, m)
 	}
 	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		if err := preprocessS3Mount(ctx, d, m); err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return mountRead(tpl, r)(ctx, d, m)
 	}
 	r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		if err := preprocessS3Mount(ctx, d, m); err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return mountDelete(tpl, r)(ctx, d, m)
 	}
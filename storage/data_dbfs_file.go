@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -16,7 +17,7 @@ func DataSourceDBFSFile() *schema.Resource {
 			dbfsAPI := NewDbfsAPI(ctx, m)
 			fileInfo, err := dbfsAPI.Status(d.Get("path").(string))
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			// TODO: DEPRECATE/ make default
 			if limitFileSize && fileInfo.FileSize > 4e6 {
@@ -28,7 +29,7 @@ func DataSourceDBFSFile() *schema.Resource {
 			d.Set("file_size", fileInfo.FileSize)
 			content, err := dbfsAPI.Read(fileInfo.Path)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.Set("content", base64.StdEncoding.EncodeToString(content))
 			return nil
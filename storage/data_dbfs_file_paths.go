@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 
+	"github.com/databrickslabs/terraform-provider-databricks/common"
 	"github.com/databrickslabs/terraform-provider-databricks/workspace"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -16,7 +17,7 @@ func DataSourceDBFSFilePaths() *schema.Resource {
 			recursive := d.Get("recursive").(bool)
 			paths, err := NewDbfsAPI(ctx, m).List(path, recursive)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.SetId(path)
 			pathList := []map[string]interface{}{}
@@ -200,17 +200,17 @@ func mountCreate(tpl interface{}, r *schema.Resource) func(context.Context, *sch
 	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		mountConfig, mountPoint, err := mountCluster(ctx, tpl, d, m, r)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		client := m.(*common.DatabricksClient)
 		log.Printf("[INFO] Mounting %s at /mnt/%s", mountConfig.Source(), d.Id())
 		source, err := mountPoint.Mount(mountConfig, client)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		err = d.Set("source", source)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return readMountSource(ctx, mountPoint, d)
 	}
@@ -225,10 +225,10 @@ func readMountSource(ctx context.Context, mp MountPoint, d *schema.ResourceData)
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return common.DiagFromErr(err)
 	}
 	if err = d.Set("source", source); err != nil {
-		return diag.FromErr(err)
+		return common.DiagFromErr(err)
 	}
 	return nil
 }
@@ -238,7 +238,7 @@ func mountRead(tpl Mount, r *schema.Resource) schema.ReadContextFunc {
 	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		_, mp, err := mountCluster(ctx, tpl, d, m, r)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return readMountSource(ctx, mp, d)
 	}
@@ -249,11 +249,11 @@ func mountDelete(tpl Mount, r *schema.Resource) schema.DeleteContextFunc {
 	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		_, mp, err := mountCluster(ctx, tpl, d, m, r)
 		if err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		log.Printf("[INFO] Unmounting /mnt/%s", d.Id())
 		if err = mp.Delete(); err != nil {
-			return diag.FromErr(err)
+			return common.DiagFromErr(err)
 		}
 		return nil
 	}
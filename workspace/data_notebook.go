@@ -55,18 +55,18 @@ func DataSourceNotebook() *schema.Resource {
 			format := d.Get("format").(string)
 			notebookContent, err := notebooksAPI.Export(path, ExportFormat(format))
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			d.SetId(path)
 			// nolint
 			d.Set("content", notebookContent)
 			objectStatus, err := notebooksAPI.Read(d.Id())
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			err = common.StructToData(objectStatus, s, d)
 			if err != nil {
-				return diag.FromErr(err)
+				return common.DiagFromErr(err)
 			}
 			return nil
 		},